@@ -2,11 +2,9 @@
 package bigjsonvalue
 
 import (
-	"encoding/json"
 	"fmt"
+	"hash"
 	"math/big"
-	//"strconv"
-	"strings"
 )
 
 // BigJSONValue is wrapper around interface{} type to force
@@ -19,7 +17,24 @@ import (
 // Compared to NatJSONValue, BigJSONValue uses big.Int and big.Float to
 // store arbitrary-precision numbers, but is slower than NatJSONValue.
 type BigJSONValue struct {
-	proxy interface{}
+	proxy   interface{}
+	literal string // original text of a number, if retained
+}
+
+// bigObject holds the members of a JSON object decoded by BigJSONValue,
+// remembering the order in which keys appeared in the document.
+type bigObject struct {
+	keys    []string
+	members map[string]*BigJSONValue
+}
+
+// set sets the member with the given key, appending key to keys if not
+// already present.
+func (obj *bigObject) set(key string, member *BigJSONValue) {
+	if _, dup := obj.members[key]; !dup {
+		obj.keys = append(obj.keys, key)
+	}
+	obj.members[key] = member
 }
 
 // Kind returns the kind of BigJSONValue it is holding:
@@ -32,6 +47,12 @@ type BigJSONValue struct {
 //
 // Returns BigFloat if value is a big.Float.
 //
+// Returns Object if value is a JSON object.
+//
+// Returns Array if value is a JSON array.
+//
+// Returns Decimal if value is an exact decimal.
+//
 // Otherwise returns Nil.
 func (bjv *BigJSONValue) Kind() Kind {
 	switch bjv.proxy.(type) {
@@ -39,10 +60,16 @@ func (bjv *BigJSONValue) Kind() Kind {
 		return Bool
 	case string:
 		return String
-	case big.Int:
+	case *big.Int:
 		return BigInt
-	case big.Float:
+	case *big.Float:
 		return BigFloat
+	case *bigObject:
+		return Object
+	case []BigJSONValue:
+		return Array
+	case bigDecimal:
+		return Decimal
 	default:
 		return Nil
 	}
@@ -73,9 +100,51 @@ func (bjv *BigJSONValue) IsBigInt() bool {
 	return (bjv.Kind() == BigInt)
 }
 
+// IsDecimal returns true if value is an exact decimal.
+func (bjv *BigJSONValue) IsDecimal() bool {
+	return (bjv.Kind() == Decimal)
+}
+
+// IsObject returns true if value is a JSON object.
+func (bjv *BigJSONValue) IsObject() bool {
+	return (bjv.Kind() == Object)
+}
+
+// IsArray returns true if value is a JSON array.
+func (bjv *BigJSONValue) IsArray() bool {
+	return (bjv.Kind() == Array)
+}
+
 // Value returns the underlying interface{} value that is being wrapped.
+//
+// BigInt and BigFloat values return a copy as a big.Int or big.Float.
+//
+// Object values return a map[string]*BigJSONValue of their members.
+//
+// Array values return a []*BigJSONValue of their elements.
 func (bjv *BigJSONValue) Value() interface{} {
-	return bjv.proxy
+	switch bjv.proxy.(type) {
+	case *big.Int:
+		return bjv.BigInt()
+	case *big.Float:
+		return bjv.BigFloat()
+	case *bigObject:
+		obj := bjv.proxy.(*bigObject)
+		members := make(map[string]*BigJSONValue, len(obj.members))
+		for key, member := range obj.members {
+			members[key] = member
+		}
+		return members
+	case []BigJSONValue:
+		arr := bjv.proxy.([]BigJSONValue)
+		elems := make([]*BigJSONValue, len(arr))
+		for idx := range arr {
+			elems[idx] = &arr[idx]
+		}
+		return elems
+	default:
+		return bjv.proxy
+	}
 }
 
 // Bool returns the underlying bool value.
@@ -84,16 +153,430 @@ func (bjv *BigJSONValue) Bool() bool {
 	return bjv.proxy.(bool)
 }
 
-// BigFloat returns the underlying big.Float value.
+// BigFloat returns a copy of the underlying big.Float value, which can be
+// changed without affecting the stored value.
 // Panics with runtime error if not a big.Float.
 func (bjv *BigJSONValue) BigFloat() big.Float {
-	return bjv.proxy.(big.Float)
+	var bigf big.Float
+	bigf.Copy(bjv.proxy.(*big.Float))
+	return bigf
+}
+
+// BigFloatRef returns a read-only view of the underlying big.Float value,
+// without copying it.  The result must not be modified, since it is the
+// stored value itself.
+// Panics with runtime error if not a big.Float.
+func (bjv *BigJSONValue) BigFloatRef() *big.Float {
+	return bjv.proxy.(*big.Float)
 }
 
-// BigInt returns the underlying big.Int value.
+// BigInt returns a copy of the underlying big.Int value, which can be
+// changed without affecting the stored value.
 // Panics with runtime error if not a big.Int.
 func (bjv *BigJSONValue) BigInt() big.Int {
-	return bjv.proxy.(big.Int)
+	var bigi big.Int
+	bigi.Set(bjv.proxy.(*big.Int))
+	return bigi
+}
+
+// BigIntRef returns a read-only view of the underlying big.Int value,
+// without copying it.  The result must not be modified, since it is the
+// stored value itself.
+// Panics with runtime error if not a big.Int.
+func (bjv *BigJSONValue) BigIntRef() *big.Int {
+	return bjv.proxy.(*big.Int)
+}
+
+// Unscaled returns a copy of the unscaled integer value of an exact decimal,
+// e.g. 1999 for 19.99.
+// Panics with runtime error if not a decimal.
+func (bjv *BigJSONValue) Unscaled() big.Int {
+	var bigi big.Int
+	bigi.Set(bjv.proxy.(bigDecimal).unscaled)
+	return bigi
+}
+
+// Scale returns the number of decimal digits after the decimal point of
+// an exact decimal, e.g. 2 for 19.99, or the negated power of ten it is
+// multiplied by if negative, e.g. -2 for 15e2.
+// Panics with runtime error if not a decimal.
+func (bjv *BigJSONValue) Scale() int {
+	return bjv.proxy.(bigDecimal).scale
+}
+
+// Rat returns the exact value of a decimal as a new big.Rat.
+// Returns ErrOverflow if the decimal is multiplied or divided by a power
+// of ten larger than 10**100000, e.g. 1e200000000, since expanding it
+// would exhaust memory; use Unscaled() and Scale() for those instead.
+// Panics with runtime error if not a decimal.
+func (bjv *BigJSONValue) Rat() (*big.Rat, error) {
+	return numberRat(bjv.proxy.(bigDecimal))
+}
+
+// AsBool returns the value as a bool.
+// Returns a *ConvertError wrapping ErrKindMismatch if not a bool.
+func (bjv *BigJSONValue) AsBool() (bool, error) {
+	b, err := asBool(bjv.proxy)
+	return b, newConvertError(bjv.Kind(), Bool, err)
+}
+
+// AsString returns the value as a string.
+// Returns a *ConvertError wrapping ErrKindMismatch if not a string.
+func (bjv *BigJSONValue) AsString() (string, error) {
+	str, err := asString(bjv.proxy)
+	return str, newConvertError(bjv.Kind(), String, err)
+}
+
+// AsInt64 returns a number value converted to an int64.
+// Returns a *ConvertError wrapping ErrKindMismatch if not a number,
+// ErrOverflow with the nearest int64 if out of range, or
+// ErrPrecisionLoss with the value truncated toward zero if fractional.
+func (bjv *BigJSONValue) AsInt64() (int64, error) {
+	i64, err := asInt64(bjv.proxy)
+	return i64, newConvertError(bjv.Kind(), Int64, err)
+}
+
+// AsUint64 returns a number value converted to a uint64.
+// Returns a *ConvertError wrapping ErrKindMismatch if not a number,
+// ErrOverflow with the nearest uint64 if out of range, or
+// ErrPrecisionLoss with the value truncated toward zero if fractional.
+func (bjv *BigJSONValue) AsUint64() (uint64, error) {
+	u64, err := asUint64(bjv.proxy)
+	return u64, newConvertError(bjv.Kind(), Uint64, err)
+}
+
+// AsFloat64 returns a number value converted to a float64.
+// Returns a *ConvertError wrapping ErrKindMismatch if not a number,
+// ErrOverflow with ±Inf if out of range, or ErrPrecisionLoss with the
+// nearest float64 if not exactly representable.
+func (bjv *BigJSONValue) AsFloat64() (float64, error) {
+	f64, err := asFloat64(bjv.proxy)
+	return f64, newConvertError(bjv.Kind(), Float64, err)
+}
+
+// AsBigInt returns a number value converted to a new big.Int.
+// Returns a *ConvertError wrapping ErrKindMismatch if not a number,
+// ErrOverflow if infinite, or ErrPrecisionLoss with the value truncated
+// toward zero if fractional.  The big.Int is nil if there is no
+// nearest value.
+func (bjv *BigJSONValue) AsBigInt() (*big.Int, error) {
+	bigi, err := asBigInt(bjv.proxy)
+	return bigi, newConvertError(bjv.Kind(), BigInt, err)
+}
+
+// AsBigFloat returns a number value converted to a new big.Float.
+// Integers are held exactly.  Returns a *ConvertError wrapping
+// ErrKindMismatch if not a number, ErrUnsupportedValue if NaN, or
+// ErrPrecisionLoss with the nearest value if a decimal cannot be held
+// exactly at the precision of DefaultBigDecodeOptions.  The big.Float
+// is nil if there is no nearest value.
+func (bjv *BigJSONValue) AsBigFloat() (*big.Float, error) {
+	bigf, err := asBigFloat(bjv.proxy)
+	return bigf, newConvertError(bjv.Kind(), BigFloat, err)
+}
+
+// ToNat returns the value converted to a new NatJSONValue, including all
+// members and elements, with numbers converted the same way
+// NatJSONValue.DecodeJSONValue() decodes them: integers to int64 if
+// negative or uint64 otherwise, and big.Float and Decimal values to
+// float64.  Numbers that do not fit are handled according to policy,
+// the same way as for NatDecodeOptions, except OverflowError returns a
+// *ConvertError wrapping ErrOverflow for the first such number.
+//
+// Also returns the worst loss of any number converted: Lossless if every
+// number was converted exactly, Rounded if some number was rounded to
+// the nearest float64, or Overflowed if some number was out of range and
+// policy is OverflowError or OverflowSaturate.
+//
+// Retained literals are kept, even for numbers that were rounded or out
+// of range, as NatJSONValue.DecodeJSONValue() keeps them.
+func (bjv *BigJSONValue) ToNat(policy OverflowPolicy) (*NatJSONValue, Loss, error) {
+	conv := natConverter{policy: policy}
+	njv := conv.value(bjv)
+	return &njv, conv.loss, conv.err
+}
+
+// Member returns the member of an object value with the given key.
+// Returns nil and false if not an object or key is not present.
+func (bjv *BigJSONValue) Member(key string) (*BigJSONValue, bool) {
+	obj, ok := bjv.proxy.(*bigObject)
+	if !ok {
+		return nil, false
+	}
+	member, ok := obj.members[key]
+	return member, ok
+}
+
+// Keys returns the keys of an object value in document order.
+// Returns nil if not an object.
+func (bjv *BigJSONValue) Keys() []string {
+	obj, ok := bjv.proxy.(*bigObject)
+	if !ok {
+		return nil
+	}
+	return append([]string(nil), obj.keys...)
+}
+
+// Len returns the number of members of an object value, or the number
+// of elements of an array value.
+// Returns 0 if neither an object nor an array.
+func (bjv *BigJSONValue) Len() int {
+	switch bjv.proxy.(type) {
+	case *bigObject:
+		return len(bjv.proxy.(*bigObject).keys)
+	case []BigJSONValue:
+		return len(bjv.proxy.([]BigJSONValue))
+	default:
+		return 0
+	}
+}
+
+// Index returns the element of an array value at index idx.
+// Returns nil if not an array or idx is out of range.
+func (bjv *BigJSONValue) Index(idx int) *BigJSONValue {
+	arr, _ := bjv.proxy.([]BigJSONValue)
+	if idx < 0 || idx >= len(arr) {
+		return nil
+	}
+	return &arr[idx]
+}
+
+// Range calls fn for each element of an array value in order,
+// stopping early if fn returns false.  Does nothing if not an array.
+func (bjv *BigJSONValue) Range(fn func(idx int, elem *BigJSONValue) bool) {
+	arr, _ := bjv.proxy.([]BigJSONValue)
+	for idx := range arr {
+		if !fn(idx, &arr[idx]) {
+			return
+		}
+	}
+}
+
+// Get returns the value referenced by the JSON Pointer pointer, per
+// RFC 6901, e.g. "/tags/0" for the first element of member "tags", or
+// "/a~1b" for member "a/b".  "" references the whole value.  The value
+// returned is not a copy, so changing it changes this value.
+// Returns a *PointerError if pointer is not well-formed, or references a
+// member or element that does not exist.
+func (bjv *BigJSONValue) Get(pointer string) (*BigJSONValue, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	return bjv.resolve(pointer, tokens)
+}
+
+// Set sets the value referenced by pointer to a copy of value, adding an
+// object member if not present, or replacing the whole value for "".
+// Unlike Add(), array elements are replaced rather than inserted, so must
+// exist.  A nil value sets null.
+// Returns a *PointerError if pointer is not well-formed, or references a
+// value whose parent does not exist or is not an object or array.
+func (bjv *BigJSONValue) Set(pointer string, value *BigJSONValue) error {
+	parent, tokens, err := bjv.resolveParent(pointer)
+	if err != nil {
+		return err
+	} else if parent == nil {
+		*bjv = value.copyValue()
+		return nil
+	}
+	token := tokens[len(tokens)-1]
+	switch parent.proxy.(type) {
+	case *bigObject:
+		member := value.copyValue()
+		parent.proxy.(*bigObject).set(token, &member)
+	case []BigJSONValue:
+		arr := parent.proxy.([]BigJSONValue)
+		idx, err := arrayIndex(token, len(arr))
+		if err == nil && idx == len(arr) {
+			err = ErrPointerNotFound
+		}
+		if err != nil {
+			return newPointerError(pointer, tokens, parent.Kind(), err)
+		}
+		arr[idx] = value.copyValue()
+	default:
+		return newPointerError(pointer, tokens, parent.Kind(), ErrKindMismatch)
+	}
+	return nil
+}
+
+// Add adds a copy of value at pointer, the same way as the "add"
+// operation of RFC 6902: an object member is added or replaced, an array
+// element is inserted at the index, or appended for "-", and "" replaces
+// the whole value.  A nil value adds null.  Elements returned by Get(),
+// Index() or Range() before an insert no longer belong to the array.
+// Returns a *PointerError if pointer is not well-formed, or references a
+// value whose parent does not exist or is not an object or array.
+func (bjv *BigJSONValue) Add(pointer string, value *BigJSONValue) error {
+	parent, tokens, err := bjv.resolveParent(pointer)
+	if err != nil {
+		return err
+	} else if parent == nil {
+		*bjv = value.copyValue()
+		return nil
+	}
+	token := tokens[len(tokens)-1]
+	switch parent.proxy.(type) {
+	case *bigObject:
+		member := value.copyValue()
+		parent.proxy.(*bigObject).set(token, &member)
+	case []BigJSONValue:
+		arr := parent.proxy.([]BigJSONValue)
+		idx, err := arrayIndex(token, len(arr))
+		if err != nil {
+			return newPointerError(pointer, tokens, parent.Kind(), err)
+		}
+		newArr := make([]BigJSONValue, 0, len(arr)+1)
+		newArr = append(newArr, arr[:idx]...)
+		newArr = append(newArr, value.copyValue())
+		parent.proxy = append(newArr, arr[idx:]...)
+	default:
+		return newPointerError(pointer, tokens, parent.Kind(), ErrKindMismatch)
+	}
+	return nil
+}
+
+// Delete removes the object member or array element referenced by
+// pointer, keeping the order of the remaining members or elements.
+// "" sets the whole value to null, as it has no parent to be removed
+// from.  Elements returned by Get(), Index() or Range() before a removal
+// no longer belong to the array.
+// Returns a *PointerError if pointer is not well-formed, or references a
+// member or element that does not exist.
+func (bjv *BigJSONValue) Delete(pointer string) error {
+	parent, tokens, err := bjv.resolveParent(pointer)
+	if err != nil {
+		return err
+	} else if parent == nil {
+		*bjv = BigJSONValue{}
+		return nil
+	}
+	token := tokens[len(tokens)-1]
+	switch parent.proxy.(type) {
+	case *bigObject:
+		obj := parent.proxy.(*bigObject)
+		if _, ok := obj.members[token]; !ok {
+			return newPointerError(pointer, tokens, parent.Kind(), ErrPointerNotFound)
+		}
+		obj.keys = removeKey(obj.keys, token)
+		delete(obj.members, token)
+	case []BigJSONValue:
+		arr := parent.proxy.([]BigJSONValue)
+		idx, err := arrayIndex(token, len(arr))
+		if err == nil && idx == len(arr) {
+			err = ErrPointerNotFound
+		}
+		if err != nil {
+			return newPointerError(pointer, tokens, parent.Kind(), err)
+		}
+		newArr := make([]BigJSONValue, 0, len(arr)-1)
+		newArr = append(newArr, arr[:idx]...)
+		parent.proxy = append(newArr, arr[idx+1:]...)
+	default:
+		return newPointerError(pointer, tokens, parent.Kind(), ErrKindMismatch)
+	}
+	return nil
+}
+
+// Query returns the values selected from the value by the JSONPath query,
+// per RFC 9535, e.g. "$.rows[?@.id > 9007199254740992].name".  See
+// JSONPath for details.
+// Returns a *JSONPathError if query cannot be compiled.
+func (bjv *BigJSONValue) Query(query string) ([]*BigJSONValue, error) {
+	jp, err := CompileJSONPath(query)
+	if err != nil {
+		return nil, err
+	}
+	return jp.Select(bjv), nil
+}
+
+// resolve returns the value referenced by tokens, the reference tokens
+// parsed from pointer.
+func (bjv *BigJSONValue) resolve(pointer string, tokens []string) (*BigJSONValue, error) {
+	cur := bjv
+	for idx, token := range tokens {
+		var err error
+		switch cur.proxy.(type) {
+		case *bigObject:
+			member, ok := cur.proxy.(*bigObject).members[token]
+			if ok {
+				cur = member
+			} else {
+				err = ErrPointerNotFound
+			}
+		case []BigJSONValue:
+			arr := cur.proxy.([]BigJSONValue)
+			var elemIdx int
+			if elemIdx, err = arrayIndex(token, len(arr)); err == nil && elemIdx == len(arr) {
+				err = ErrPointerNotFound
+			} else if err == nil {
+				cur = &arr[elemIdx]
+			}
+		default:
+			err = ErrKindMismatch
+		}
+		if err != nil {
+			return nil, newPointerError(pointer, tokens[:idx+1], cur.Kind(), err)
+		}
+	}
+	return cur, nil
+}
+
+// resolveParent returns the parent of the value referenced by pointer,
+// and the reference tokens parsed from pointer.  Returns a nil parent for
+// "", which references the whole value.
+func (bjv *BigJSONValue) resolveParent(pointer string) (*BigJSONValue, []string, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil || len(tokens) == 0 {
+		return nil, tokens, err
+	}
+	parent, err := bjv.resolve(pointer, tokens[:len(tokens)-1])
+	return parent, tokens, err
+}
+
+// Equal returns true if the value is equal to other, which is the same
+// as Compare(other) == 0.  Numbers of different kinds are equal if they
+// have exactly the same value, e.g. BigInt 1, BigFloat 1.0 and Decimal 1.00.
+// Objects are equal if they have the same keys with equal members,
+// in any order.
+func (bjv *BigJSONValue) Equal(other *BigJSONValue) bool {
+	return equalProxies(bjv.proxy, other.proxy)
+}
+
+// Compare returns -1, 0 or +1 if the value is less than, equal to, or
+// greater than other.  Values of different kinds are ordered
+// nil < bool < number < string < array < object, and within a kind:
+//
+// Bool values order false before true.
+//
+// Number values order by exact value, whatever their kind.  NaN is
+// equal to itself and less than all other numbers.  Numbers larger or
+// smaller than about 1e±100000 are compared rounded to 512 bits.
+//
+// String values order by their bytes.
+//
+// Array values order by their first unequal element, or by length if
+// one is a prefix of the other.
+//
+// Object values order by their members sorted by key, as if they were
+// arrays of key and member pairs.
+func (bjv *BigJSONValue) Compare(other *BigJSONValue) int {
+	return compareProxies(bjv.proxy, other.proxy)
+}
+
+// Hash64 returns a 64-bit FNV-1a hash of the value, which is the same for
+// values that are Equal(), so can be used as a map key standing in for
+// the value.  Values that are not Equal() may still hash the same.
+func (bjv *BigJSONValue) Hash64() uint64 {
+	return hash64Proxy(bjv.proxy)
+}
+
+// HashTo writes the value to h in a canonical form, which is the same
+// for values that are Equal(), including members of objects in any order
+// and numbers of different kinds with the same exact value.
+func (bjv *BigJSONValue) HashTo(h hash.Hash) {
+	hashProxy(h, bjv.proxy)
 }
 
 // String implements fmt.Stringer interface for BigJSONValue.
@@ -104,6 +587,12 @@ func (bjv *BigJSONValue) BigInt() big.Int {
 //
 // Number values return with as much precision as possible.
 //
+// Decimal values return all their digits, in plain decimal text if they
+// have digits after the decimal point, otherwise in exponent form,
+// e.g. "19.99", "0.00" or "15e2".
+//
+// Object and Array values return as compact JSON text.
+//
 // Nil values return "nil".
 func (bjv *BigJSONValue) String() string {
 	switch bjv.proxy.(type) {
@@ -111,19 +600,135 @@ func (bjv *BigJSONValue) String() string {
 		return fmt.Sprintf("%t", bjv.proxy.(bool))
 	case string:
 		return bjv.proxy.(string)
-	case big.Int:
-		bigi := bjv.proxy.(big.Int)
-		return bigi.String()
-	case big.Float:
-		bigf := bjv.proxy.(big.Float)
-		return bigf.Text('g', -1)
+	case *big.Int:
+		return bjv.proxy.(*big.Int).String()
+	case *big.Float:
+		return bjv.proxy.(*big.Float).Text('g', -1)
+	case bigDecimal:
+		dec := bjv.proxy.(bigDecimal)
+		return dec.String()
+	case *bigObject, []BigJSONValue:
+		text, _ := bjv.appendJSON(nil)
+		return string(text)
 	default:
 		return "nil"
 	}
 }
 
+// Literal returns the original text of a number value decoded with
+// BigDecodeOptions.RetainLiterals, e.g. "1.50", "1E3" or "-0", or "" if
+// the value is not a number or its text was not retained.
+func (bjv *BigJSONValue) Literal() string {
+	return bjv.literal
+}
+
+// MarshalJSON implements the json.Marshaler interface for BigJSONValue.
+// It has a value receiver so that BigJSONValue struct fields marshal
+// correctly even when the enclosing struct is not addressable.
+//
+// Nil values encode as null.
+//
+// String values encode as double-quoted JSON strings.
+//
+// BigInt values encode as integers with all their digits.
+//
+// BigFloat values encode with enough digits to decode to the same value
+// at the same precision, with a trailing ".0" added if needed so they
+// decode as BigFloat values again.  Infinite values cannot be encoded,
+// and return ErrUnsupportedValue.
+//
+// Decimal values encode as their String() text, which keeps their scale.
+//
+// Numbers decoded with BigDecodeOptions.RetainLiterals encode as their
+// original text instead.
+func (bjv BigJSONValue) MarshalJSON() ([]byte, error) {
+	return bjv.appendJSON(nil)
+}
+
+// appendJSON appends the compact JSON text of the value to buf.
+// Values that cannot be encoded are appended in their String() form,
+// and ErrUnsupportedValue is returned after the rest is appended.
+func (bjv *BigJSONValue) appendJSON(buf []byte) ([]byte, error) {
+	var err error
+	if bjv.literal != "" {
+		return append(buf, bjv.literal...), nil
+	}
+	switch bjv.proxy.(type) {
+	case nil:
+		buf = append(buf, "null"...)
+	case string:
+		buf = appendJSONString(buf, bjv.proxy.(string))
+	case *big.Float:
+		bigf := bjv.proxy.(*big.Float)
+		if bigf.IsInf() {
+			err = ErrUnsupportedValue
+		}
+		buf = appendJSONFloat(buf, bigf.Text('g', -1))
+	case *bigObject:
+		obj := bjv.proxy.(*bigObject)
+		buf = append(buf, '{')
+		for idx, key := range obj.keys {
+			if idx > 0 {
+				buf = append(buf, ',')
+			}
+			buf = appendJSONString(buf, key)
+			buf = append(buf, ':')
+			var memberErr error
+			buf, memberErr = obj.members[key].appendJSON(buf)
+			if err == nil {
+				err = memberErr
+			}
+		}
+		buf = append(buf, '}')
+	case []BigJSONValue:
+		arr := bjv.proxy.([]BigJSONValue)
+		buf = append(buf, '[')
+		for idx := range arr {
+			if idx > 0 {
+				buf = append(buf, ',')
+			}
+			var elemErr error
+			buf, elemErr = arr[idx].appendJSON(buf)
+			if err == nil {
+				err = elemErr
+			}
+		}
+		buf = append(buf, ']')
+	default:
+		buf = append(buf, bjv.String()...)
+	}
+	return buf, err
+}
+
+// Canonicalize returns the canonical JSON text of the value, per the
+// JSON Canonicalization Scheme of RFC 8785, suitable for hashing or
+// signing: object members sorted by the UTF-16 code units of their keys,
+// no whitespace, strings with only the required escapes, and numbers
+// formatted as ECMAScript formats the nearest float64.  Retained literals
+// are ignored.
+//
+// RFC 8785 numbers are IEEE 754 doubles, so numbers are canonicalized as
+// if parsed by JSON.parse().  BigFloat and Decimal values round to the
+// nearest float64, e.g. 0.1 at any precision is "0.1".  BigInt values
+// are never rounded: integers beyond 2^53 that are exactly a float64
+// are written as ECMAScript does, e.g. 2^60 as "1152921504606847000",
+// but any other, e.g. the 64-bit ID 9007199254740993, returns
+// ErrPrecisionLoss, and should be stored as a string instead.
+//
+// Returns nil and ErrPrecisionLoss for such integers, ErrOverflow for
+// numbers beyond the range of float64, and ErrUnsupportedValue for
+// infinite BigFloat values and strings or keys of invalid UTF-8.
+func (bjv *BigJSONValue) Canonicalize() ([]byte, error) {
+	buf, err := appendCanonical(nil, bjv.proxy)
+	if err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
 // DecodeJSONValue decodes a JSON value, and returns itself.
-// Results are undefined if error is returned.
+// Results are undefined if error is returned, which is always
+// a *DecodeError.
 //
 // The text "null" is decoded as a nil value.
 //
@@ -131,50 +736,143 @@ func (bjv *BigJSONValue) String() string {
 //
 // Text surrounded by double-quotes are decoded as string values.
 //
+// Text surrounded by curly braces are decoded as object values,
+// with each member decoded as a BigJSONValue.  If a key appears more
+// than once, the last member value wins.
+//
+// Text surrounded by square brackets are decoded as array values,
+// with each element decoded as a BigJSONValue.
+//
 // Number text containing period "." or the letters "e" or "E"
-// are decoded as big.Float values.
+// are decoded as big.Float values, with the precision and rounding mode
+// set by DefaultBigDecodeOptions, or as exact Decimal values if
+// DefaultBigDecodeOptions.Decimal is true.
 //
 // Otherwise, number text is decoded as big.Int values.
-// Whether text is considered a number is based on http://json.org
+// Whether text is considered a number is based on RFC 8259.
 func (bjv *BigJSONValue) DecodeJSONValue(text string) (*BigJSONValue, error) {
+	opts := DefaultBigDecodeOptions
+	return bjv, bjv.decodeJSONValue([]byte(text), &opts)
+}
+
+// decodeJSONValue decodes a JSON value using opts.
+func (bjv *BigJSONValue) decodeJSONValue(text []byte, opts *BigDecodeOptions) error {
+	if ns, ok := scanNumber(text); ok {
+		err := bjv.decodeJSONNumber(text, &ns, opts)
+		return newDecodeError(text, 0, bjv.Kind(), err)
+	}
+	builder := bigBuilder{opts: *opts}
+	if err := decodeJSONText(text, &builder); err != nil {
+		*bjv = BigJSONValue{}
+		return err
+	}
+	*bjv = builder.value
+	return nil
+}
+
+// decodeJSONNumber decodes the JSON number in text, already scanned as ns,
+// using opts.
+func (bjv *BigJSONValue) decodeJSONNumber(text []byte, ns *numScan, opts *BigDecodeOptions) error {
 	var err error
-	if text == "null" {
-		bjv.proxy = nil
-	} else if text == "true" {
-		bjv.proxy = true
-	} else if text == "false" {
-		bjv.proxy = false
-	} else if strings.HasPrefix(text, `{`) && strings.HasSuffix(text, `}`) {
-		err = ErrNotImplemented
-	} else if strings.HasPrefix(text, `[`) && strings.HasSuffix(text, `]`) {
-		err = ErrNotImplemented
-	} else if strings.HasPrefix(text, `"`) && strings.HasSuffix(text, `"`) {
-		err = json.Unmarshal([]byte(text), &bjv.proxy)
-	} else if jsonNumRegexp.MatchString(text) {
-		if strings.ContainsAny(text, ".eE") {
-			var bigf big.Float
-			bigf.SetPrec(128)
-			_, _, err = bigf.Parse(text, 10)
-			bjv.proxy = bigf
-		} else if strings.HasPrefix(text, "0") || strings.HasPrefix(text, "-0") {
-			err = ErrInvalidJSON
-		} else {
-			var bigi big.Int
-			//_, ok := bigi.SetString(text, 10)
-			//if !ok {
-			//	err = strconv.ErrSyntax
-			//}
-			err = bigi.UnmarshalJSON([]byte(text))
-			bjv.proxy = bigi
+	bjv.literal = ""
+	if ns.isFloat() && opts.Decimal {
+		var dec bigDecimal
+		dec, err = parseDecimal(text)
+		bjv.proxy = dec
+	} else if ns.isFloat() {
+		bigf := new(big.Float).SetPrec(opts.precFor(text)).SetMode(opts.Mode)
+		_, _, err = bigf.Parse(string(text), 10)
+		bjv.proxy = bigf
+	} else if ns.fits {
+		bigi := new(big.Int).SetUint64(ns.mag)
+		if ns.neg {
+			bigi.Neg(bigi)
 		}
+		bjv.proxy = bigi
 	} else {
-		err = ErrInvalidJSON
+		bigi := new(big.Int)
+		err = bigi.UnmarshalJSON(text)
+		bjv.proxy = bigi
 	}
-	return bjv, err
+	if err == nil && opts.RetainLiterals {
+		bjv.literal = string(text)
+	}
+	return err
 }
 
-// UnmarshalJSON implements the json.Unmarshaler interface for BigJSONValue
+// bigBuilder builds a BigJSONValue from the parts a valueScanner finds,
+// decoding numbers using opts.
+type bigBuilder struct {
+	opts   BigDecodeOptions
+	value  BigJSONValue    // the top-level value
+	frames []bigBuildFrame // objects and arrays begun and not yet ended
+}
+
+// bigBuildFrame is an object or array being built by a bigBuilder, with
+// the key of its next member if an object.
+type bigBuildFrame struct {
+	obj *bigObject
+	arr []BigJSONValue
+	key string
+}
+
+// add adds value to the innermost object or array, or sets the top-level
+// value if there is none.
+func (bb *bigBuilder) add(value BigJSONValue) {
+	if len(bb.frames) == 0 {
+		bb.value = value
+		return
+	}
+	frame := &bb.frames[len(bb.frames)-1]
+	if frame.obj != nil {
+		frame.obj.set(frame.key, &value)
+	} else {
+		frame.arr = append(frame.arr, value)
+	}
+}
+
+func (bb *bigBuilder) beginObject() {
+	obj := &bigObject{members: make(map[string]*BigJSONValue)}
+	bb.frames = append(bb.frames, bigBuildFrame{obj: obj})
+}
+
+func (bb *bigBuilder) beginArray() {
+	bb.frames = append(bb.frames, bigBuildFrame{arr: []BigJSONValue{}})
+}
+
+func (bb *bigBuilder) key(key string) {
+	bb.frames[len(bb.frames)-1].key = key
+}
+
+func (bb *bigBuilder) end() {
+	frame := bb.frames[len(bb.frames)-1]
+	bb.frames = bb.frames[:len(bb.frames)-1]
+	if frame.obj != nil {
+		bb.add(BigJSONValue{proxy: frame.obj})
+	} else {
+		bb.add(BigJSONValue{proxy: frame.arr})
+	}
+}
+
+func (bb *bigBuilder) scalar(proxy interface{}) {
+	bb.add(BigJSONValue{proxy: proxy})
+}
+
+func (bb *bigBuilder) number(text []byte, ns *numScan) (Kind, error) {
+	var bjv BigJSONValue
+	err := bjv.decodeJSONNumber(text, ns, &bb.opts)
+	bb.add(bjv)
+	return bjv.Kind(), err
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for BigJSONValue,
+// decoding with DefaultBigDecodeOptions.
+//
+// json.Unmarshal() only passes it the text of the value itself, not its
+// position in the enclosing document, so a *DecodeError it returns has
+// Offset and Path relative to that text.  Decode the whole document with
+// DecodeJSONValue() for errors relative to the document.
 func (bjv *BigJSONValue) UnmarshalJSON(text []byte) error {
-	_, err := bjv.DecodeJSONValue(string(text))
-	return err
+	opts := DefaultBigDecodeOptions
+	return bjv.decodeJSONValue(text, &opts)
 }
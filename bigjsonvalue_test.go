@@ -2,7 +2,10 @@ package bigjsonvalue
 
 import (
 	"encoding/json"
+	"errors"
+	"math/big"
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -49,6 +52,15 @@ var testList = []testRec{
 	{`"a\\b\\c new\nline"`,
 		"a\\b\\c new\nline", String, false, nil,
 		"a\\b\\c new\nline", String, false, nil},
+	{`0`,
+		"0", BigInt, false, nil,
+		"0", Uint64, false, nil},
+	{`-0`,
+		"0", BigInt, false, nil,
+		"0", Uint64, false, nil},
+	{`0.5`,
+		"0.5", BigFloat, false, nil,
+		"0.5", Float64, false, nil},
 	{`18446744073709551616`, // math.MaxUint64 + 1
 		"18446744073709551616", BigInt, false, nil,
 		"18446744073709551615", Uint64, false, strconv.ErrRange},
@@ -86,11 +98,11 @@ var testList = []testRec{
 		"-9.87654321987654321987654321987654321e+104", BigFloat, false, nil,
 		"-9.876543219876543e+104", Float64, false, nil},
 	{`{ "foo": "bar" }`,
-		"nil", Nil, true, ErrNotImplemented,
-		"nil", Nil, true, ErrNotImplemented},
+		`{"foo":"bar"}`, Object, false, nil,
+		`{"foo":"bar"}`, Object, false, nil},
 	{`[ 123, 456 ]`,
-		"nil", Nil, true, ErrNotImplemented,
-		"nil", Nil, true, ErrNotImplemented},
+		"[123,456]", Array, false, nil,
+		"[123,456]", Array, false, nil},
 	{`-123,456`,
 		"nil", Nil, true, ErrInvalidJSON,
 		"nil", Nil, true, ErrInvalidJSON},
@@ -124,7 +136,7 @@ func TestBigDecodeJSONValue(t *testing.T) {
 	for idx, rec := range testList {
 		bjv := BigJSONValue{}
 		_, err := bjv.DecodeJSONValue(rec.jsonStr)
-		if err != rec.bigErr {
+		if !errors.Is(err, rec.bigErr) {
 			t.Errorf("%d: Unexpected err=%+v, bigErr=%s, testRec=%+v\n", idx, err, rec.bigErr, rec)
 		}
 		if bjv.Kind() != rec.bigKind {
@@ -145,6 +157,12 @@ func TestBigDecodeJSONValue(t *testing.T) {
 		if bjv.Kind() == BigFloat && !bjv.IsBigFloat() {
 			t.Errorf("%d: Unexpected IsBigFloat()=%t, testRec=%+v\n", idx, bjv.IsBigFloat(), rec)
 		}
+		if bjv.Kind() == Object && !bjv.IsObject() {
+			t.Errorf("%d: Unexpected IsObject()=%t, testRec=%+v\n", idx, bjv.IsObject(), rec)
+		}
+		if bjv.Kind() == Array && !bjv.IsArray() {
+			t.Errorf("%d: Unexpected IsArray()=%t, testRec=%+v\n", idx, bjv.IsArray(), rec)
+		}
 		if bjv.IsNil() != rec.bigIsNil {
 			t.Errorf("%d: Unexpected IsNil()=%t, testRec=%+v\n", idx, bjv.IsNil(), rec)
 		}
@@ -169,6 +187,114 @@ func TestBigDecodeJSONValue(t *testing.T) {
 	}
 }
 
+func TestBigDecodeObject(t *testing.T) {
+	jsonStr := `{ "id": 18446744073709551616, "name": "foo",
+		"tags": { "z": null, "a": true, "m": -3.14 }, "name": "bar" }`
+
+	bjv, err := new(BigJSONValue).DecodeJSONValue(jsonStr)
+	if err != nil {
+		t.Fatalf("DecodeJSONValue err=%s", err)
+	}
+	if bjv.Kind() != Object || bjv.Len() != 3 {
+		t.Fatalf("Unexpected Kind()=%s, Len()=%d", bjv.Kind(), bjv.Len())
+	}
+	if keys := strings.Join(bjv.Keys(), ","); keys != "id,name,tags" {
+		t.Errorf("Unexpected Keys()=%s", keys)
+	}
+	if id, ok := bjv.Member("id"); !ok || id.Kind() != BigInt || id.String() != "18446744073709551616" {
+		t.Errorf("Unexpected Member(id)=%s, ok=%t", id, ok)
+	}
+	if name, ok := bjv.Member("name"); !ok || name.String() != "bar" {
+		t.Errorf("Unexpected Member(name)=%s, ok=%t", name, ok)
+	}
+	if _, ok := bjv.Member("missing"); ok {
+		t.Errorf("Unexpected Member(missing)")
+	}
+	tags, _ := bjv.Member("tags")
+	if keys := strings.Join(tags.Keys(), ","); keys != "z,a,m" {
+		t.Errorf("Unexpected tags Keys()=%s", keys)
+	}
+	if m, _ := tags.Member("m"); m.Kind() != BigFloat {
+		t.Errorf("Unexpected tags Member(m) Kind()=%s", m.Kind())
+	}
+	if z, ok := tags.Member("z"); !ok || !z.IsNil() {
+		t.Errorf("Unexpected tags Member(z)=%s, ok=%t", z, ok)
+	}
+	if str := bjv.String(); str != `{"id":18446744073709551616,"name":"bar","tags":{"z":null,"a":true,"m":-3.14}}` {
+		t.Errorf("Unexpected String()=%s", str)
+	}
+
+	str := BigJSONValue{}
+	str.DecodeJSONValue(`"foo"`)
+	if _, ok := str.Member("foo"); ok || str.Len() != 0 || str.Keys() != nil {
+		t.Errorf("Unexpected object accessors on string value")
+	}
+
+	for _, text := range []string{`{}`, `{ }`} {
+		empty, err := new(BigJSONValue).DecodeJSONValue(text)
+		if err != nil || empty.Kind() != Object || empty.Len() != 0 {
+			t.Errorf("Unexpected decode of %s: Kind()=%s, err=%v", text, empty.Kind(), err)
+		}
+	}
+
+	invalidList := []string{
+		`{ "a": }`,
+		`{ "a": 1, }`,
+		`{ "a" 1 }`,
+		`{ a: 1 }`,
+		`{ "a": 0123 }`,
+		`{ "a": 1 } { "b": 2 }`,
+		`{ "a": 1 }}`,
+	}
+	for _, text := range invalidList {
+		bjv := BigJSONValue{}
+		if _, err := bjv.DecodeJSONValue(text); !errors.Is(err, ErrInvalidJSON) {
+			t.Errorf("Unexpected decode of %s: err=%v", text, err)
+		}
+		if !bjv.IsNil() {
+			t.Errorf("Unexpected decode of %s: Kind()=%s", text, bjv.Kind())
+		}
+	}
+}
+
+func TestBigDecodeArray(t *testing.T) {
+	jsonStr := `[ 18446744073709551616, -3.14, "foo", null, [ true, [] ], { "a": [ 1 ] } ]`
+	expectedKinds := []Kind{BigInt, BigFloat, String, Nil, Array, Object}
+
+	bjv, err := new(BigJSONValue).DecodeJSONValue(jsonStr)
+	if err != nil {
+		t.Fatalf("DecodeJSONValue err=%s", err)
+	}
+	if bjv.Kind() != Array || bjv.Len() != len(expectedKinds) {
+		t.Fatalf("Unexpected Kind()=%s, Len()=%d", bjv.Kind(), bjv.Len())
+	}
+	bjv.Range(func(idx int, elem *BigJSONValue) bool {
+		if elem.Kind() != expectedKinds[idx] {
+			t.Errorf("Unexpected elem %d Kind()=%s", idx, elem.Kind())
+		}
+		return true
+	})
+	if str := bjv.String(); str != `[18446744073709551616,-3.14,"foo",null,[true,[]],{"a":[1]}]` {
+		t.Errorf("Unexpected String()=%s", str)
+	}
+
+	invalidList := []string{
+		`[ 1, ]`,
+		`[ 1 2 ]`,
+		`[ 0123 ]`,
+		`[ 1 ] [ 2 ]`,
+	}
+	for _, text := range invalidList {
+		bjv := BigJSONValue{}
+		if _, err := bjv.DecodeJSONValue(text); !errors.Is(err, ErrInvalidJSON) {
+			t.Errorf("Unexpected decode of %s: err=%v", text, err)
+		}
+		if !bjv.IsNil() {
+			t.Errorf("Unexpected decode of %s: Kind()=%s", text, bjv.Kind())
+		}
+	}
+}
+
 type bigWalChangeRec struct {
 	ColumnValues []BigJSONValue `json:"columnvalues"`
 }
@@ -181,7 +307,8 @@ func TestBigUnmarshalJSON(t *testing.T) {
 		"a\\b\\c new\nline",
 		3.14,
 		-987654321987654321,
-		-987654321987654321.987654321987654321
+		-987654321987654321.987654321987654321,
+		{ "id": 987654321987654321 }
 	] }`
 
 	expectedKinds := []Kind{
@@ -192,6 +319,7 @@ func TestBigUnmarshalJSON(t *testing.T) {
 		BigFloat,
 		BigInt,
 		BigFloat,
+		Object,
 	}
 
 	var bigRec bigWalChangeRec
@@ -208,6 +336,164 @@ func TestBigUnmarshalJSON(t *testing.T) {
 	}
 }
 
+func TestBigMarshalJSON(t *testing.T) {
+	for idx, rec := range testList {
+		if rec.bigErr != nil {
+			continue
+		}
+		bjv := BigJSONValue{}
+		bjv.DecodeJSONValue(rec.jsonStr)
+		text, err := bjv.MarshalJSON()
+		if err != nil {
+			t.Errorf("%d: MarshalJSON err=%s, testRec=%+v\n", idx, err, rec)
+			continue
+		}
+		bjv2 := BigJSONValue{}
+		if _, err = bjv2.DecodeJSONValue(string(text)); err != nil {
+			t.Errorf("%d: DecodeJSONValue(%s) err=%s, testRec=%+v\n", idx, text, err, rec)
+		}
+		if bjv2.Kind() != bjv.Kind() || bjv2.String() != bjv.String() {
+			t.Errorf("%d: Round-trip of %s gives %s=%s, testRec=%+v\n", idx, text, bjv2.Kind(), bjv2.String(), rec)
+		}
+	}
+
+	testList := []struct {
+		jsonStr  string
+		expected string
+	}{
+		{`null`, `null`},
+		{`"tab\there \u00e9 \"q\""`, `"tab\there é \"q\""`},
+		{`-18446744073709551616`, `-18446744073709551616`},
+		{`3e0`, `3.0`},
+		{`-0.0`, `-0.0`},
+		{`1.5E+300`, `1.5e+300`},
+		{`{ "b" : 1, "a" : { "c" : 2.5, "d" : "x" } }`, `{"b":1,"a":{"c":2.5,"d":"x"}}`},
+	}
+	for idx, rec := range testList {
+		bjv := BigJSONValue{}
+		if _, err := bjv.DecodeJSONValue(rec.jsonStr); err != nil {
+			t.Errorf("%d: DecodeJSONValue(%s) err=%s", idx, rec.jsonStr, err)
+		}
+		if text, err := json.Marshal(bjv); err != nil || string(text) != rec.expected {
+			t.Errorf("%d: json.Marshal(%s)=%s, err=%v, expected %s", idx, rec.jsonStr, text, err, rec.expected)
+		}
+	}
+
+	var bigRec bigWalChangeRec
+	jsonStr := `{"columnvalues":[null,true,"x",18446744073709551616,-1.25,{"id":-9223372036854775809}]}`
+	if err := json.Unmarshal([]byte(jsonStr), &bigRec); err != nil {
+		t.Fatalf("json.Unmarshal err=%s", err)
+	}
+	if text, err := json.Marshal(bigRec); err != nil || string(text) != jsonStr {
+		t.Errorf("json.Marshal=%s, err=%v", text, err)
+	}
+
+	inf := BigJSONValue{proxy: new(big.Float).SetInf(false)}
+	if _, err := inf.MarshalJSON(); err != ErrUnsupportedValue {
+		t.Errorf("MarshalJSON of +Inf err=%v", err)
+	}
+}
+
+func TestBigAccessorCopies(t *testing.T) {
+	bjv, _ := new(BigJSONValue).DecodeJSONValue(`-123456789012345678901234567890`)
+	bigi := bjv.BigInt()
+	bigi.Add(&bigi, big.NewInt(1))
+	bigi.SetBytes([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12})
+	valuei := bjv.Value().(big.Int)
+	valuei.Neg(&valuei)
+	if str := bjv.String(); str != "-123456789012345678901234567890" {
+		t.Errorf("Changing BigInt() copy changed value to %s", str)
+	}
+	if bjv.BigIntRef() != bjv.BigIntRef() || bjv.BigIntRef().String() != bjv.String() {
+		t.Errorf("BigIntRef() does not return the stored value")
+	}
+
+	bjv, _ = new(BigJSONValue).DecodeJSONValue(`-1234567890.12345678901234567890`)
+	want := bjv.String()
+	bigf := bjv.BigFloat()
+	bigf.Add(&bigf, big.NewFloat(1))
+	bigf.SetMantExp(&bigf, 100)
+	valuef := bjv.Value().(big.Float)
+	valuef.Neg(&valuef)
+	if str := bjv.String(); str != want {
+		t.Errorf("Changing BigFloat() copy changed value to %s", str)
+	}
+	if bigf := bjv.BigFloat(); bigf.Prec() != 128 {
+		t.Errorf("BigFloat() copy has Prec()=%d", bigf.Prec())
+	}
+	if bjv.BigFloatRef() != bjv.BigFloatRef() || bjv.BigFloatRef().Text('g', -1) != bjv.String() {
+		t.Errorf("BigFloatRef() does not return the stored value")
+	}
+
+	bjv, _ = BigDecodeOptions{Decimal: true}.DecodeJSONValue(new(BigJSONValue), `123456789012345678901234567890.99`)
+	unscaled := bjv.Unscaled()
+	unscaled.SetInt64(0)
+	rat, _ := bjv.Rat()
+	rat.SetInt64(0)
+	if str := bjv.String(); str != "123456789012345678901234567890.99" {
+		t.Errorf("Changing Unscaled() or Rat() copy changed value to %s", str)
+	}
+}
+
+func TestBigRetainLiterals(t *testing.T) {
+	text := `{"a":1.50,"b":-0,"c":1E+2,"d":0.0e0,"e":12345678901234567890123.000,"f":"1.50"}`
+	opts := DefaultBigDecodeOptions
+	opts.RetainLiterals = true
+	bjv, err := opts.DecodeJSONValue(new(BigJSONValue), text)
+	if err != nil {
+		t.Fatalf("Unexpected err=%v", err)
+	}
+	if out, _ := bjv.MarshalJSON(); string(out) != text {
+		t.Errorf("Unexpected MarshalJSON()=%s", out)
+	}
+	if member, _ := bjv.Member("c"); member.Literal() != "1E+2" || member.String() != "100" {
+		t.Errorf("Unexpected Literal()=%s, String()=%s", member.Literal(), member.String())
+	}
+	if member, _ := bjv.Member("f"); member.Literal() != "" {
+		t.Errorf("Unexpected string Literal()=%s", member.Literal())
+	}
+	if bjvCopy, _ := new(BigJSONValue).FromInterface(bjv); bjvCopy.String() != text {
+		t.Errorf("Unexpected FromInterface() copy=%s", bjvCopy.String())
+	}
+	if njv, _, _ := bjv.ToNat(OverflowKeepLiteral); njv.String() != text {
+		t.Errorf("Unexpected ToNat()=%s", njv.String())
+	}
+	if njv, loss, _ := bjv.ToNat(OverflowSaturate); njv.String() != text || loss != Rounded {
+		t.Errorf("Unexpected ToNat(OverflowSaturate)=%s, loss=%s", njv.String(), loss)
+	}
+
+	other, _ := new(BigJSONValue).DecodeJSONValue(`{"a":1.5,"b":0,"c":100,"d":0,"e":12345678901234567890123,"f":"1.50"}`)
+	if !bjv.Equal(other) || bjv.Hash64() != other.Hash64() {
+		t.Errorf("Literals changed Equal() or Hash64()")
+	}
+	if member, _ := other.Member("a"); member.Literal() != "" {
+		t.Errorf("Unexpected Literal()=%s without RetainLiterals", member.Literal())
+	}
+
+	if _, err = bjv.DecodeJSONValue(`2.50`); err != nil || bjv.Literal() != "" || bjv.String() != "2.5" {
+		t.Errorf("Decoding again kept Literal()=%s, String()=%s", bjv.Literal(), bjv.String())
+	}
+}
+
+func BenchmarkBigIntCopy(b *testing.B) {
+	bjv, _ := new(BigJSONValue).DecodeJSONValue(`-123456789012345678901234567890`)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		bigi := bjv.BigInt()
+		bigi.Sign()
+	}
+}
+
+func BenchmarkBigIntRef(b *testing.B) {
+	bjv, _ := new(BigJSONValue).DecodeJSONValue(`-123456789012345678901234567890`)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		bjv.BigIntRef().Sign()
+	}
+}
+
 func BenchmarkBigDecodeJSONNumbers(b *testing.B) {
 	bjv := BigJSONValue{}
 	for n := 0; n < b.N; n++ {
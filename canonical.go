@@ -0,0 +1,201 @@
+package bigjsonvalue
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// appendCanonical appends the RFC 8785 canonical JSON text of a proxy
+// value to buf.  Returns the first value that cannot be canonicalized as
+// ErrPrecisionLoss, ErrOverflow or ErrUnsupportedValue.
+func appendCanonical(buf []byte, proxy interface{}) ([]byte, error) {
+	var err error
+	switch proxy.(type) {
+	case nil:
+		return append(buf, "null"...), nil
+	case bool:
+		return strconv.AppendBool(buf, proxy.(bool)), nil
+	case string:
+		return appendCanonicalString(buf, proxy.(string))
+	case *bigObject, *natObject, *hybObject:
+		buf = append(buf, '{')
+		for idx, key := range canonicalKeys(proxy) {
+			if idx > 0 {
+				buf = append(buf, ',')
+			}
+			if buf, err = appendCanonicalString(buf, key); err != nil {
+				return buf, err
+			}
+			buf = append(buf, ':')
+			member, _ := proxyMember(proxy, key)
+			if buf, err = appendCanonical(buf, member); err != nil {
+				return buf, err
+			}
+		}
+		return append(buf, '}'), nil
+	case []BigJSONValue, []NatJSONValue, []HybJSONValue:
+		buf = append(buf, '[')
+		for idx, n := 0, proxyLen(proxy); idx < n; idx++ {
+			if idx > 0 {
+				buf = append(buf, ',')
+			}
+			if buf, err = appendCanonical(buf, proxyIndex(proxy, idx)); err != nil {
+				return buf, err
+			}
+		}
+		return append(buf, ']'), nil
+	default:
+		return appendCanonicalNumber(buf, proxy)
+	}
+}
+
+// appendCanonicalNumber appends a number proxy value to buf as the
+// ECMAScript text of the nearest float64.  Integer values that are not
+// exactly a float64 return ErrPrecisionLoss.
+func appendCanonicalNumber(buf []byte, proxy interface{}) ([]byte, error) {
+	integer := false
+	switch proxy.(type) {
+	case int64, uint64, *big.Int:
+		integer = true
+	case json.Number:
+		ns, _ := scanNumber([]byte(proxy.(json.Number)))
+		integer = !ns.isFloat()
+	case float64:
+		if f64 := proxy.(float64); math.IsInf(f64, 0) || math.IsNaN(f64) {
+			return buf, ErrUnsupportedValue
+		}
+	case *big.Float:
+		if proxy.(*big.Float).IsInf() {
+			return buf, ErrUnsupportedValue
+		}
+	}
+	f64, err := asFloat64(proxy)
+	if err == ErrPrecisionLoss && !integer {
+		err = nil
+	}
+	if err != nil {
+		return buf, err
+	}
+	return appendESNumber(buf, f64), nil
+}
+
+// appendESNumber appends the finite f64 to buf as ECMAScript
+// Number.prototype.toString() would, per RFC 8785 section 3.2.2.3:
+// the shortest digits that round trip, in plain notation for decimal
+// exponents from -6 to 20 and exponential notation otherwise.
+// Negative zero appends "0".
+func appendESNumber(buf []byte, f64 float64) []byte {
+	if f64 == 0 {
+		return append(buf, '0')
+	} else if f64 < 0 {
+		buf = append(buf, '-')
+		f64 = -f64
+	}
+	text := strconv.FormatFloat(f64, 'e', -1, 64)
+	split := strings.IndexByte(text, 'e')
+	digits := strings.Replace(text[:split], ".", "", 1)
+	exp, _ := strconv.Atoi(text[split+1:])
+	k, n := len(digits), exp+1
+	switch {
+	case k <= n && n <= 21:
+		buf = append(buf, digits...)
+		buf = append(buf, strings.Repeat("0", n-k)...)
+	case 0 < n && n <= 21:
+		buf = append(buf, digits[:n]...)
+		buf = append(buf, '.')
+		buf = append(buf, digits[n:]...)
+	case -6 < n && n <= 0:
+		buf = append(buf, "0."...)
+		buf = append(buf, strings.Repeat("0", -n)...)
+		buf = append(buf, digits...)
+	default:
+		buf = append(buf, digits[0])
+		if k > 1 {
+			buf = append(buf, '.')
+			buf = append(buf, digits[1:]...)
+		}
+		buf = append(buf, 'e')
+		if exp >= 0 {
+			buf = append(buf, '+')
+		}
+		buf = strconv.AppendInt(buf, int64(exp), 10)
+	}
+	return buf
+}
+
+// appendCanonicalString appends s to buf as a double-quoted JSON string,
+// escaping only double-quotes, backslashes and control characters, per
+// RFC 8785 section 3.2.2.2.  Invalid UTF-8 returns ErrUnsupportedValue.
+func appendCanonicalString(buf []byte, s string) ([]byte, error) {
+	if !utf8.ValidString(s) {
+		return buf, ErrUnsupportedValue
+	}
+	buf = append(buf, '"')
+	start := 0
+	for idx := 0; idx < len(s); idx++ {
+		c := s[idx]
+		if c >= 0x20 && c != '"' && c != '\\' {
+			continue
+		}
+		buf = append(buf, s[start:idx]...)
+		switch c {
+		case '"', '\\':
+			buf = append(buf, '\\', c)
+		case '\b':
+			buf = append(buf, '\\', 'b')
+		case '\f':
+			buf = append(buf, '\\', 'f')
+		case '\n':
+			buf = append(buf, '\\', 'n')
+		case '\r':
+			buf = append(buf, '\\', 'r')
+		case '\t':
+			buf = append(buf, '\\', 't')
+		default:
+			buf = append(buf, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xF])
+		}
+		start = idx + 1
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"'), nil
+}
+
+// canonicalKeys returns a copy of the keys of a proxy object value,
+// sorted by their UTF-16 code units per RFC 8785 section 3.2.3.
+func canonicalKeys(proxy interface{}) []string {
+	keys := append([]string(nil), proxyKeys(proxy)...)
+	sort.Slice(keys, func(i, j int) bool {
+		return compareUTF16(keys[i], keys[j]) < 0
+	})
+	return keys
+}
+
+// compareUTF16 compares two strings by their UTF-16 code units,
+// returning -1, 0 or +1.  This only differs from comparing their UTF-8
+// bytes when one has a rune beyond U+FFFF where the other has a rune
+// from U+E000 to U+FFFF, as the former starts with a lower surrogate.
+func compareUTF16(a string, b string) int {
+	for a != "" && b != "" {
+		runeA, sizeA := utf8.DecodeRuneInString(a)
+		runeB, sizeB := utf8.DecodeRuneInString(b)
+		if runeA != runeB {
+			unitA, unitB := runeA, runeB
+			if unitA > 0xFFFF && unitB <= 0xFFFF {
+				unitA = 0xD800 + (unitA-0x10000)>>10
+			} else if unitB > 0xFFFF && unitA <= 0xFFFF {
+				unitB = 0xD800 + (unitB-0x10000)>>10
+			}
+			if unitA < unitB {
+				return -1
+			}
+			return 1
+		}
+		a, b = a[sizeA:], b[sizeB:]
+	}
+	return compareInts(len(a), len(b))
+}
@@ -0,0 +1,149 @@
+package bigjsonvalue
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// Example from RFC 8785 section 3.2.2.
+const canonicalInput = `{
+  "numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+  "literals": [null, true, false]
+}`
+
+const canonicalOutput = `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],` +
+	`"string":"€$\u000f\nA'B\"\\\\\"/"}`
+
+func TestAppendESNumber(t *testing.T) {
+	// Examples from RFC 8785 appendix B.
+	testList := []struct {
+		bits     uint64
+		expected string
+	}{
+		{0x0000000000000000, "0"},
+		{0x8000000000000000, "0"},
+		{0x0000000000000001, "5e-324"},
+		{0x8000000000000001, "-5e-324"},
+		{0x7fefffffffffffff, "1.7976931348623157e+308"},
+		{0xffefffffffffffff, "-1.7976931348623157e+308"},
+		{0x4340000000000000, "9007199254740992"},
+		{0xc340000000000000, "-9007199254740992"},
+		{0x4430000000000000, "295147905179352830000"},
+		{0x44b52d02c7e14af5, "9.999999999999997e+22"},
+		{0x44b52d02c7e14af6, "1e+23"},
+		{0x44b52d02c7e14af7, "1.0000000000000001e+23"},
+		{0x444b1ae4d6e2ef4e, "999999999999999700000"},
+		{0x444b1ae4d6e2ef4f, "999999999999999900000"},
+		{0x444b1ae4d6e2ef50, "1e+21"},
+		{0x3eb0c6f7a0b5ed8c, "9.999999999999997e-7"},
+		{0x3eb0c6f7a0b5ed8d, "0.000001"},
+		{0x41b3de4355555553, "333333333.3333332"},
+		{0x41b3de4355555554, "333333333.33333325"},
+		{0x41b3de4355555555, "333333333.3333333"},
+		{0x41b3de4355555556, "333333333.3333334"},
+		{0x41b3de4355555557, "333333333.33333343"},
+		{0xbecbf647612f3696, "-0.0000033333333333333333"},
+		{0x43143ff3c1cb0959, "1424953923781206.2"},
+	}
+	for idx, rec := range testList {
+		f64 := math.Float64frombits(rec.bits)
+		if text := string(appendESNumber(nil, f64)); text != rec.expected {
+			t.Errorf("%d: appendESNumber(%v)=%s, expected %s", idx, f64, text, rec.expected)
+		}
+	}
+}
+
+func TestCompareUTF16(t *testing.T) {
+	// Example from RFC 8785 section 3.2.3, in sorted order.
+	keys := []string{"\r", "1", "\u0080", "ö", "€", "\U0001f600", "דּ"}
+	for i := range keys {
+		for j := range keys {
+			if cmp := compareUTF16(keys[i], keys[j]); cmp != compareInts(i, j) {
+				t.Errorf("compareUTF16(%q, %q)=%d", keys[i], keys[j], cmp)
+			}
+		}
+	}
+	if cmp := compareUTF16("ab", "abc"); cmp != -1 {
+		t.Errorf("compareUTF16(ab, abc)=%d", cmp)
+	}
+}
+
+func TestBigCanonicalize(t *testing.T) {
+	testList := []struct {
+		jsonStr  string
+		decimal  bool
+		expected string
+		err      error
+	}{
+		{canonicalInput, false, canonicalOutput, nil},
+		{canonicalInput, true, canonicalOutput, nil},
+		{`{"€":1,"\r":2,"דּ":3,"1":4,"😀":5,"\u0080":6,"ö":7}`, false,
+			`{"\r":2,"1":4,"` + "\u0080" + `":6,"ö":7,"€":1,"😀":5,"דּ":3}`, nil},
+		{`{"b":[{"z":1,"y":[]},{}],"a":{"d":-0,"c":" "}}`, false,
+			`{"a":{"c":"` + " " + `","d":0},"b":[{"y":[],"z":1},{}]}`, nil},
+		{`[9007199254740992, 1152921504606846976, -18446744073709551616, 1e400]`, false,
+			"", ErrOverflow},
+		{`[9007199254740992, 1152921504606846976, -18446744073709551616]`, false,
+			`[9007199254740992,1152921504606847000,-18446744073709552000]`, nil},
+		{`{"id":9007199254740993}`, false, "", ErrPrecisionLoss},
+		{`[0.1, 9007199254740993.0, 1.00000000000000000001]`, false, `[0.1,9007199254740992,1]`, nil},
+		{`[0.1, 9007199254740993.0, 1.00000000000000000001]`, true, `[0.1,9007199254740992,1]`, nil},
+		{`1e400`, true, "", ErrOverflow},
+	}
+	for idx, rec := range testList {
+		bjv, _ := BigDecodeOptions{Decimal: rec.decimal}.DecodeJSONValue(new(BigJSONValue), rec.jsonStr)
+		text, err := bjv.Canonicalize()
+		if !errors.Is(err, rec.err) {
+			t.Errorf("%d: Canonicalize(%s) unexpected err=%v", idx, rec.jsonStr, err)
+		} else if err != nil && text != nil {
+			t.Errorf("%d: Canonicalize(%s)=%s with err=%v", idx, rec.jsonStr, text, err)
+		} else if err == nil && string(text) != rec.expected {
+			t.Errorf("%d: Canonicalize(%s)=%s, expected %s", idx, rec.jsonStr, text, rec.expected)
+		}
+	}
+
+	bjv, _ := BigDecodeOptions{RetainLiterals: true}.DecodeJSONValue(new(BigJSONValue), `[1.50, 1E3]`)
+	if text, _ := bjv.Canonicalize(); string(text) != `[1.5,1000]` {
+		t.Errorf("Canonicalize() of retained literals=%s", text)
+	}
+	if _, err := NewBigString("bad\xffutf8").Canonicalize(); err != ErrUnsupportedValue {
+		t.Errorf("Canonicalize() of invalid UTF-8 err=%v", err)
+	}
+}
+
+func TestNatCanonicalize(t *testing.T) {
+	testList := []struct {
+		jsonStr  string
+		overflow OverflowPolicy
+		expected string
+		err      error
+	}{
+		{canonicalInput, OverflowError, canonicalOutput, nil},
+		{`{"z":[18446744073709551615],"a":-9223372036854775808}`, OverflowError, "", ErrPrecisionLoss},
+		{`{"z":[9223372036854775808],"a":-9223372036854775808}`, OverflowError,
+			`{"a":-9223372036854776000,"z":[9223372036854776000]}`, nil},
+		{`[36893488147419103232, 1e400]`, OverflowPromote, "", ErrOverflow},
+		{`[36893488147419103232, 1.00000000000000000001]`, OverflowPromote, `[36893488147419103000,1]`, nil},
+		{`[36893488147419103233]`, OverflowPromote, "", ErrPrecisionLoss},
+		{`[36893488147419103232, 1e400]`, OverflowKeepLiteral, "", ErrOverflow},
+		{`[36893488147419103232, 1e-400]`, OverflowKeepLiteral, `[36893488147419103000,0]`, nil},
+		{`[36893488147419103233]`, OverflowKeepLiteral, "", ErrPrecisionLoss},
+	}
+	for idx, rec := range testList {
+		njv, _ := NatDecodeOptions{Overflow: rec.overflow}.DecodeJSONValue(new(NatJSONValue), rec.jsonStr)
+		text, err := njv.Canonicalize()
+		if !errors.Is(err, rec.err) {
+			t.Errorf("%d: Canonicalize(%s) unexpected err=%v", idx, rec.jsonStr, err)
+		} else if err == nil && string(text) != rec.expected {
+			t.Errorf("%d: Canonicalize(%s)=%s, expected %s", idx, rec.jsonStr, text, rec.expected)
+		}
+	}
+
+	for _, f64 := range []float64{math.Inf(1), math.NaN()} {
+		if _, err := NewNatFloat64(f64).Canonicalize(); err != ErrUnsupportedValue {
+			t.Errorf("Canonicalize(%v) err=%v", f64, err)
+		}
+	}
+}
@@ -0,0 +1,357 @@
+package bigjsonvalue
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// approxPrec is the precision numbers too large or small to compare
+// exactly are rounded to before comparing.
+const approxPrec = 512
+
+// maxRatBits is the largest difference between the bit lengths of the
+// numerator and denominator of a number that is compared exactly, about
+// the same magnitude as a power of ten of maxRatScale.
+const maxRatBits = maxRatScale * 3322 / 1000
+
+// numKey holds a number proxy value in a form every number kind can be
+// compared and hashed in.
+type numKey struct {
+	nan bool       // true if NaN
+	inf int        // sign of an infinite value, otherwise 0
+	rat *big.Rat   // exact value, if not too large or small
+	flt *big.Float // value rounded to approxPrec, if rat is nil
+}
+
+// newNumKey returns the numKey of a number proxy value.
+func newNumKey(proxy interface{}) numKey {
+	var key numKey
+	switch proxy.(type) {
+	case int64:
+		key.rat = new(big.Rat).SetInt64(proxy.(int64))
+	case uint64:
+		key.rat = new(big.Rat).SetUint64(proxy.(uint64))
+	case *big.Int:
+		key.rat = new(big.Rat).SetInt(proxy.(*big.Int))
+	case float64:
+		f64 := proxy.(float64)
+		if math.IsNaN(f64) {
+			key.nan = true
+		} else if math.IsInf(f64, 0) {
+			key.inf = int(math.Copysign(1, f64))
+		} else {
+			key.setFloat(new(big.Float).SetFloat64(f64))
+		}
+	case *big.Float:
+		bigf := proxy.(*big.Float)
+		if bigf.IsInf() {
+			key.inf = bigf.Sign()
+		} else {
+			key.setFloat(bigf)
+		}
+	case bigDecimal:
+		key.setDecimal(proxy.(bigDecimal))
+	case json.Number:
+		dec, err := parseDecimal([]byte(proxy.(json.Number)))
+		if err == strconv.ErrRange {
+			key.flt = approxNumber(numberText(proxy))
+		} else if err != nil {
+			key.nan = true
+		} else {
+			key.setDecimal(dec)
+		}
+	}
+	if key.rat != nil {
+		num, denom := key.rat.Num().BitLen(), key.rat.Denom().BitLen()
+		if num-denom > maxRatBits || denom-num > maxRatBits {
+			key.flt = new(big.Float).SetPrec(approxPrec).SetRat(key.rat)
+			key.rat = nil
+		}
+	} else if key.flt != nil && key.flt.Sign() == 0 {
+		key.rat, key.flt = new(big.Rat), nil
+	}
+	return key
+}
+
+// setFloat sets the key to the value of the finite bigf.  Only values
+// clearly beyond maxRatBits skip the exact big.Rat, so that whether a
+// value is compared exactly never depends on its kind.
+func (key *numKey) setFloat(bigf *big.Float) {
+	if exp := bigf.MantExp(nil); exp > maxRatBits+2 || exp < -maxRatBits-2 {
+		key.flt = new(big.Float).SetPrec(approxPrec).Set(bigf)
+	} else {
+		key.rat, _ = bigf.Rat(nil)
+	}
+}
+
+// setDecimal sets the key to the value of dec, like setFloat().  Its
+// binary exponent is estimated from the size of its unscaled value and
+// scale, so powers of ten are only expanded when within maxRatBits.
+func (key *numKey) setDecimal(dec bigDecimal) {
+	exp := float64(dec.unscaled.BitLen()) - float64(dec.scale)*math.Log2(10)
+	if dec.unscaled.Sign() == 0 {
+		key.rat = new(big.Rat)
+	} else if exp > maxRatBits+4 || exp < -maxRatBits-4 {
+		key.flt = approxNumber(numberText(dec))
+	} else {
+		key.rat = dec.rat()
+	}
+}
+
+// float returns the key value rounded to approxPrec.
+func (key *numKey) float() *big.Float {
+	if key.flt != nil {
+		return key.flt
+	}
+	return new(big.Float).SetPrec(approxPrec).SetRat(key.rat)
+}
+
+// cmp compares key and other, ordering NaN before all other numbers.
+func (key *numKey) cmp(other *numKey) int {
+	if key.nan || other.nan {
+		return compareBools(!key.nan, !other.nan)
+	} else if key.inf != 0 || other.inf != 0 {
+		return compareInts(key.inf, other.inf)
+	} else if key.rat != nil && other.rat != nil {
+		return key.rat.Cmp(other.rat)
+	}
+	return key.float().Cmp(other.float())
+}
+
+// numberText returns the JSON number text of a decimal or json.Number.
+// Decimals are in exponent form, so tiny ones are not written out with
+// all their leading zeros.
+func numberText(proxy interface{}) string {
+	if dec, ok := proxy.(bigDecimal); ok {
+		return dec.unscaled.String() + "e" + strconv.Itoa(-dec.scale)
+	}
+	return proxy.(json.Number).String()
+}
+
+// approxNumber returns number text rounded to approxPrec.  Numbers
+// beyond the range of big.Float round to ±Inf or ±0.
+func approxNumber(text string) *big.Float {
+	bigf, _, err := new(big.Float).SetPrec(approxPrec).Parse(text, 10)
+	if err == nil {
+		return bigf
+	}
+	bigf = new(big.Float).SetPrec(approxPrec)
+	if !strings.Contains(text, "e-") && !strings.Contains(text, "E-") {
+		bigf.SetInf(false)
+	}
+	if strings.HasPrefix(text, "-") {
+		bigf.Neg(bigf)
+	}
+	return bigf
+}
+
+// proxyKind returns the Kind of any proxy value.
+func proxyKind(proxy interface{}) Kind {
+	switch proxy.(type) {
+	case bool:
+		return Bool
+	case string:
+		return String
+	case int64:
+		return Int64
+	case uint64:
+		return Uint64
+	case float64:
+		return Float64
+	case *big.Int:
+		return BigInt
+	case *big.Float:
+		return BigFloat
+	case bigDecimal:
+		return Decimal
+	case json.Number:
+		return Number
+	case *bigObject, *natObject, *hybObject:
+		return Object
+	case []BigJSONValue, []NatJSONValue, []HybJSONValue:
+		return Array
+	default:
+		return Nil
+	}
+}
+
+// kindRank returns the position of a kind in the order values of
+// different kinds compare in: nil, bool, number, string, array, object.
+func kindRank(kind Kind) int {
+	switch kind {
+	case Nil:
+		return 0
+	case Bool:
+		return 1
+	case String:
+		return 3
+	case Array:
+		return 4
+	case Object:
+		return 5
+	default:
+		return 2
+	}
+}
+
+// compareProxies compares two proxy values, returning -1, 0 or +1.
+func compareProxies(a interface{}, b interface{}) int {
+	rankA, rankB := kindRank(proxyKind(a)), kindRank(proxyKind(b))
+	if rankA != rankB {
+		return compareInts(rankA, rankB)
+	}
+	switch rankA {
+	case 1:
+		return compareBools(a.(bool), b.(bool))
+	case 2:
+		return compareNumbers(a, b)
+	case 3:
+		return strings.Compare(a.(string), b.(string))
+	case 4:
+		lenA, lenB := proxyLen(a), proxyLen(b)
+		for idx := 0; idx < lenA && idx < lenB; idx++ {
+			if c := compareProxies(proxyIndex(a, idx), proxyIndex(b, idx)); c != 0 {
+				return c
+			}
+		}
+		return compareInts(lenA, lenB)
+	case 5:
+		keysA, keysB := sortedKeys(a), sortedKeys(b)
+		for idx := 0; idx < len(keysA) && idx < len(keysB); idx++ {
+			if c := strings.Compare(keysA[idx], keysB[idx]); c != 0 {
+				return c
+			}
+			memberA, _ := proxyMember(a, keysA[idx])
+			memberB, _ := proxyMember(b, keysB[idx])
+			if c := compareProxies(memberA, memberB); c != 0 {
+				return c
+			}
+		}
+		return compareInts(len(keysA), len(keysB))
+	default:
+		return 0
+	}
+}
+
+// equalProxies returns true if two proxy values compare equal.
+// It avoids sorting object keys, so is faster than compareProxies.
+func equalProxies(a interface{}, b interface{}) bool {
+	kindA, kindB := proxyKind(a), proxyKind(b)
+	switch {
+	case kindRank(kindA) != kindRank(kindB):
+		return false
+	case kindA == Array:
+		if proxyLen(a) != proxyLen(b) {
+			return false
+		}
+		for idx := proxyLen(a) - 1; idx >= 0; idx-- {
+			if !equalProxies(proxyIndex(a, idx), proxyIndex(b, idx)) {
+				return false
+			}
+		}
+		return true
+	case kindA == Object:
+		keys := proxyKeys(a)
+		if len(keys) != len(proxyKeys(b)) {
+			return false
+		}
+		for _, key := range keys {
+			memberA, _ := proxyMember(a, key)
+			memberB, ok := proxyMember(b, key)
+			if !ok || !equalProxies(memberA, memberB) {
+				return false
+			}
+		}
+		return true
+	default:
+		return compareProxies(a, b) == 0
+	}
+}
+
+// compareNumbers compares two number proxy values exactly, returning
+// -1, 0 or +1.  NaN compares equal to itself and less than all other
+// numbers, so that numbers have a total order.
+func compareNumbers(a interface{}, b interface{}) int {
+	switch a.(type) {
+	case int64:
+		switch b.(type) {
+		case int64:
+			return compareInt64s(a.(int64), b.(int64))
+		case uint64:
+			if a.(int64) < 0 {
+				return -1
+			}
+			return compareUint64s(uint64(a.(int64)), b.(uint64))
+		}
+	case uint64:
+		switch b.(type) {
+		case uint64:
+			return compareUint64s(a.(uint64), b.(uint64))
+		case int64:
+			return -compareNumbers(b, a)
+		}
+	case float64:
+		if f64b, ok := b.(float64); ok && !math.IsNaN(a.(float64)) && !math.IsNaN(f64b) {
+			f64a := a.(float64)
+			if f64a < f64b {
+				return -1
+			} else if f64a > f64b {
+				return 1
+			}
+			return 0
+		}
+	}
+	keyA, keyB := newNumKey(a), newNumKey(b)
+	return keyA.cmp(&keyB)
+}
+
+// sortedKeys returns a sorted copy of the keys of a proxy object value.
+func sortedKeys(proxy interface{}) []string {
+	keys := append([]string(nil), proxyKeys(proxy)...)
+	sort.Strings(keys)
+	return keys
+}
+
+// compareBools compares two bools, ordering false before true.
+func compareBools(a bool, b bool) int {
+	if a == b {
+		return 0
+	} else if b {
+		return -1
+	}
+	return 1
+}
+
+// compareInts compares two ints, returning -1, 0 or +1.
+func compareInts(a int, b int) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+// compareInt64s compares two int64s, returning -1, 0 or +1.
+func compareInt64s(a int64, b int64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+// compareUint64s compares two uint64s, returning -1, 0 or +1.
+func compareUint64s(a uint64, b uint64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
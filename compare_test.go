@@ -0,0 +1,137 @@
+package bigjsonvalue
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+func bigProxy(text string) interface{} {
+	bjv, _ := new(BigJSONValue).DecodeJSONValue(text)
+	return bjv.proxy
+}
+
+func decimalProxy(text string) interface{} {
+	bjv, _ := BigDecodeOptions{Decimal: true}.DecodeJSONValue(new(BigJSONValue), text)
+	return bjv.proxy
+}
+
+func natProxy(text string) interface{} {
+	njv, _ := NatDecodeOptions{Overflow: OverflowKeepLiteral}.DecodeJSONValue(new(NatJSONValue), text)
+	return njv.proxy
+}
+
+func hybProxy(text string) interface{} {
+	hjv, _ := new(HybJSONValue).DecodeJSONValue(text)
+	return hjv.proxy
+}
+
+func goProxy(v interface{}) interface{} {
+	bjv, _ := new(BigJSONValue).FromInterface(v)
+	return bjv.proxy
+}
+
+// compareList holds groups of equal proxy values in ascending order.
+var compareList = [][]interface{}{
+	{nil, natProxy("null")},
+	{false, hybProxy("false")},
+	{true},
+	{math.NaN()},
+	{math.Inf(-1)},
+	{natProxy("-1e400"), decimalProxy("-1e400")},
+	{natProxy("-9223372036854775808"), bigProxy("-9223372036854775808"), bigProxy("-9223372036854775808.0")},
+	{natProxy("-1"), bigProxy("-1.0"), decimalProxy("-1.00"), hybProxy("-1e0")},
+	{natProxy("0"), natProxy("-0"), natProxy("-0.0"), bigProxy("0"), bigProxy("0e10"), decimalProxy("0.000")},
+	{decimalProxy("1e-200000000")},
+	{decimalProxy("0.1")},
+	{natProxy("0.1")},
+	{natProxy("1"), int64(1), natProxy("1.0"), bigProxy("1"), bigProxy("1.0"), decimalProxy("1.00"), decimalProxy("0.01e2")},
+	{natProxy("9007199254740993.0"), natProxy("9007199254740992")},
+	{natProxy("9007199254740993"), decimalProxy("9007199254740993.0")},
+	{natProxy("18446744073709551615"), bigProxy("18446744073709551615"), hybProxy("18446744073709551615")},
+	{bigProxy("1180591620717411303424"), hybProxy("1180591620717411303424"), decimalProxy("1180591620717411303424e0")},
+	{natProxy("1e400"), decimalProxy("1e400")},
+	{decimalProxy("1e100001"), natProxy("1e100001")},
+	{decimalProxy("1e2147483647"), natProxy("1e9999999999")},
+	{math.Inf(1)},
+	{""},
+	{"a", natProxy(`"a"`)},
+	{"b"},
+	{goProxy([]int{}), natProxy("[]")},
+	{goProxy([]int{1}), natProxy("[1.0]"), hybProxy("[1]")},
+	{goProxy([]int{1, 2}), natProxy("[1, 2]")},
+	{goProxy([]int{2})},
+	{bigProxy("{}"), hybProxy("{}")},
+	{bigProxy(`{"a": 1}`), hybProxy(`{"a": 1.0}`)},
+	{bigProxy(`{"a": 1, "b": 1}`), bigProxy(`{"b": 1.0, "a": 1}`), goProxy(map[string]int{"a": 1, "b": 1})},
+	{bigProxy(`{"a": 2}`)},
+	{bigProxy(`{"b": 0}`)},
+}
+
+func TestCompareProxies(t *testing.T) {
+	for idxA, groupA := range compareList {
+		for idxB, groupB := range compareList {
+			expected := compareInts(idxA, idxB)
+			for _, a := range groupA {
+				for _, b := range groupB {
+					if c := compareProxies(a, b); c != expected {
+						t.Errorf("compareProxies(%v, %v)=%d, expected %d", a, b, c, expected)
+					}
+					if eq := equalProxies(a, b); eq != (expected == 0) {
+						t.Errorf("equalProxies(%v, %v)=%t, expected %t", a, b, eq, expected == 0)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	texts := []string{`{"a": 1}`, `"1"`, `1.5`, `1`, `true`, `null`, `1e400`, `-1`}
+	values := make([]*BigJSONValue, len(texts))
+	for idx, text := range texts {
+		values[idx], _ = new(BigJSONValue).DecodeJSONValue(text)
+	}
+	arr, _ := new(BigJSONValue).FromInterface([]int{1})
+	values = append(values, arr)
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].Compare(values[j]) < 0
+	})
+	var sorted []string
+	for _, bjv := range values {
+		text, _ := bjv.MarshalJSON()
+		sorted = append(sorted, string(text))
+	}
+	expected := []string{`null`, `true`, `-1`, `1`, `1.5`, `1e+400`, `"1"`, `[1]`, `{"a":1}`}
+	for idx := range expected {
+		if sorted[idx] != expected[idx] {
+			t.Fatalf("Sorted %v, expected %v", sorted, expected)
+		}
+	}
+
+	njvA, _ := new(NatJSONValue).DecodeJSONValue(`[1, 18446744073709551615]`)
+	njvB, _ := new(NatJSONValue).FromInterface([]interface{}{1.0, uint64(math.MaxUint64)})
+	if !njvA.Equal(njvB) || njvA.Compare(njvB) != 0 {
+		t.Errorf("%s is not equal to %s", njvA, njvB)
+	}
+	hjvA, _ := new(HybJSONValue).DecodeJSONValue(`18446744073709551616`)
+	hjvB, _ := new(HybJSONValue).DecodeJSONValue(`18446744073709551615`)
+	if hjvA.Equal(hjvB) || hjvA.Compare(hjvB) != 1 || hjvB.Compare(hjvA) != -1 {
+		t.Errorf("%s is not greater than %s", hjvA, hjvB)
+	}
+}
+
+func BenchmarkCompareInt64(b *testing.B) {
+	njvA, njvB := NewNatInt64(-1), NewNatUint64(1)
+	for n := 0; n < b.N; n++ {
+		njvA.Compare(njvB)
+	}
+}
+
+func BenchmarkCompareMixed(b *testing.B) {
+	bjvA, _ := new(BigJSONValue).DecodeJSONValue(`123456789012345678901234567890`)
+	bjvB, _ := new(BigJSONValue).DecodeJSONValue(`1.23456789012345678901234567890e29`)
+	for n := 0; n < b.N; n++ {
+		bjvA.Compare(bjvB)
+	}
+}
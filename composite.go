@@ -0,0 +1,67 @@
+package bigjsonvalue
+
+// proxyKeys returns the keys of a proxy object value in order, without
+// copying them, or nil if not an object.
+func proxyKeys(proxy interface{}) []string {
+	switch proxy.(type) {
+	case *bigObject:
+		return proxy.(*bigObject).keys
+	case *natObject:
+		return proxy.(*natObject).keys
+	case *hybObject:
+		return proxy.(*hybObject).keys
+	default:
+		return nil
+	}
+}
+
+// proxyMember returns the proxy value of the member of a proxy object
+// value with the given key.  Returns false if not present.
+func proxyMember(proxy interface{}, key string) (interface{}, bool) {
+	switch proxy.(type) {
+	case *bigObject:
+		member, ok := proxy.(*bigObject).members[key]
+		if ok {
+			return member.proxy, true
+		}
+	case *natObject:
+		member, ok := proxy.(*natObject).members[key]
+		if ok {
+			return member.proxy, true
+		}
+	case *hybObject:
+		member, ok := proxy.(*hybObject).members[key]
+		if ok {
+			return member.proxy, true
+		}
+	}
+	return nil, false
+}
+
+// proxyLen returns the number of elements of a proxy array value,
+// or 0 if not an array.
+func proxyLen(proxy interface{}) int {
+	switch proxy.(type) {
+	case []BigJSONValue:
+		return len(proxy.([]BigJSONValue))
+	case []NatJSONValue:
+		return len(proxy.([]NatJSONValue))
+	case []HybJSONValue:
+		return len(proxy.([]HybJSONValue))
+	default:
+		return 0
+	}
+}
+
+// proxyIndex returns the proxy value of the element of a proxy array
+// value at index idx, which must be in range.
+func proxyIndex(proxy interface{}, idx int) interface{} {
+	switch proxy.(type) {
+	case []BigJSONValue:
+		return proxy.([]BigJSONValue)[idx].proxy
+	case []NatJSONValue:
+		return proxy.([]NatJSONValue)[idx].proxy
+	default:
+		return proxy.([]HybJSONValue)[idx].proxy
+	}
+}
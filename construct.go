@@ -0,0 +1,506 @@
+package bigjsonvalue
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+)
+
+// UnsupportedTypeError reports a Go value that FromInterface() has no
+// conversion for, such as a struct, channel or function, or a map whose
+// keys are not strings.
+type UnsupportedTypeError struct {
+	Type reflect.Type // type of the value being converted
+}
+
+// Error implements the error interface for UnsupportedTypeError.
+func (e *UnsupportedTypeError) Error() string {
+	return fmt.Sprintf("cannot convert Go value of type %s", e.Type)
+}
+
+// Unwrap returns ErrUnsupportedValue, so errors.Is(err, ErrUnsupportedValue)
+// works on an UnsupportedTypeError.
+func (e *UnsupportedTypeError) Unwrap() error {
+	return ErrUnsupportedValue
+}
+
+// NewBigNull returns a new nil BigJSONValue.
+func NewBigNull() *BigJSONValue {
+	return &BigJSONValue{}
+}
+
+// NewBigBool returns a new BigJSONValue holding the bool b.
+func NewBigBool(b bool) *BigJSONValue {
+	return &BigJSONValue{proxy: b}
+}
+
+// NewBigString returns a new BigJSONValue holding the string str.
+func NewBigString(str string) *BigJSONValue {
+	return &BigJSONValue{proxy: str}
+}
+
+// NewBigInt returns a new BigJSONValue holding a copy of bigi,
+// or a nil value if bigi is nil.
+func NewBigInt(bigi *big.Int) *BigJSONValue {
+	if bigi == nil {
+		return &BigJSONValue{}
+	}
+	return &BigJSONValue{proxy: new(big.Int).Set(bigi)}
+}
+
+// NewBigFloat returns a new BigJSONValue holding a copy of bigf,
+// with the same precision and rounding mode, or a nil value if bigf is nil.
+func NewBigFloat(bigf *big.Float) *BigJSONValue {
+	if bigf == nil {
+		return &BigJSONValue{}
+	}
+	return &BigJSONValue{proxy: new(big.Float).Copy(bigf)}
+}
+
+// NewNatNull returns a new nil NatJSONValue.
+func NewNatNull() *NatJSONValue {
+	return &NatJSONValue{}
+}
+
+// NewNatBool returns a new NatJSONValue holding the bool b.
+func NewNatBool(b bool) *NatJSONValue {
+	return &NatJSONValue{proxy: b}
+}
+
+// NewNatString returns a new NatJSONValue holding the string str.
+func NewNatString(str string) *NatJSONValue {
+	return &NatJSONValue{proxy: str}
+}
+
+// NewNatInt64 returns a new NatJSONValue holding the int64 i64.
+func NewNatInt64(i64 int64) *NatJSONValue {
+	return &NatJSONValue{proxy: i64}
+}
+
+// NewNatUint64 returns a new NatJSONValue holding the uint64 u64.
+func NewNatUint64(u64 uint64) *NatJSONValue {
+	return &NatJSONValue{proxy: u64}
+}
+
+// NewNatFloat64 returns a new NatJSONValue holding the float64 f64.
+// Infinite and NaN values are held as-is, but cannot be encoded as JSON.
+func NewNatFloat64(f64 float64) *NatJSONValue {
+	return &NatJSONValue{proxy: f64}
+}
+
+// FromInterface converts the Go value v, and returns itself.
+// Results are undefined if error is returned.
+//
+// nil, and nil pointers, slices and maps, are converted to nil values.
+//
+// bool and string values are converted as-is.
+//
+// Signed and unsigned integers of any size are converted to big.Int values.
+//
+// float32 and float64 values are converted exactly to big.Float values
+// with precision 53.  Infinite and NaN values return ErrUnsupportedValue.
+//
+// json.Number values are decoded with DefaultBigDecodeOptions, as
+// DecodeJSONValue() would.
+//
+// *big.Int and *big.Float values are copied, and infinite big.Float
+// values return ErrUnsupportedValue.
+//
+// BigJSONValue values are copied, including all their members or elements
+// and retained literals.
+//
+// []byte values are converted to base64 encoded strings, as
+// json.Marshal() would.  Other slices and arrays are converted to array
+// values, and maps with string keys are converted to object values with
+// their keys in sorted order.
+//
+// Pointers are converted to the value they point to.
+//
+// Maps, slices and pointers that contain themselves, directly or through
+// other values, return ErrCyclicValue.
+//
+// Any other type returns an *UnsupportedTypeError.
+func (bjv *BigJSONValue) FromInterface(v interface{}) (*BigJSONValue, error) {
+	value, err := bigValueFrom(v, make(visiting))
+	*bjv = value
+	return bjv, err
+}
+
+// bigValueFrom converts the Go value v to a BigJSONValue, keeping the
+// literals of any BigJSONValue values in it.
+func bigValueFrom(v interface{}, visits visiting) (BigJSONValue, error) {
+	switch v := v.(type) {
+	case nil:
+		return BigJSONValue{}, nil
+	case bool:
+		return BigJSONValue{proxy: v}, nil
+	case string:
+		return BigJSONValue{proxy: v}, nil
+	case json.Number:
+		var bjv BigJSONValue
+		opts := DefaultBigDecodeOptions
+		err := bjv.decodeJSONValue([]byte(v), &opts)
+		return bjv, err
+	case *big.Int:
+		if v == nil {
+			return BigJSONValue{}, nil
+		}
+		return BigJSONValue{proxy: new(big.Int).Set(v)}, nil
+	case *big.Float:
+		if v == nil {
+			return BigJSONValue{}, nil
+		} else if v.IsInf() {
+			return BigJSONValue{}, ErrUnsupportedValue
+		}
+		return BigJSONValue{proxy: new(big.Float).Copy(v)}, nil
+	case *BigJSONValue:
+		return v.copyValue(), nil
+	case BigJSONValue:
+		return v.copyValue(), nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return BigJSONValue{proxy: rv.Bool()}, nil
+	case reflect.String:
+		return BigJSONValue{proxy: rv.String()}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return BigJSONValue{proxy: big.NewInt(rv.Int())}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return BigJSONValue{proxy: new(big.Int).SetUint64(rv.Uint())}, nil
+	case reflect.Float32, reflect.Float64:
+		f64 := rv.Float()
+		if math.IsInf(f64, 0) || math.IsNaN(f64) {
+			return BigJSONValue{}, ErrUnsupportedValue
+		}
+		return BigJSONValue{proxy: new(big.Float).SetFloat64(f64)}, nil
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return BigJSONValue{}, nil
+		} else if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			return BigJSONValue{proxy: base64.StdEncoding.EncodeToString(rv.Bytes())}, nil
+		} else if rv.Kind() == reflect.Slice {
+			if err := visits.enter(rv); err != nil {
+				return BigJSONValue{}, err
+			}
+			defer visits.leave(rv)
+		}
+		arr := make([]BigJSONValue, rv.Len())
+		for idx := range arr {
+			elem, err := bigValueFrom(rv.Index(idx).Interface(), visits)
+			if err != nil {
+				return BigJSONValue{}, err
+			}
+			arr[idx] = elem
+		}
+		return BigJSONValue{proxy: arr}, nil
+	case reflect.Map:
+		if rv.IsNil() {
+			return BigJSONValue{}, nil
+		} else if err := visits.enter(rv); err != nil {
+			return BigJSONValue{}, err
+		}
+		defer visits.leave(rv)
+		keys, err := sortedMapKeys(rv)
+		if err != nil {
+			return BigJSONValue{}, err
+		}
+		obj := &bigObject{members: make(map[string]*BigJSONValue, len(keys))}
+		for _, key := range keys {
+			member, err := bigValueFrom(rv.MapIndex(key).Interface(), visits)
+			if err != nil {
+				return BigJSONValue{}, err
+			}
+			obj.set(key.String(), &member)
+		}
+		return BigJSONValue{proxy: obj}, nil
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return BigJSONValue{}, nil
+		} else if err := visits.enter(rv); err != nil {
+			return BigJSONValue{}, err
+		}
+		defer visits.leave(rv)
+		return bigValueFrom(rv.Elem().Interface(), visits)
+	default:
+		return BigJSONValue{}, &UnsupportedTypeError{Type: rv.Type()}
+	}
+}
+
+// copyValue returns a copy of the value, including its literal, or null
+// if nil.
+func (bjv *BigJSONValue) copyValue() BigJSONValue {
+	if bjv == nil {
+		return BigJSONValue{}
+	}
+	return BigJSONValue{proxy: bjv.copyProxy(), literal: bjv.literal}
+}
+
+// copyProxy returns a copy of the proxy value, copying objects and arrays
+// all the way down.  Other values are never changed once stored, so are
+// shared rather than copied.
+func (bjv *BigJSONValue) copyProxy() interface{} {
+	switch bjv.proxy.(type) {
+	case *bigObject:
+		obj := bjv.proxy.(*bigObject)
+		objCopy := &bigObject{
+			keys:    append([]string(nil), obj.keys...),
+			members: make(map[string]*BigJSONValue, len(obj.members)),
+		}
+		for key, member := range obj.members {
+			objCopy.members[key] = &BigJSONValue{proxy: member.copyProxy(), literal: member.literal}
+		}
+		return objCopy
+	case []BigJSONValue:
+		arr := bjv.proxy.([]BigJSONValue)
+		arrCopy := make([]BigJSONValue, len(arr))
+		for idx := range arr {
+			arrCopy[idx] = BigJSONValue{proxy: arr[idx].copyProxy(), literal: arr[idx].literal}
+		}
+		return arrCopy
+	default:
+		return bjv.proxy
+	}
+}
+
+// FromInterface converts the Go value v, and returns itself.
+// Results are undefined if error is returned.
+//
+// nil, and nil pointers, slices and maps, are converted to nil values.
+//
+// bool and string values are converted as-is.
+//
+// Signed integers of any size are converted to int64 values, and unsigned
+// integers of any size are converted to uint64 values.
+//
+// float32 and float64 values are converted to float64 values.
+// Infinite and NaN values return ErrUnsupportedValue.
+//
+// json.Number values are decoded with DefaultNatDecodeOptions, as
+// DecodeJSONValue() would.
+//
+// *big.Int values are converted to int64 values if negative or uint64
+// values otherwise, if they fit, and are copied as big.Int values if not.
+// *big.Float values are converted to float64 values if they fit exactly,
+// and are copied as big.Float values if not.  Infinite big.Float values
+// return ErrUnsupportedValue.
+//
+// NatJSONValue values are copied, including all their members or elements
+// and retained literals.
+//
+// []byte values are converted to base64 encoded strings, as
+// json.Marshal() would.  Other slices and arrays are converted to array
+// values, and maps with string keys are converted to object values with
+// their keys in sorted order.
+//
+// Pointers are converted to the value they point to.
+//
+// Maps, slices and pointers that contain themselves, directly or through
+// other values, return ErrCyclicValue.
+//
+// Any other type returns an *UnsupportedTypeError.
+func (njv *NatJSONValue) FromInterface(v interface{}) (*NatJSONValue, error) {
+	value, err := natValueFrom(v, make(visiting))
+	*njv = value
+	return njv, err
+}
+
+// natValueFrom converts the Go value v to a NatJSONValue, keeping the
+// literals of any NatJSONValue values in it.
+func natValueFrom(v interface{}, visits visiting) (NatJSONValue, error) {
+	switch v := v.(type) {
+	case nil:
+		return NatJSONValue{}, nil
+	case bool:
+		return NatJSONValue{proxy: v}, nil
+	case string:
+		return NatJSONValue{proxy: v}, nil
+	case json.Number:
+		var njv NatJSONValue
+		opts := DefaultNatDecodeOptions
+		err := njv.decodeJSONValue([]byte(v), &opts)
+		return njv, err
+	case *big.Int:
+		if v == nil {
+			return NatJSONValue{}, nil
+		} else if v.IsUint64() {
+			return NatJSONValue{proxy: v.Uint64()}, nil
+		} else if v.IsInt64() {
+			return NatJSONValue{proxy: v.Int64()}, nil
+		}
+		return NatJSONValue{proxy: new(big.Int).Set(v)}, nil
+	case *big.Float:
+		if v == nil {
+			return NatJSONValue{}, nil
+		} else if v.IsInf() {
+			return NatJSONValue{}, ErrUnsupportedValue
+		} else if f64, acc := v.Float64(); acc == big.Exact {
+			return NatJSONValue{proxy: f64}, nil
+		}
+		return NatJSONValue{proxy: new(big.Float).Copy(v)}, nil
+	case *NatJSONValue:
+		return v.copyValue(), nil
+	case NatJSONValue:
+		return v.copyValue(), nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return NatJSONValue{proxy: rv.Bool()}, nil
+	case reflect.String:
+		return NatJSONValue{proxy: rv.String()}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return NatJSONValue{proxy: rv.Int()}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return NatJSONValue{proxy: rv.Uint()}, nil
+	case reflect.Float32, reflect.Float64:
+		f64 := rv.Float()
+		if math.IsInf(f64, 0) || math.IsNaN(f64) {
+			return NatJSONValue{}, ErrUnsupportedValue
+		}
+		return NatJSONValue{proxy: f64}, nil
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return NatJSONValue{}, nil
+		} else if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			return NatJSONValue{proxy: base64.StdEncoding.EncodeToString(rv.Bytes())}, nil
+		} else if rv.Kind() == reflect.Slice {
+			if err := visits.enter(rv); err != nil {
+				return NatJSONValue{}, err
+			}
+			defer visits.leave(rv)
+		}
+		arr := make([]NatJSONValue, rv.Len())
+		for idx := range arr {
+			elem, err := natValueFrom(rv.Index(idx).Interface(), visits)
+			if err != nil {
+				return NatJSONValue{}, err
+			}
+			arr[idx] = elem
+		}
+		return NatJSONValue{proxy: arr}, nil
+	case reflect.Map:
+		if rv.IsNil() {
+			return NatJSONValue{}, nil
+		} else if err := visits.enter(rv); err != nil {
+			return NatJSONValue{}, err
+		}
+		defer visits.leave(rv)
+		keys, err := sortedMapKeys(rv)
+		if err != nil {
+			return NatJSONValue{}, err
+		}
+		obj := &natObject{members: make(map[string]*NatJSONValue, len(keys))}
+		for _, key := range keys {
+			member, err := natValueFrom(rv.MapIndex(key).Interface(), visits)
+			if err != nil {
+				return NatJSONValue{}, err
+			}
+			obj.set(key.String(), &member)
+		}
+		return NatJSONValue{proxy: obj}, nil
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return NatJSONValue{}, nil
+		} else if err := visits.enter(rv); err != nil {
+			return NatJSONValue{}, err
+		}
+		defer visits.leave(rv)
+		return natValueFrom(rv.Elem().Interface(), visits)
+	default:
+		return NatJSONValue{}, &UnsupportedTypeError{Type: rv.Type()}
+	}
+}
+
+// copyValue returns a copy of the value, including its literal, or null
+// if nil.
+func (njv *NatJSONValue) copyValue() NatJSONValue {
+	if njv == nil {
+		return NatJSONValue{}
+	}
+	return NatJSONValue{proxy: njv.copyProxy(), literal: njv.literal}
+}
+
+// copyProxy returns a copy of the proxy value, copying objects and arrays
+// all the way down.  Other values are never changed once stored, so are
+// shared rather than copied.
+func (njv *NatJSONValue) copyProxy() interface{} {
+	switch njv.proxy.(type) {
+	case *natObject:
+		obj := njv.proxy.(*natObject)
+		objCopy := &natObject{
+			keys:    append([]string(nil), obj.keys...),
+			members: make(map[string]*NatJSONValue, len(obj.members)),
+		}
+		for key, member := range obj.members {
+			objCopy.members[key] = &NatJSONValue{proxy: member.copyProxy(), literal: member.literal}
+		}
+		return objCopy
+	case []NatJSONValue:
+		arr := njv.proxy.([]NatJSONValue)
+		arrCopy := make([]NatJSONValue, len(arr))
+		for idx := range arr {
+			arrCopy[idx] = NatJSONValue{proxy: arr[idx].copyProxy(), literal: arr[idx].literal}
+		}
+		return arrCopy
+	default:
+		return njv.proxy
+	}
+}
+
+// visitKey identifies a map, slice or pointer by its address and type,
+// and for slices, its length.
+type visitKey struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// visiting holds the maps, slices and pointers FromInterface() is
+// converting, from the top-level value down to the current one, to find
+// values that contain themselves.
+type visiting map[visitKey]bool
+
+// newVisitKey returns the visitKey of the map, slice or pointer rv.
+func newVisitKey(rv reflect.Value) visitKey {
+	key := visitKey{ptr: rv.Pointer(), typ: rv.Type()}
+	if rv.Kind() == reflect.Slice {
+		key.len = rv.Len()
+	}
+	return key
+}
+
+// enter adds rv to visits.  Returns ErrCyclicValue if rv is already
+// being converted, since it contains itself.
+func (visits visiting) enter(rv reflect.Value) error {
+	key := newVisitKey(rv)
+	if visits[key] {
+		return ErrCyclicValue
+	}
+	visits[key] = true
+	return nil
+}
+
+// leave removes rv from visits, once it has been converted.
+func (visits visiting) leave(rv reflect.Value) {
+	delete(visits, newVisitKey(rv))
+}
+
+// sortedMapKeys returns the keys of the map rv in sorted order.
+// Returns an *UnsupportedTypeError if the keys are not strings.
+func sortedMapKeys(rv reflect.Value) ([]reflect.Value, error) {
+	if rv.Type().Key().Kind() != reflect.String {
+		return nil, &UnsupportedTypeError{Type: rv.Type()}
+	}
+	keys := rv.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+	return keys, nil
+}
@@ -0,0 +1,238 @@
+package bigjsonvalue
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"testing"
+)
+
+type myInt int
+
+type myString string
+
+type fromInterfaceRec struct {
+	v       interface{}
+	bigKind Kind
+	bigText string
+	bigErr  error
+	natKind Kind
+	natText string
+	natErr  error
+}
+
+var fromInterfaceList = []fromInterfaceRec{
+	{nil, Nil, "null", nil, Nil, "null", nil},
+	{(*int)(nil), Nil, "null", nil, Nil, "null", nil},
+	{true, Bool, "true", nil, Bool, "true", nil},
+	{"foo", String, `"foo"`, nil, String, `"foo"`, nil},
+	{myString("bar"), String, `"bar"`, nil, String, `"bar"`, nil},
+	{-42, BigInt, "-42", nil, Int64, "-42", nil},
+	{myInt(7), BigInt, "7", nil, Int64, "7", nil},
+	{int8(-8), BigInt, "-8", nil, Int64, "-8", nil},
+	{uint16(16), BigInt, "16", nil, Uint64, "16", nil},
+	{uint64(math.MaxUint64), BigInt, "18446744073709551615", nil, Uint64, "18446744073709551615", nil},
+	{float32(0.5), BigFloat, "0.5", nil, Float64, "0.5", nil},
+	{1e300, BigFloat, "1e+300", nil, Float64, "1e+300", nil},
+	{math.Inf(1), Nil, "null", ErrUnsupportedValue, Nil, "null", ErrUnsupportedValue},
+	{math.NaN(), Nil, "null", ErrUnsupportedValue, Nil, "null", ErrUnsupportedValue},
+	{json.Number("123456789012345678901234567890"), BigInt, "123456789012345678901234567890", nil,
+		Uint64, "18446744073709551615", strconv.ErrRange},
+	{json.Number("-1.25"), BigFloat, "-1.25", nil, Float64, "-1.25", nil},
+	{json.Number("1.2.3"), Nil, "null", ErrInvalidJSON, Nil, "null", ErrInvalidJSON},
+	{mustBigInt("-123456789012345678901234567890"), BigInt, "-123456789012345678901234567890", nil,
+		BigInt, "-123456789012345678901234567890", nil},
+	{mustBigInt("-5"), BigInt, "-5", nil, Int64, "-5", nil},
+	{mustBigInt("5"), BigInt, "5", nil, Uint64, "5", nil},
+	{(*big.Int)(nil), Nil, "null", nil, Nil, "null", nil},
+	{mustBigFloat("0.1"), BigFloat, "0.1", nil, BigFloat, "0.1", nil},
+	{mustBigFloat("0.25"), BigFloat, "0.25", nil, Float64, "0.25", nil},
+	{new(big.Float).SetInf(true), Nil, "null", ErrUnsupportedValue, Nil, "null", ErrUnsupportedValue},
+	{[]byte("hi"), String, `"aGk="`, nil, String, `"aGk="`, nil},
+	{[]int(nil), Nil, "null", nil, Nil, "null", nil},
+	{[]interface{}{1, "two", nil, []bool{true}}, Array, `[1,"two",null,[true]]`, nil,
+		Array, `[1,"two",null,[true]]`, nil},
+	{[2]float64{1, 2.5}, Array, `[1.0,2.5]`, nil, Array, `[1.0,2.5]`, nil},
+	{map[string]interface{}{"b": 2, "a": map[myString]string{"x": "y"}}, Object, `{"a":{"x":"y"},"b":2}`, nil,
+		Object, `{"a":{"x":"y"},"b":2}`, nil},
+	{map[string]int(nil), Nil, "null", nil, Nil, "null", nil},
+	{map[int]string{1: "one"}, Nil, "null", ErrUnsupportedValue, Nil, "null", ErrUnsupportedValue},
+	{[]interface{}{1, struct{}{}}, Nil, "null", ErrUnsupportedValue, Nil, "null", ErrUnsupportedValue},
+	{make(chan int), Nil, "null", ErrUnsupportedValue, Nil, "null", ErrUnsupportedValue},
+	{NewBigString("big"), String, `"big"`, nil, Nil, "null", ErrUnsupportedValue},
+	{NewNatUint64(64), Nil, "null", ErrUnsupportedValue, Uint64, "64", nil},
+}
+
+func TestBigFromInterface(t *testing.T) {
+	for idx, rec := range fromInterfaceList {
+		bjv, err := new(BigJSONValue).FromInterface(rec.v)
+		if !errors.Is(err, rec.bigErr) {
+			t.Errorf("%d: Unexpected err=%v, testRec=%+v", idx, err, rec)
+			continue
+		} else if err != nil {
+			continue
+		}
+		if bjv.Kind() != rec.bigKind {
+			t.Errorf("%d: Unexpected Kind()=%s, testRec=%+v", idx, bjv.Kind(), rec)
+		}
+		if text, _ := bjv.MarshalJSON(); string(text) != rec.bigText {
+			t.Errorf("%d: Unexpected MarshalJSON()=%s, testRec=%+v", idx, text, rec)
+		}
+	}
+}
+
+func TestNatFromInterface(t *testing.T) {
+	for idx, rec := range fromInterfaceList {
+		njv, err := new(NatJSONValue).FromInterface(rec.v)
+		if !errors.Is(err, rec.natErr) {
+			t.Errorf("%d: Unexpected err=%v, testRec=%+v", idx, err, rec)
+			continue
+		} else if err != nil {
+			continue
+		}
+		if njv.Kind() != rec.natKind {
+			t.Errorf("%d: Unexpected Kind()=%s, testRec=%+v", idx, njv.Kind(), rec)
+		}
+		if text, _ := njv.MarshalJSON(); string(text) != rec.natText {
+			t.Errorf("%d: Unexpected MarshalJSON()=%s, testRec=%+v", idx, text, rec)
+		}
+	}
+}
+
+func TestFromInterfaceUnsupportedType(t *testing.T) {
+	_, err := new(BigJSONValue).FromInterface(map[string]interface{}{"fn": func() {}})
+	var typeErr *UnsupportedTypeError
+	if !errors.As(err, &typeErr) || typeErr.Type.String() != "func()" {
+		t.Fatalf("Unexpected err=%v", err)
+	}
+	if err.Error() != "cannot convert Go value of type func()" {
+		t.Errorf("Unexpected Error()=%s", err.Error())
+	}
+}
+
+func TestFromInterfaceCycles(t *testing.T) {
+	cyclicMap := map[string]interface{}{"a": 1}
+	cyclicMap["self"] = []interface{}{cyclicMap}
+	cyclicSlice := []interface{}{nil}
+	cyclicSlice[0] = cyclicSlice
+	var cyclicPtr interface{}
+	cyclicPtr = &cyclicPtr
+	for idx, v := range []interface{}{cyclicMap, cyclicSlice, cyclicPtr} {
+		if _, err := new(BigJSONValue).FromInterface(v); err != ErrCyclicValue {
+			t.Errorf("%d: Unexpected BigJSONValue err=%v", idx, err)
+		}
+		if _, err := new(NatJSONValue).FromInterface(v); err != ErrCyclicValue {
+			t.Errorf("%d: Unexpected NatJSONValue err=%v", idx, err)
+		}
+	}
+
+	// Values shared by siblings are not cycles
+	shared := map[string]int{"x": 1}
+	bjv, err := new(BigJSONValue).FromInterface([]interface{}{shared, &shared, shared})
+	if err != nil || bjv.String() != `[{"x":1},{"x":1},{"x":1}]` {
+		t.Errorf("Unexpected String()=%s, err=%v", bjv.String(), err)
+	}
+}
+
+func TestFromInterfaceCopies(t *testing.T) {
+	bigi := big.NewInt(1)
+	bjv, _ := new(BigJSONValue).FromInterface([]*big.Int{bigi})
+	bigi.SetInt64(2)
+	if str := bjv.String(); str != "[1]" {
+		t.Errorf("Changing *big.Int changed value to %s", str)
+	}
+
+	bjvCopy, _ := new(BigJSONValue).FromInterface(map[string]*BigJSONValue{"arr": bjv})
+	bjv.Index(0).proxy = "changed"
+	if str := bjvCopy.String(); str != `{"arr":[1]}` {
+		t.Errorf("Changing BigJSONValue changed copy to %s", str)
+	}
+
+	njv, _ := new(NatJSONValue).FromInterface([]interface{}{1})
+	njvCopy, _ := new(NatJSONValue).FromInterface(njv)
+	njv.Index(0).proxy = "changed"
+	if str := njvCopy.String(); str != "[1]" {
+		t.Errorf("Changing NatJSONValue changed copy to %s", str)
+	}
+}
+
+func TestFromInterfaceLiterals(t *testing.T) {
+	bigOpts := BigDecodeOptions{Prec: 64, RetainLiterals: true}
+	bjv, _ := bigOpts.DecodeJSONValue(new(BigJSONValue), `1.50`)
+	natOpts := NatDecodeOptions{RetainLiterals: true}
+	njv, _ := natOpts.DecodeJSONValue(new(NatJSONValue), `1E3`)
+
+	bigList := []struct {
+		v        interface{}
+		expected string
+	}{
+		{[]*BigJSONValue{bjv}, `[1.50]`},
+		{[]BigJSONValue{*bjv}, `[1.50]`},
+		{map[string]interface{}{"a": []interface{}{bjv}}, `{"a":[1.50]}`},
+	}
+	for idx, rec := range bigList {
+		bjvCopy, _ := new(BigJSONValue).FromInterface(rec.v)
+		if text, err := json.Marshal(bjvCopy); err != nil || string(text) != rec.expected {
+			t.Errorf("%d: BigJSONValue marshals to %s, err=%v, expected %s", idx, text, err, rec.expected)
+		}
+	}
+
+	natList := []struct {
+		v        interface{}
+		expected string
+	}{
+		{[]*NatJSONValue{njv}, `[1E3]`},
+		{map[string]NatJSONValue{"a": *njv}, `{"a":1E3}`},
+	}
+	for idx, rec := range natList {
+		njvCopy, _ := new(NatJSONValue).FromInterface(rec.v)
+		if text, err := json.Marshal(njvCopy); err != nil || string(text) != rec.expected {
+			t.Errorf("%d: NatJSONValue marshals to %s, err=%v, expected %s", idx, text, err, rec.expected)
+		}
+	}
+}
+
+func TestConstructors(t *testing.T) {
+	bigf := big.NewFloat(2.5)
+	bigi := big.NewInt(-3)
+	bigList := []struct {
+		bjv  *BigJSONValue
+		kind Kind
+		str  string
+	}{
+		{NewBigNull(), Nil, "nil"},
+		{NewBigBool(true), Bool, "true"},
+		{NewBigString("foo"), String, "foo"},
+		{NewBigInt(bigi), BigInt, "-3"},
+		{NewBigInt(nil), Nil, "nil"},
+		{NewBigFloat(bigf), BigFloat, "2.5"},
+		{NewBigFloat(nil), Nil, "nil"},
+	}
+	bigi.SetInt64(0)
+	bigf.SetInt64(0)
+	for idx, rec := range bigList {
+		if rec.bjv.Kind() != rec.kind || rec.bjv.String() != rec.str {
+			t.Errorf("%d: Unexpected Kind()=%s, String()=%s", idx, rec.bjv.Kind(), rec.bjv.String())
+		}
+	}
+
+	natList := []struct {
+		njv  *NatJSONValue
+		kind Kind
+		str  string
+	}{
+		{NewNatNull(), Nil, "nil"},
+		{NewNatBool(false), Bool, "false"},
+		{NewNatString("foo"), String, "foo"},
+		{NewNatInt64(-64), Int64, "-64"},
+		{NewNatUint64(math.MaxUint64), Uint64, "18446744073709551615"},
+		{NewNatFloat64(0.5), Float64, "0.5"},
+	}
+	for idx, rec := range natList {
+		if rec.njv.Kind() != rec.kind || rec.njv.String() != rec.str {
+			t.Errorf("%d: Unexpected Kind()=%s, String()=%s", idx, rec.njv.Kind(), rec.njv.String())
+		}
+	}
+}
@@ -0,0 +1,487 @@
+package bigjsonvalue
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// ConvertError reports why one of the As accessors, e.g. AsInt64(),
+// could not convert a value exactly.
+type ConvertError struct {
+	From Kind  // kind of the value being converted
+	To   Kind  // kind the value was being converted to
+	Err  error // ErrKindMismatch, ErrOverflow, ErrPrecisionLoss or ErrUnsupportedValue
+}
+
+// Error implements the error interface for ConvertError.
+func (e *ConvertError) Error() string {
+	return fmt.Sprintf("cannot convert %s to %s: %s", e.From, e.To, e.Err)
+}
+
+// Unwrap returns the underlying error, so errors.Is(err, ErrOverflow)
+// and similar work on a ConvertError.
+func (e *ConvertError) Unwrap() error {
+	return e.Err
+}
+
+// newConvertError wraps err in a ConvertError, or returns nil if err is nil.
+func newConvertError(from Kind, to Kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ConvertError{From: from, To: to, Err: err}
+}
+
+// asBool converts a proxy value to a bool.
+func asBool(proxy interface{}) (bool, error) {
+	if b, ok := proxy.(bool); ok {
+		return b, nil
+	}
+	return false, ErrKindMismatch
+}
+
+// asString converts a proxy value to a string.
+func asString(proxy interface{}) (string, error) {
+	if str, ok := proxy.(string); ok {
+		return str, nil
+	}
+	return "", ErrKindMismatch
+}
+
+// asInt64 converts a proxy number value to an int64.
+// Out of range values return the nearest int64 and ErrOverflow,
+// fractional values return the value truncated toward zero and
+// ErrPrecisionLoss.
+func asInt64(proxy interface{}) (int64, error) {
+	switch proxy.(type) {
+	case int64:
+		return proxy.(int64), nil
+	case uint64:
+		if u64 := proxy.(uint64); u64 <= math.MaxInt64 {
+			return int64(u64), nil
+		}
+		return math.MaxInt64, ErrOverflow
+	}
+	bigi, err := asBigInt(proxy)
+	if bigi == nil && err == ErrOverflow && overflowSign(proxy) < 0 {
+		return math.MinInt64, err
+	} else if bigi == nil && err == ErrOverflow {
+		return math.MaxInt64, err
+	} else if bigi == nil {
+		return 0, err
+	}
+	if !bigi.IsInt64() {
+		if bigi.Sign() < 0 {
+			return math.MinInt64, ErrOverflow
+		}
+		return math.MaxInt64, ErrOverflow
+	}
+	return bigi.Int64(), err
+}
+
+// asUint64 converts a proxy number value to a uint64.
+// Out of range values return the nearest uint64 and ErrOverflow,
+// fractional values return the value truncated toward zero and
+// ErrPrecisionLoss.
+func asUint64(proxy interface{}) (uint64, error) {
+	switch proxy.(type) {
+	case uint64:
+		return proxy.(uint64), nil
+	case int64:
+		if i64 := proxy.(int64); i64 >= 0 {
+			return uint64(i64), nil
+		}
+		return 0, ErrOverflow
+	}
+	bigi, err := asBigInt(proxy)
+	if bigi == nil && err == ErrOverflow && overflowSign(proxy) > 0 {
+		return math.MaxUint64, err
+	} else if bigi == nil {
+		return 0, err
+	}
+	if bigi.Sign() < 0 {
+		return 0, ErrOverflow
+	}
+	if !bigi.IsUint64() {
+		return math.MaxUint64, ErrOverflow
+	}
+	return bigi.Uint64(), err
+}
+
+// asFloat64 converts a proxy number value to a float64.
+// Values not exactly representable return the nearest float64 and
+// ErrPrecisionLoss, or ±Inf and ErrOverflow if out of range.
+func asFloat64(proxy interface{}) (float64, error) {
+	var f64 float64
+	var exact bool
+	switch proxy.(type) {
+	case float64:
+		return proxy.(float64), nil
+	case int64, uint64, *big.Int, *big.Float:
+		bigf, err := asBigFloat(proxy)
+		if err != nil {
+			return 0, err
+		}
+		var acc big.Accuracy
+		f64, acc = bigf.Float64()
+		exact = (acc == big.Exact)
+	case bigDecimal, json.Number:
+		rat, err := numberRat(proxy)
+		if err == ErrOverflow {
+			key := newNumKey(proxy)
+			f64, _ = key.float().Float64()
+		} else if err != nil {
+			return 0, err
+		} else {
+			f64, exact = rat.Float64()
+		}
+	default:
+		return 0, ErrKindMismatch
+	}
+	if exact {
+		return f64, nil
+	} else if math.IsInf(f64, 0) {
+		return f64, ErrOverflow
+	}
+	return f64, ErrPrecisionLoss
+}
+
+// asBigInt converts a proxy number value to a new big.Int.
+// Fractional values return the value truncated toward zero and
+// ErrPrecisionLoss.  Returns nil and ErrOverflow if infinite or too
+// large to expand, as there is no nearest big.Int value.
+func asBigInt(proxy interface{}) (*big.Int, error) {
+	switch proxy.(type) {
+	case int64:
+		return new(big.Int).SetInt64(proxy.(int64)), nil
+	case uint64:
+		return new(big.Int).SetUint64(proxy.(uint64)), nil
+	case *big.Int:
+		return new(big.Int).Set(proxy.(*big.Int)), nil
+	case float64, *big.Float:
+		bigf, err := asBigFloat(proxy)
+		if err != nil {
+			return nil, err
+		}
+		if bigf.IsInf() {
+			return nil, ErrOverflow
+		}
+		bigi, acc := bigf.Int(nil)
+		if acc != big.Exact {
+			return bigi, ErrPrecisionLoss
+		}
+		return bigi, nil
+	case bigDecimal, json.Number:
+		rat, err := numberRat(proxy)
+		if err == ErrOverflow && overflowSign(proxy) == 0 {
+			return new(big.Int), ErrPrecisionLoss
+		} else if err != nil {
+			return nil, err
+		}
+		if rat.IsInt() {
+			return new(big.Int).Set(rat.Num()), nil
+		}
+		return new(big.Int).Quo(rat.Num(), rat.Denom()), ErrPrecisionLoss
+	default:
+		return nil, ErrKindMismatch
+	}
+}
+
+// asBigFloat converts a proxy number value to a new big.Float.
+// Integers get a precision large enough to hold them exactly.
+// Fractional decimals are rounded to the precision and rounding mode
+// of DefaultBigDecodeOptions, and return ErrPrecisionLoss if inexact.
+// Returns nil if there is no nearest big.Float value.
+func asBigFloat(proxy interface{}) (*big.Float, error) {
+	switch proxy.(type) {
+	case int64:
+		return new(big.Float).SetInt64(proxy.(int64)), nil
+	case uint64:
+		return new(big.Float).SetUint64(proxy.(uint64)), nil
+	case float64:
+		f64 := proxy.(float64)
+		if math.IsNaN(f64) {
+			return nil, ErrUnsupportedValue
+		}
+		return new(big.Float).SetFloat64(f64), nil
+	case *big.Int:
+		return new(big.Float).SetInt(proxy.(*big.Int)), nil
+	case *big.Float:
+		return new(big.Float).Copy(proxy.(*big.Float)), nil
+	case bigDecimal, json.Number:
+		rat, err := numberRat(proxy)
+		if err != nil {
+			return nil, err
+		}
+		if rat.IsInt() {
+			return new(big.Float).SetInt(rat.Num()), nil
+		}
+		bigf := new(big.Float).SetMode(DefaultBigDecodeOptions.Mode)
+		bigf.SetPrec(DefaultBigDecodeOptions.Prec)
+		if bigf.Prec() == 0 {
+			bigf.SetPrec(64)
+		}
+		if bigf.SetRat(rat); bigf.Acc() != big.Exact {
+			return bigf, ErrPrecisionLoss
+		}
+		return bigf, nil
+	default:
+		return nil, ErrKindMismatch
+	}
+}
+
+// overflowSign returns the sign of a number proxy value that asBigInt()
+// returns ErrOverflow for, or 0 for a decimal too small for numberRat().
+func overflowSign(proxy interface{}) int {
+	key := newNumKey(proxy)
+	if key.inf != 0 {
+		return key.inf
+	} else if bigf := key.float(); bigf.IsInf() || bigf.MantExp(nil) > 0 {
+		return bigf.Sign()
+	}
+	return 0
+}
+
+// maxRatScale is the largest power of ten numberRat() will expand,
+// to keep numbers like 1e999999999 from exhausting memory.
+const maxRatScale = 100000
+
+// numberRat returns the exact value of a proxy decimal or json.Number
+// value as a new big.Rat.  Returns ErrOverflow if the value has a power
+// of ten larger than maxRatScale.
+func numberRat(proxy interface{}) (*big.Rat, error) {
+	switch proxy.(type) {
+	case bigDecimal:
+		dec := proxy.(bigDecimal)
+		if dec.scale > maxRatScale || dec.scale < -maxRatScale {
+			return nil, ErrOverflow
+		}
+		return dec.rat(), nil
+	case json.Number:
+		dec, err := parseDecimal([]byte(proxy.(json.Number)))
+		if err == strconv.ErrRange {
+			return nil, ErrOverflow
+		} else if err != nil {
+			return nil, err
+		}
+		return numberRat(dec)
+	default:
+		return nil, ErrKindMismatch
+	}
+}
+
+// Loss reports how exactly ToNat() converted the numbers in a value.
+type Loss uint
+
+// Loss enumeration constants, in order of increasing loss
+const (
+	// Lossless means every number was converted exactly
+	Lossless Loss = iota
+
+	// Rounded means some number was rounded to the nearest float64
+	Rounded
+
+	// Overflowed means some number was out of range, and was handled
+	// according to the OverflowPolicy
+	Overflowed
+)
+
+var lossNames = [...]string{
+	"Lossless",
+	"Rounded",
+	"Overflowed",
+}
+
+// String implements fmt.Stringer interface for Loss
+func (l Loss) String() string {
+	return lossNames[l]
+}
+
+// natConverter converts BigJSONValue proxy values to NatJSONValue proxy
+// values, keeping track of the worst loss and the first overflow error.
+type natConverter struct {
+	policy OverflowPolicy
+	loss   Loss
+	err    error
+}
+
+// convert returns proxy converted to a NatJSONValue proxy value.
+func (conv *natConverter) convert(proxy interface{}) interface{} {
+	switch proxy.(type) {
+	case *big.Int:
+		bigi := proxy.(*big.Int)
+		if bigi.Sign() < 0 && bigi.IsInt64() {
+			return bigi.Int64()
+		} else if bigi.Sign() >= 0 && bigi.IsUint64() {
+			return bigi.Uint64()
+		} else if bigi.Sign() < 0 {
+			return conv.overflow(proxy, Int64, int64(math.MinInt64))
+		}
+		return conv.overflow(proxy, Uint64, uint64(math.MaxUint64))
+	case *big.Float:
+		f64, acc := proxy.(*big.Float).Float64()
+		return conv.float(proxy, f64, acc == big.Exact)
+	case bigDecimal:
+		rat, err := numberRat(proxy)
+		if err != nil {
+			f64, _ := approxNumber(numberText(proxy)).Float64()
+			return conv.float(proxy, f64, false)
+		}
+		f64, exact := rat.Float64()
+		return conv.float(proxy, f64, exact)
+	case *bigObject:
+		obj := proxy.(*bigObject)
+		natObj := &natObject{
+			keys:    append([]string(nil), obj.keys...),
+			members: make(map[string]*NatJSONValue, len(obj.members)),
+		}
+		for key, member := range obj.members {
+			natMember := conv.value(member)
+			natObj.members[key] = &natMember
+		}
+		return natObj
+	case []BigJSONValue:
+		arr := proxy.([]BigJSONValue)
+		natArr := make([]NatJSONValue, len(arr))
+		for idx := range arr {
+			natArr[idx] = conv.value(&arr[idx])
+		}
+		return natArr
+	default:
+		return proxy
+	}
+}
+
+// value returns bjv converted to a NatJSONValue, keeping its literal.
+func (conv *natConverter) value(bjv *BigJSONValue) NatJSONValue {
+	return NatJSONValue{proxy: conv.convert(bjv.proxy), literal: bjv.literal}
+}
+
+// float returns the float64 f64 proxy was converted to, handling it as
+// out of range if infinite but proxy is not.
+func (conv *natConverter) float(proxy interface{}, f64 float64, exact bool) interface{} {
+	if bigf, ok := proxy.(*big.Float); ok && bigf.IsInf() {
+		return f64
+	} else if math.IsInf(f64, 0) {
+		if conv.policy == OverflowSaturate {
+			return conv.overflow(proxy, Float64, math.Copysign(math.MaxFloat64, f64))
+		}
+		return conv.overflow(proxy, Float64, f64)
+	} else if !exact && conv.loss < Rounded {
+		conv.loss = Rounded
+	}
+	return f64
+}
+
+// overflow returns the out of range number proxy handled according to
+// the policy, with nearest being its nearest value of kind to.
+func (conv *natConverter) overflow(proxy interface{}, to Kind, nearest interface{}) interface{} {
+	switch conv.policy {
+	case OverflowPromote:
+		if dec, ok := proxy.(bigDecimal); ok {
+			bigf, err := asBigFloat(dec)
+			if bigf == nil {
+				bigf = approxNumber(numberText(dec))
+			}
+			if err != nil && conv.loss < Rounded {
+				conv.loss = Rounded
+			}
+			return bigf
+		}
+		return copyBigNumber(proxy)
+	case OverflowKeepLiteral:
+		if dec, ok := proxy.(bigDecimal); ok {
+			return json.Number(dec.String())
+		}
+		return json.Number((&BigJSONValue{proxy: proxy}).String())
+	case OverflowError:
+		if conv.err == nil {
+			conv.err = newConvertError(proxyKind(proxy), to, ErrOverflow)
+		}
+	}
+	conv.loss = Overflowed
+	return nearest
+}
+
+// bigConverter converts NatJSONValue proxy values to BigJSONValue proxy
+// values, keeping track of the first value not converted exactly.
+type bigConverter struct {
+	err error
+}
+
+// convert returns proxy converted to a BigJSONValue proxy value.  NaN,
+// which BigJSONValue cannot hold, is converted to nil.
+func (conv *bigConverter) convert(proxy interface{}) interface{} {
+	switch proxy.(type) {
+	case int64:
+		return big.NewInt(proxy.(int64))
+	case uint64:
+		return new(big.Int).SetUint64(proxy.(uint64))
+	case float64:
+		if math.IsNaN(proxy.(float64)) {
+			conv.inexact(Float64, BigFloat, ErrUnsupportedValue)
+			return nil
+		}
+		return new(big.Float).SetFloat64(proxy.(float64))
+	case json.Number:
+		var bjv BigJSONValue
+		text := proxy.(json.Number).String()
+		opts := BigDecodeOptions{Decimal: true}
+		if err := bjv.decodeJSONValue([]byte(text), &opts); err != nil {
+			conv.inexact(Number, BigFloat, ErrPrecisionLoss)
+			return approxNumber(text)
+		}
+		return bjv.proxy
+	case *natObject:
+		obj := proxy.(*natObject)
+		bigObj := &bigObject{
+			keys:    append([]string(nil), obj.keys...),
+			members: make(map[string]*BigJSONValue, len(obj.members)),
+		}
+		for _, key := range obj.keys {
+			bigMember := conv.value(obj.members[key])
+			bigObj.members[key] = &bigMember
+		}
+		return bigObj
+	case []NatJSONValue:
+		arr := proxy.([]NatJSONValue)
+		bigArr := make([]BigJSONValue, len(arr))
+		for idx := range arr {
+			bigArr[idx] = conv.value(&arr[idx])
+		}
+		return bigArr
+	case *big.Int, *big.Float:
+		return copyBigNumber(proxy)
+	default:
+		return proxy
+	}
+}
+
+// copyBigNumber returns a copy of a *big.Int or *big.Float proxy value,
+// so that values converted between types never share one.
+func copyBigNumber(proxy interface{}) interface{} {
+	switch proxy.(type) {
+	case *big.Int:
+		return new(big.Int).Set(proxy.(*big.Int))
+	case *big.Float:
+		return new(big.Float).Copy(proxy.(*big.Float))
+	default:
+		return proxy
+	}
+}
+
+// value returns njv converted to a BigJSONValue, keeping its literal.
+func (conv *bigConverter) value(njv *NatJSONValue) BigJSONValue {
+	return BigJSONValue{proxy: conv.convert(njv.proxy), literal: njv.literal}
+}
+
+// inexact records err for a value of kind from not converted exactly to
+// kind to, if it is the first.
+func (conv *bigConverter) inexact(from Kind, to Kind, err error) {
+	if conv.err == nil {
+		conv.err = newConvertError(from, to, err)
+	}
+}
@@ -0,0 +1,318 @@
+package bigjsonvalue
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+)
+
+type convertRec struct {
+	proxy   interface{}
+	i64     int64
+	i64Err  error
+	u64     uint64
+	u64Err  error
+	f64     float64
+	f64Err  error
+	bigi    string
+	bigiErr error
+	bigf    string
+	bigfErr error
+}
+
+func mustBigInt(str string) *big.Int {
+	bigi, _ := new(big.Int).SetString(str, 10)
+	return bigi
+}
+
+func mustBigFloat(str string) *big.Float {
+	bigf, _, _ := new(big.Float).SetPrec(128).Parse(str, 10)
+	return bigf
+}
+
+func mustDecimal(str string) bigDecimal {
+	dec, _ := parseDecimal([]byte(str))
+	return dec
+}
+
+var convertList = []convertRec{
+	{int64(-42),
+		-42, nil, 0, ErrOverflow, -42, nil, "-42", nil, "-42", nil},
+	{int64(math.MinInt64),
+		math.MinInt64, nil, 0, ErrOverflow, -9223372036854775808, nil, "-9223372036854775808", nil, "-9.223372036854775808e+18", nil},
+	{int64(9007199254740993),
+		9007199254740993, nil, 9007199254740993, nil, 9007199254740992, ErrPrecisionLoss, "9007199254740993", nil, "9.007199254740993e+15", nil},
+	{uint64(math.MaxUint64),
+		math.MaxInt64, ErrOverflow, math.MaxUint64, nil, 18446744073709551616, ErrPrecisionLoss, "18446744073709551615", nil, "1.8446744073709551615e+19", nil},
+	{float64(1.0),
+		1, nil, 1, nil, 1, nil, "1", nil, "1", nil},
+	{float64(-2.5),
+		-2, ErrPrecisionLoss, 0, ErrOverflow, -2.5, nil, "-2", ErrPrecisionLoss, "-2.5", nil},
+	{float64(1e30),
+		math.MaxInt64, ErrOverflow, math.MaxUint64, ErrOverflow, 1e30, nil, "1000000000000000019884624838656", nil, "1e+30", nil},
+	{math.Inf(-1),
+		math.MinInt64, ErrOverflow, 0, ErrOverflow, math.Inf(-1), nil, "<nil>", ErrOverflow, "-Inf", nil},
+	{math.Inf(1),
+		math.MaxInt64, ErrOverflow, math.MaxUint64, ErrOverflow, math.Inf(1), nil, "<nil>", ErrOverflow, "+Inf", nil},
+	{math.NaN(),
+		0, ErrUnsupportedValue, 0, ErrUnsupportedValue, math.NaN(), nil, "<nil>", ErrUnsupportedValue, "<nil>", ErrUnsupportedValue},
+	{mustBigInt("-1"),
+		-1, nil, 0, ErrOverflow, -1, nil, "-1", nil, "-1", nil},
+	{mustBigInt("1180591620717411303424"), // 2**70
+		math.MaxInt64, ErrOverflow, math.MaxUint64, ErrOverflow, 1180591620717411303424, nil, "1180591620717411303424", nil, "1.180591620717411303424e+21", nil},
+	{mustBigInt("1180591620717411303425"), // 2**70 + 1
+		math.MaxInt64, ErrOverflow, math.MaxUint64, ErrOverflow, 1180591620717411303424, ErrPrecisionLoss, "1180591620717411303425", nil, "1.180591620717411303425e+21", nil},
+	{mustBigFloat("123"),
+		123, nil, 123, nil, 123, nil, "123", nil, "123", nil},
+	{mustBigFloat("0.1"),
+		0, ErrPrecisionLoss, 0, ErrPrecisionLoss, 0.1, ErrPrecisionLoss, "0", ErrPrecisionLoss, "0.1", nil},
+	{mustBigFloat("-1e400"),
+		math.MinInt64, ErrOverflow, 0, ErrOverflow, math.Inf(-1), ErrOverflow, bigFloatIntString("-1e400"), nil, "-1e+400", nil},
+	{mustDecimal("19.99"),
+		19, ErrPrecisionLoss, 19, ErrPrecisionLoss, 19.99, ErrPrecisionLoss, "19", ErrPrecisionLoss, "19.99", ErrPrecisionLoss},
+	{mustDecimal("0.5"),
+		0, ErrPrecisionLoss, 0, ErrPrecisionLoss, 0.5, nil, "0", ErrPrecisionLoss, "0.5", nil},
+	{mustDecimal("15e2"),
+		1500, nil, 1500, nil, 1500, nil, "1500", nil, "1500", nil},
+	{mustDecimal("1e999999999"),
+		math.MaxInt64, ErrOverflow, math.MaxUint64, ErrOverflow, math.Inf(1), ErrOverflow, "<nil>", ErrOverflow, "<nil>", ErrOverflow},
+	{mustDecimal("-1e999999999"),
+		math.MinInt64, ErrOverflow, 0, ErrOverflow, math.Inf(-1), ErrOverflow, "<nil>", ErrOverflow, "<nil>", ErrOverflow},
+	{mustDecimal("-1e-999999999"),
+		0, ErrPrecisionLoss, 0, ErrPrecisionLoss, 0, ErrPrecisionLoss, "0", ErrPrecisionLoss, "<nil>", ErrOverflow},
+	{json.Number("-9223372036854775809"),
+		math.MinInt64, ErrOverflow, 0, ErrOverflow, -9223372036854775808, ErrPrecisionLoss, "-9223372036854775809", nil, "-9.223372036854775809e+18", nil},
+	{json.Number("1.7976931348623159e+308"),
+		math.MaxInt64, ErrOverflow, math.MaxUint64, ErrOverflow, math.Inf(1), ErrOverflow, "17976931348623159" + zeros(292), nil, "1.7976931348623159e+308", nil},
+	{"123",
+		0, ErrKindMismatch, 0, ErrKindMismatch, 0, ErrKindMismatch, "<nil>", ErrKindMismatch, "<nil>", ErrKindMismatch},
+	{true,
+		0, ErrKindMismatch, 0, ErrKindMismatch, 0, ErrKindMismatch, "<nil>", ErrKindMismatch, "<nil>", ErrKindMismatch},
+	{nil,
+		0, ErrKindMismatch, 0, ErrKindMismatch, 0, ErrKindMismatch, "<nil>", ErrKindMismatch, "<nil>", ErrKindMismatch},
+}
+
+// bigFloatIntString returns the exact integer value of a big.Float
+// with 128-bit precision.
+func bigFloatIntString(str string) string {
+	bigf := mustBigFloat(str)
+	bigi, _ := bigf.Int(nil)
+	return bigi.String()
+}
+
+func zeros(n int) string {
+	buf := make([]byte, n)
+	for idx := range buf {
+		buf[idx] = '0'
+	}
+	return string(buf)
+}
+
+func TestAsNumbers(t *testing.T) {
+	for idx, rec := range convertList {
+		bjv := BigJSONValue{proxy: rec.proxy}
+		i64, err := bjv.AsInt64()
+		if i64 != rec.i64 || !errors.Is(err, rec.i64Err) || (err == nil) != (rec.i64Err == nil) {
+			t.Errorf("%d: AsInt64()=%d, err=%v, expected %d, %v", idx, i64, err, rec.i64, rec.i64Err)
+		}
+		u64, err := bjv.AsUint64()
+		if u64 != rec.u64 || !errors.Is(err, rec.u64Err) || (err == nil) != (rec.u64Err == nil) {
+			t.Errorf("%d: AsUint64()=%d, err=%v, expected %d, %v", idx, u64, err, rec.u64, rec.u64Err)
+		}
+		f64, err := bjv.AsFloat64()
+		if (f64 != rec.f64 && !(math.IsNaN(f64) && math.IsNaN(rec.f64))) ||
+			!errors.Is(err, rec.f64Err) || (err == nil) != (rec.f64Err == nil) {
+			t.Errorf("%d: AsFloat64()=%g, err=%v, expected %g, %v", idx, f64, err, rec.f64, rec.f64Err)
+		}
+		bigi, err := bjv.AsBigInt()
+		if bigi.String() != rec.bigi || !errors.Is(err, rec.bigiErr) || (err == nil) != (rec.bigiErr == nil) {
+			t.Errorf("%d: AsBigInt()=%s, err=%v, expected %s, %v", idx, bigi, err, rec.bigi, rec.bigiErr)
+		}
+		bigf, err := bjv.AsBigFloat()
+		bigfStr := "<nil>"
+		if bigf != nil {
+			bigfStr = bigf.Text('g', -1)
+		}
+		if bigfStr != rec.bigf || !errors.Is(err, rec.bigfErr) || (err == nil) != (rec.bigfErr == nil) {
+			t.Errorf("%d: AsBigFloat()=%s, err=%v, expected %s, %v", idx, bigfStr, err, rec.bigf, rec.bigfErr)
+		}
+	}
+}
+
+func TestAsCopies(t *testing.T) {
+	bjv, _ := new(BigJSONValue).DecodeJSONValue(`123456789012345678901234567890`)
+	bigi, _ := bjv.AsBigInt()
+	bigi.SetInt64(0)
+	if bjv.String() != "123456789012345678901234567890" {
+		t.Errorf("Changing AsBigInt() result changed value to %s", bjv)
+	}
+
+	bjv, _ = new(BigJSONValue).DecodeJSONValue(`1.5`)
+	bigf, _ := bjv.AsBigFloat()
+	bigf.SetInt64(0)
+	if bjv.String() != "1.5" {
+		t.Errorf("Changing AsBigFloat() result changed value to %s", bjv)
+	}
+}
+
+func TestAsErrors(t *testing.T) {
+	njv, _ := new(NatJSONValue).DecodeJSONValue(`[ 1 ]`)
+	_, err := njv.AsInt64()
+	var convErr *ConvertError
+	if !errors.As(err, &convErr) || convErr.From != Array || convErr.To != Int64 || convErr.Err != ErrKindMismatch {
+		t.Errorf("Unexpected AsInt64() err=%#v", err)
+	}
+	if str := err.Error(); str != "cannot convert Array to Int64: kind mismatch" {
+		t.Errorf("Unexpected Error()=%s", str)
+	}
+
+	hjv, _ := new(HybJSONValue).DecodeJSONValue(`"str"`)
+	if str, err := hjv.AsString(); str != "str" || err != nil {
+		t.Errorf("Unexpected AsString()=%s, err=%v", str, err)
+	}
+	if _, err := hjv.AsBool(); !errors.Is(err, ErrKindMismatch) {
+		t.Errorf("Unexpected AsBool() err=%v", err)
+	}
+	hjv, _ = new(HybJSONValue).DecodeJSONValue(`true`)
+	if b, err := hjv.AsBool(); !b || err != nil {
+		t.Errorf("Unexpected AsBool()=%t, err=%v", b, err)
+	}
+	if _, err := hjv.AsString(); !errors.Is(err, ErrKindMismatch) {
+		t.Errorf("Unexpected AsString() err=%v", err)
+	}
+}
+
+func TestAsAcrossTypes(t *testing.T) {
+	jsonStr := `18446744073709551615`
+	bjv, _ := new(BigJSONValue).DecodeJSONValue(jsonStr)
+	njv, _ := new(NatJSONValue).DecodeJSONValue(jsonStr)
+	hjv, _ := new(HybJSONValue).DecodeJSONValue(jsonStr)
+	for idx, conv := range []interface {
+		AsUint64() (uint64, error)
+		AsInt64() (int64, error)
+	}{bjv, njv, hjv} {
+		if u64, err := conv.AsUint64(); u64 != math.MaxUint64 || err != nil {
+			t.Errorf("%d: AsUint64()=%d, err=%v", idx, u64, err)
+		}
+		if i64, err := conv.AsInt64(); i64 != math.MaxInt64 || !errors.Is(err, ErrOverflow) {
+			t.Errorf("%d: AsInt64()=%d, err=%v", idx, i64, err)
+		}
+	}
+}
+
+type toNatRec struct {
+	jsonStr string
+	decimal bool
+	policy  OverflowPolicy
+	kind    Kind
+	natStr  string
+	loss    Loss
+	err     error
+}
+
+var toNatList = []toNatRec{
+	{`"foo"`, false, OverflowError, String, `"foo"`, Lossless, nil},
+	{`-42`, false, OverflowError, Int64, `-42`, Lossless, nil},
+	{`18446744073709551615`, false, OverflowError, Uint64, `18446744073709551615`, Lossless, nil},
+	{`18446744073709551616`, false, OverflowError, Uint64, `18446744073709551615`, Overflowed, ErrOverflow},
+	{`-9223372036854775809`, false, OverflowSaturate, Int64, `-9223372036854775808`, Overflowed, nil},
+	{`18446744073709551616`, false, OverflowPromote, BigInt, `18446744073709551616`, Lossless, nil},
+	{`18446744073709551616`, false, OverflowKeepLiteral, Number, `18446744073709551616`, Lossless, nil},
+	{`1.5`, false, OverflowError, Float64, `1.5`, Lossless, nil},
+	{`0.1`, false, OverflowError, Float64, `0.1`, Rounded, nil},
+	{`0.1`, true, OverflowError, Float64, `0.1`, Rounded, nil},
+	{`19.99e2`, true, OverflowError, Float64, `1999.0`, Lossless, nil},
+	{`1e400`, false, OverflowError, Float64, ``, Overflowed, ErrOverflow},
+	{`-1e400`, true, OverflowSaturate, Float64, `-1.7976931348623157e+308`, Overflowed, nil},
+	{`1e400`, false, OverflowPromote, BigFloat, `1e+400`, Lossless, nil},
+	{`1e400`, true, OverflowPromote, BigFloat, `1e+400`, Lossless, nil},
+	{`0.1e400`, true, OverflowPromote, BigFloat, `1e+399`, Lossless, nil},
+	{`1.1e-400`, true, OverflowPromote, Float64, `0.0`, Rounded, nil},
+	{`1.50e400`, true, OverflowKeepLiteral, Number, `150e398`, Lossless, nil},
+	{`1e-400`, true, OverflowError, Float64, `0.0`, Rounded, nil},
+	{`1e-999999`, true, OverflowError, Float64, `0.0`, Rounded, nil},
+	{`1e999999`, true, OverflowSaturate, Float64, `1.7976931348623157e+308`, Overflowed, nil},
+	{`{"a": 0.1, "b": 1e400, "c": 1}`, true, OverflowError, Object, ``, Overflowed, ErrOverflow},
+	{`{"a": 0.1, "b": 1e400, "c": 1}`, true, OverflowKeepLiteral, Object, `{"a":0.1,"b":1e400,"c":1}`, Rounded, nil},
+}
+
+func TestToNat(t *testing.T) {
+	for idx, rec := range toNatList {
+		bjv, _ := BigDecodeOptions{Decimal: rec.decimal}.DecodeJSONValue(new(BigJSONValue), rec.jsonStr)
+		njv, loss, err := bjv.ToNat(rec.policy)
+		if !errors.Is(err, rec.err) || (err == nil) != (rec.err == nil) {
+			t.Errorf("%d: ToNat() err=%v, testRec=%+v", idx, err, rec)
+		}
+		// natStr is empty if the value holds an infinite number,
+		// which cannot be encoded
+		text, marshalErr := njv.MarshalJSON()
+		if rec.natStr == "" && !errors.Is(marshalErr, ErrUnsupportedValue) {
+			t.Errorf("%d: MarshalJSON() err=%v, expected %v", idx, marshalErr, ErrUnsupportedValue)
+		} else if rec.natStr != "" && string(text) != rec.natStr {
+			t.Errorf("%d: ToNat()=%s, testRec=%+v", idx, text, rec)
+		}
+		if njv.Kind() != rec.kind || loss != rec.loss {
+			t.Errorf("%d: ToNat() Kind()=%s, loss=%s, testRec=%+v", idx, njv.Kind(), loss, rec)
+		}
+	}
+}
+
+func TestConvertCopies(t *testing.T) {
+	text := `[123456789012345678901234567890, 1e400]`
+	bjv, _ := new(BigJSONValue).DecodeJSONValue(text)
+	njv, _, _ := bjv.ToNat(OverflowPromote)
+	njv.Index(0).proxy.(*big.Int).SetInt64(0)
+	njv.Index(1).proxy.(*big.Float).SetInt64(0)
+	if bjv.String() != "[123456789012345678901234567890,1e+400]" {
+		t.Errorf("Changing ToNat() result changed value to %s", bjv)
+	}
+
+	njv, _ = NatDecodeOptions{Overflow: OverflowPromote}.DecodeJSONValue(new(NatJSONValue), text)
+	bjv, _ = njv.ToBig()
+	bjv.Index(0).proxy.(*big.Int).SetInt64(0)
+	bjv.Index(1).proxy.(*big.Float).SetInt64(0)
+	if njv.String() != "[123456789012345678901234567890,1e+400]" {
+		t.Errorf("Changing ToBig() result changed value to %s", njv)
+	}
+}
+
+func TestToBig(t *testing.T) {
+	njv, _ := NatDecodeOptions{Overflow: OverflowKeepLiteral}.DecodeJSONValue(new(NatJSONValue),
+		`[null, true, "foo", -1, 18446744073709551615, 0.1, 1e400, 123456789012345678901234567890]`)
+	bjv, err := njv.ToBig()
+	if err != nil {
+		t.Errorf("ToBig() err=%v", err)
+	}
+	expectedKinds := []Kind{Nil, Bool, String, BigInt, BigInt, BigFloat, Decimal, BigInt}
+	for idx, kind := range expectedKinds {
+		if bjv.Index(idx).Kind() != kind {
+			t.Errorf("%d: ToBig() Kind()=%s, expected %s", idx, bjv.Index(idx).Kind(), kind)
+		}
+	}
+	if !equalProxies(njv.proxy, bjv.proxy) {
+		t.Errorf("ToBig()=%s is not equal to %s", bjv, njv)
+	}
+	back, loss, err := bjv.ToNat(OverflowKeepLiteral)
+	if !back.Equal(njv) || loss != Lossless || err != nil {
+		t.Errorf("ToBig().ToNat()=%s, loss=%s, err=%v, expected %s", back, loss, err, njv)
+	}
+
+	obj, _ := new(NatJSONValue).FromInterface(map[string]interface{}{"a": 1.5})
+	if bjv, err := obj.ToBig(); !bjv.IsObject() || bjv.String() != `{"a":1.5}` || err != nil {
+		t.Errorf("ToBig()=%s, err=%v, expected object", bjv, err)
+	}
+	if bjv, err := NewNatFloat64(math.NaN()).ToBig(); !bjv.IsNil() || !errors.Is(err, ErrUnsupportedValue) {
+		t.Errorf("ToBig()=%s, err=%v, expected nil and %v", bjv, err, ErrUnsupportedValue)
+	}
+	nan := NatJSONValue{proxy: []NatJSONValue{*NewNatInt64(1), *NewNatFloat64(math.NaN())}}
+	var convErr *ConvertError
+	if bjv, err := nan.ToBig(); bjv.String() != "[1,null]" || !errors.As(err, &convErr) || convErr.From != Float64 {
+		t.Errorf("ToBig()=%s, err=%v, expected [1,null] and a *ConvertError from Float64", bjv, err)
+	}
+	huge := NatJSONValue{proxy: json.Number("1e9999999999")}
+	if bjv, err := huge.ToBig(); !bjv.IsBigFloat() || !errors.Is(err, ErrPrecisionLoss) {
+		t.Errorf("ToBig()=%s, err=%v, expected BigFloat and %v", bjv, err, ErrPrecisionLoss)
+	}
+}
@@ -0,0 +1,80 @@
+package bigjsonvalue
+
+import (
+	"bytes"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// bigDecimal holds an exact decimal number decoded by BigJSONValue,
+// whose value is unscaled * 10**(-scale).
+// For example 19.99 has unscaled 1999 and scale 2,
+// and 15e2 has unscaled 15 and scale -2.
+type bigDecimal struct {
+	unscaled *big.Int
+	scale    int
+}
+
+// parseDecimal parses JSON number text as an exact decimal.
+// Returns strconv.ErrRange if the scale does not fit in an int32.
+func parseDecimal(text []byte) (bigDecimal, error) {
+	dec := bigDecimal{unscaled: new(big.Int)}
+	mantissa, exp := text, int64(0)
+	if idx := bytes.IndexAny(text, "eE"); idx >= 0 {
+		var err error
+		mantissa = text[:idx]
+		if exp, err = strconv.ParseInt(string(text[idx+1:]), 10, 32); err != nil {
+			return dec, strconv.ErrRange
+		}
+	}
+	var buf [64]byte
+	digits, frac := buf[:0], 0
+	for idx, c := range mantissa {
+		if c == '.' {
+			frac = len(mantissa) - idx - 1
+		} else {
+			digits = append(digits, c)
+		}
+	}
+	scale := int64(frac) - exp
+	if scale < math.MinInt32 || scale > math.MaxInt32 {
+		return dec, strconv.ErrRange
+	}
+	if _, ok := dec.unscaled.SetString(string(digits), 10); !ok {
+		return dec, ErrInvalidJSON
+	}
+	dec.scale = int(scale)
+	return dec, nil
+}
+
+// rat returns the exact value of the decimal as a big.Rat.
+func (dec *bigDecimal) rat() *big.Rat {
+	var pow big.Int
+	if dec.scale < 0 {
+		pow.Exp(big.NewInt(10), big.NewInt(int64(-dec.scale)), nil)
+		pow.Mul(&pow, dec.unscaled)
+		return new(big.Rat).SetInt(&pow)
+	}
+	pow.Exp(big.NewInt(10), big.NewInt(int64(dec.scale)), nil)
+	return new(big.Rat).SetFrac(dec.unscaled, &pow)
+}
+
+// String formats the decimal keeping its scale, as plain decimal text
+// if scale is positive, otherwise in exponent form, e.g. "19.99" or "15e2".
+func (dec *bigDecimal) String() string {
+	digits := dec.unscaled.String()
+	if dec.scale <= 0 {
+		return digits + "e" + strconv.Itoa(-dec.scale)
+	}
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	if len(digits) <= dec.scale {
+		digits = strings.Repeat("0", dec.scale-len(digits)+1) + digits
+	}
+	point := len(digits) - dec.scale
+	return sign + digits[:point] + "." + digits[point:]
+}
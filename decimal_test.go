@@ -0,0 +1,101 @@
+package bigjsonvalue
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strconv"
+	"testing"
+)
+
+func TestBigDecodeDecimal(t *testing.T) {
+	testList := []struct {
+		jsonStr  string
+		unscaled string
+		scale    int
+		rat      string
+		str      string
+		err      error
+	}{
+		{`19.99`, "1999", 2, "1999/100", "19.99", nil},
+		{`-0.05`, "-5", 2, "-1/20", "-0.05", nil},
+		{`0.00`, "0", 2, "0/1", "0.00", nil},
+		{`1.5e3`, "15", -2, "1500/1", "15e2", nil},
+		{`15E-4`, "15", 4, "3/2000", "0.0015", nil},
+		{`-7e+0`, "-7", 0, "-7/1", "-7e0", nil},
+		{`123456789012345678901234567890.123456789`, "123456789012345678901234567890123456789", 9,
+			"123456789012345678901234567890123456789/1000000000", "123456789012345678901234567890.123456789", nil},
+		{`1e2147483648`, "", 0, "", "", strconv.ErrRange},
+		{`1.5e-2147483647`, "", 0, "", "", strconv.ErrRange},
+	}
+	opts := BigDecodeOptions{Decimal: true}
+	for idx, rec := range testList {
+		bjv, err := opts.DecodeJSONValue(new(BigJSONValue), rec.jsonStr)
+		if !errors.Is(err, rec.err) {
+			t.Errorf("%d: DecodeJSONValue(%s) err=%v, expected %v", idx, rec.jsonStr, err, rec.err)
+		}
+		if err != nil {
+			continue
+		}
+		if !bjv.IsDecimal() || bjv.Kind() != Decimal {
+			t.Errorf("%d: DecodeJSONValue(%s) Kind()=%s", idx, rec.jsonStr, bjv.Kind())
+			continue
+		}
+		unscaled := bjv.Unscaled()
+		rat, err := bjv.Rat()
+		if err != nil || unscaled.String() != rec.unscaled || bjv.Scale() != rec.scale || rat.String() != rec.rat {
+			t.Errorf("%d: DecodeJSONValue(%s) Unscaled()=%s, Scale()=%d, Rat()=%s",
+				idx, rec.jsonStr, unscaled.String(), bjv.Scale(), rat.String())
+		}
+		if bjv.String() != rec.str {
+			t.Errorf("%d: DecodeJSONValue(%s) String()=%s, expected %s", idx, rec.jsonStr, bjv.String(), rec.str)
+		}
+		text, err := bjv.MarshalJSON()
+		if err != nil || string(text) != rec.str {
+			t.Errorf("%d: MarshalJSON(%s)=%s, err=%v", idx, rec.jsonStr, text, err)
+		}
+		again, err := opts.DecodeJSONValue(new(BigJSONValue), string(text))
+		if err != nil || again.Scale() != bjv.Scale() || again.String() != bjv.String() {
+			t.Errorf("%d: Round-trip of %s gives %s, err=%v", idx, text, again, err)
+		}
+	}
+
+	for _, text := range []string{`1e200000000`, `1e-200000000`, `1e100001`} {
+		bjv, err := opts.DecodeJSONValue(new(BigJSONValue), text)
+		if err != nil {
+			t.Errorf("DecodeJSONValue(%s) err=%s", text, err)
+		} else if rat, err := bjv.Rat(); rat != nil || err != ErrOverflow {
+			t.Errorf("Rat() of %s=%v, err=%v, expected ErrOverflow", text, rat, err)
+		}
+	}
+	if bjv, _ := opts.DecodeJSONValue(new(BigJSONValue), `1e100000`); bjv != nil {
+		if rat, err := bjv.Rat(); err != nil || rat.Num().BitLen() != 332193 {
+			t.Errorf("Rat() of 1e100000 err=%v", err)
+		}
+	}
+
+	bigi, err := opts.DecodeJSONValue(new(BigJSONValue), `1999`)
+	if err != nil || bigi.Kind() != BigInt {
+		t.Errorf("Decimal option changed kind of integer to %s, err=%v", bigi.Kind(), err)
+	}
+}
+
+func TestBigDecimalSum(t *testing.T) {
+	saved := DefaultBigDecodeOptions
+	defer func() { DefaultBigDecodeOptions = saved }()
+	DefaultBigDecodeOptions.Decimal = true
+
+	var bigRec bigWalChangeRec
+	jsonStr := `{ "columnvalues": [ 19.99, 0.01, 0.1, 0.2, 1e-2, 123456789012345678.99 ] }`
+	if err := json.Unmarshal([]byte(jsonStr), &bigRec); err != nil {
+		t.Fatalf("json.Unmarshal err=%s", err)
+	}
+	var sum big.Rat
+	for _, bjv := range bigRec.ColumnValues {
+		rat, _ := bjv.Rat()
+		sum.Add(&sum, rat)
+	}
+	if str := sum.FloatString(2); str != "123456789012345699.30" {
+		t.Errorf("Unexpected sum=%s", str)
+	}
+}
@@ -0,0 +1,91 @@
+package bigjsonvalue
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSnippetLen is the most bytes of offending text a DecodeError keeps.
+const maxSnippetLen = 32
+
+// DecodeError reports where and why a JSON value could not be decoded.
+//
+// Offset and Path are relative to the text being decoded.  When decoding
+// with json.Unmarshal(), that is the text of the BigJSONValue,
+// NatJSONValue or HybJSONValue itself, not the enclosing document.
+type DecodeError struct {
+	Offset  int64  // byte offset of the offending text
+	Path    string // JSON Pointer to the offending value, "" if the whole text
+	Snippet string // offending text, truncated to 32 bytes with "..." added
+	Kind    Kind   // kind being decoded, or Nil if text is not valid JSON
+	Err     error  // ErrInvalidJSON, ErrNotImplemented, strconv.ErrRange, etc.
+}
+
+// Error implements the error interface for DecodeError.
+func (e *DecodeError) Error() string {
+	var path string
+	if e.Path != "" {
+		path = " in " + e.Path
+	}
+	return fmt.Sprintf("cannot decode %q as %s at offset %d%s: %s",
+		e.Snippet, e.Kind, e.Offset, path, e.Err)
+}
+
+// Unwrap returns the underlying error, so errors.Is(err, ErrInvalidJSON)
+// and errors.Is(err, strconv.ErrRange) work on a DecodeError.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// newDecodeError wraps err in a DecodeError for the text starting at
+// offset, or returns nil if err is nil.  A DecodeError from a nested
+// value is returned as-is.
+func newDecodeError(text []byte, offset int64, kind Kind, err error) error {
+	if err == nil {
+		return nil
+	} else if _, ok := err.(*DecodeError); ok {
+		return err
+	}
+	if offset > int64(len(text)) {
+		offset = int64(len(text))
+	}
+	return &DecodeError{
+		Offset:  offset,
+		Snippet: snippet(text[offset:]),
+		Kind:    kind,
+		Err:     err,
+	}
+}
+
+// offsetDecodeError moves a DecodeError for text starting at offset
+// within a stream to be relative to the stream.  Other errors are
+// returned as-is.
+func offsetDecodeError(err error, offset int64) error {
+	if decErr, ok := err.(*DecodeError); ok {
+		decErr.Offset += offset
+	}
+	return err
+}
+
+// snippet returns text as a string, truncated to maxSnippetLen bytes on
+// a UTF-8 boundary with "..." added if longer.
+func snippet(text []byte) string {
+	if len(text) <= maxSnippetLen {
+		return string(text)
+	}
+	end := maxSnippetLen
+	for end > 0 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	return string(text[:end]) + "..."
+}
+
+// escapePointerToken escapes a member key or element index for use as a
+// JSON Pointer reference token, per RFC 6901.
+func escapePointerToken(token string) string {
+	if strings.IndexAny(token, "~/") < 0 {
+		return token
+	}
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
@@ -0,0 +1,109 @@
+package bigjsonvalue
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+type decodeErrorRec struct {
+	decode  func(text string) error
+	jsonStr string
+	offset  int64
+	path    string
+	snippet string
+	kind    Kind
+	err     error
+}
+
+func bigDecodeFn(text string) error {
+	_, err := new(BigJSONValue).DecodeJSONValue(text)
+	return err
+}
+
+func bigDecimalDecodeFn(text string) error {
+	_, err := BigDecodeOptions{Decimal: true}.DecodeJSONValue(new(BigJSONValue), text)
+	return err
+}
+
+func natDecodeFn(text string) error {
+	_, err := new(NatJSONValue).DecodeJSONValue(text)
+	return err
+}
+
+func hybDecodeFn(text string) error {
+	_, err := new(HybJSONValue).DecodeJSONValue(text)
+	return err
+}
+
+var decodeErrorList = []decodeErrorRec{
+	{bigDecodeFn, ``, 0, "", "", Nil, ErrInvalidJSON},
+	{bigDecodeFn, `tru`, 0, "", "tru", Nil, ErrInvalidJSON},
+	{bigDecimalDecodeFn, `[1, 1e2147483648]`, 4, "/1", "1e2147483648", Decimal, strconv.ErrRange},
+	{bigDecodeFn, `[1, tru]`, 4, "/1", "tru", Nil, ErrInvalidJSON},
+	{bigDecodeFn, `{"a": tru}`, 6, "/a", "tru", Nil, ErrInvalidJSON},
+	{bigDecodeFn, `{"a": 1,}`, 8, "", "}", Object, ErrInvalidJSON},
+	{bigDecodeFn, `{"a": 1,, 2}`, 8, "", ", 2}", Object, ErrInvalidJSON},
+	{bigDecimalDecodeFn, `{"a/b": {"x~": 1e2147483648}}`, 15, "/a~1b/x~0", "1e2147483648", Decimal, strconv.ErrRange},
+	{natDecodeFn, `[1, [2, 99999999999999999999]]`, 8, "/1/1", "99999999999999999999", Uint64, strconv.ErrRange},
+	{natDecodeFn, `{"a": [{"b~": -99999999999999999999}]}`, 14, "/a/0/b~0", "-99999999999999999999", Int64, strconv.ErrRange},
+	{natDecodeFn, `-123456789012345678901234567890123456789`, 0, "",
+		"-1234567890123456789012345678901...", Int64, strconv.ErrRange},
+	{natDecodeFn, `["éééééééé", 01]`, 21, "/1", "01", Nil, ErrInvalidJSON},
+	{hybDecodeFn, `"ééééééééééééééééééé`, 39, "", "", Nil, ErrInvalidJSON},
+	{hybDecodeFn, `[1 xééééééééééééééééééé]`, 3, "", `xééééééééééééééé...`, Array, ErrInvalidJSON},
+	{hybDecodeFn, `{"k": [1, {"é": 2,, 3}]}`, 19, "/k/1", ", 3}]}", Object, ErrInvalidJSON},
+	{bigDecodeFn, `{"a": [1, 2`, 11, "/a", "", Array, ErrInvalidJSON},
+	{natDecodeFn, `{"a\q": 1}`, 4, "", `q": 1}`, Object, ErrInvalidJSON},
+	{bigDecodeFn, `[1] `, 3, "", " ", Array, ErrInvalidJSON},
+	{bigDecodeFn, `"a"b"`, 3, "", `b"`, String, ErrInvalidJSON},
+	{natDecodeFn, `"a\x"`, 3, "", `x"`, String, ErrInvalidJSON},
+	{hybDecodeFn, "\"a\tb\"", 2, "", "\tb\"", String, ErrInvalidJSON},
+}
+
+func TestDecodeError(t *testing.T) {
+	for idx, rec := range decodeErrorList {
+		err := rec.decode(rec.jsonStr)
+		var decErr *DecodeError
+		if !errors.As(err, &decErr) {
+			t.Errorf("%d: DecodeJSONValue(%s) err=%v, expected a *DecodeError", idx, rec.jsonStr, err)
+			continue
+		}
+		if decErr.Offset != rec.offset || decErr.Path != rec.path || decErr.Snippet != rec.snippet ||
+			decErr.Kind != rec.kind || decErr.Err != rec.err {
+			t.Errorf("%d: DecodeJSONValue(%s) err=%+v, testRec=%+v", idx, rec.jsonStr, decErr, rec)
+		}
+		if !errors.Is(err, rec.err) {
+			t.Errorf("%d: DecodeJSONValue(%s) err=%v is not %v", idx, rec.jsonStr, err, rec.err)
+		}
+	}
+}
+
+func TestDecodeErrorString(t *testing.T) {
+	_, err := new(NatJSONValue).DecodeJSONValue(`[1, 18446744073709551616]`)
+	expected := `cannot decode "18446744073709551616" as Uint64 at offset 4 in /1: value out of range`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Unexpected err=%v, expected %s", err, expected)
+	}
+
+	_, err = new(BigJSONValue).DecodeJSONValue(`nul`)
+	expected = `cannot decode "nul" as Nil at offset 0: invalid JSON`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Unexpected err=%v, expected %s", err, expected)
+	}
+}
+
+func TestDecodeErrorUnmarshalJSON(t *testing.T) {
+	var walRec struct {
+		ColumnValues []NatJSONValue `json:"columnvalues"`
+	}
+	err := json.Unmarshal([]byte(`{"columnvalues": [1, [true, 1e400]]}`), &walRec)
+	var decErr *DecodeError
+	if !errors.As(err, &decErr) || !errors.Is(err, strconv.ErrRange) {
+		t.Fatalf("Unexpected err=%v", err)
+	}
+	if decErr.Offset != 7 || decErr.Path != "/1" || decErr.Kind != Float64 {
+		t.Errorf("Unexpected err=%+v", decErr)
+	}
+}
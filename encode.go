@@ -0,0 +1,76 @@
+package bigjsonvalue
+
+import (
+	"unicode/utf8"
+)
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString appends s to buf as a double-quoted JSON string,
+// escaping double-quotes, backslashes and control characters.
+// Invalid UTF-8 is replaced with U+FFFD, and U+2028 and U+2029 are
+// escaped so the output is also safe to embed in JavaScript.
+func appendJSONString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	start := 0
+	for idx := 0; idx < len(s); {
+		c := s[idx]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				idx++
+				continue
+			}
+			buf = append(buf, s[start:idx]...)
+			switch c {
+			case '"', '\\':
+				buf = append(buf, '\\', c)
+			case '\b':
+				buf = append(buf, '\\', 'b')
+			case '\f':
+				buf = append(buf, '\\', 'f')
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xF])
+			}
+			idx++
+			start = idx
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[idx:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:idx]...)
+			buf = append(buf, `\ufffd`...)
+			idx += size
+			start = idx
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			buf = append(buf, s[start:idx]...)
+			buf = append(buf, '\\', 'u', '2', '0', '2', hexDigits[r&0xF])
+			idx += size
+			start = idx
+			continue
+		}
+		idx += size
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
+
+// appendJSONFloat appends the number text of a float value to buf,
+// adding a trailing ".0" if needed so the text decodes as a float again.
+func appendJSONFloat(buf []byte, text string) []byte {
+	buf = append(buf, text...)
+	for idx := 0; idx < len(text); idx++ {
+		switch text[idx] {
+		case '.', 'e', 'E':
+			return buf
+		}
+	}
+	return append(buf, '.', '0')
+}
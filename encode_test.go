@@ -0,0 +1,50 @@
+package bigjsonvalue
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAppendJSONString(t *testing.T) {
+	testList := []struct {
+		str      string
+		expected string
+	}{
+		{"", `""`},
+		{"plain", `"plain"`},
+		{"a\\b\\c new\nline", `"a\\b\\c new\nline"`},
+		{`"quoted"`, `"\"quoted\""`},
+		{"\b\f\r\t\x00\x1f", `"\b\f\r\t\u0000\u001f"`},
+		{"<html> & é 😀", `"<html> & é 😀"`},
+		{"\u2028\u2029", `"\u2028\u2029"`},
+		{"bad\xffutf8", `"bad\ufffdutf8"`},
+	}
+	for idx, rec := range testList {
+		text := string(appendJSONString(nil, rec.str))
+		if text != rec.expected {
+			t.Errorf("%d: appendJSONString(%q)=%s, expected %s", idx, rec.str, text, rec.expected)
+		}
+		var str string
+		if err := json.Unmarshal([]byte(text), &str); err != nil {
+			t.Errorf("%d: json.Unmarshal(%s) err=%s", idx, text, err)
+		}
+	}
+}
+
+func TestAppendJSONFloat(t *testing.T) {
+	testList := []struct {
+		text     string
+		expected string
+	}{
+		{"3", "3.0"},
+		{"-0", "-0.0"},
+		{"3.14", "3.14"},
+		{"1e+21", "1e+21"},
+		{"1E-7", "1E-7"},
+	}
+	for idx, rec := range testList {
+		if text := string(appendJSONFloat(nil, rec.text)); text != rec.expected {
+			t.Errorf("%d: appendJSONFloat(%s)=%s, expected %s", idx, rec.text, text, rec.expected)
+		}
+	}
+}
@@ -2,7 +2,6 @@ package bigjsonvalue
 
 import (
 	"errors"
-	"regexp"
 )
 
 // Package errors
@@ -12,15 +11,79 @@ var (
 
 	// ErrNotImplemented defines the not-implemented error
 	ErrNotImplemented = errors.New("not implemented")
+
+	// ErrUnsupportedValue defines the error for values that cannot be
+	// encoded as JSON, such as infinite numbers
+	ErrUnsupportedValue = errors.New("unsupported JSON value")
+
+	// ErrKindMismatch defines the error for converting a value to a kind
+	// it has no conversion to, such as a string to a number
+	ErrKindMismatch = errors.New("kind mismatch")
+
+	// ErrOverflow defines the error for converting a number to a kind
+	// whose range cannot hold it
+	ErrOverflow = errors.New("number out of range")
+
+	// ErrPrecisionLoss defines the error for converting a number to a kind
+	// that cannot hold it exactly
+	ErrPrecisionLoss = errors.New("number loses precision")
+
+	// ErrInvalidPointer defines the error for JSON Pointers that are not
+	// well-formed per RFC 6901
+	ErrInvalidPointer = errors.New("invalid JSON Pointer")
+
+	// ErrPointerNotFound defines the error for JSON Pointers referencing
+	// an object member or array element that does not exist
+	ErrPointerNotFound = errors.New("JSON Pointer value not found")
+
+	// ErrInvalidIndex defines the error for JSON Pointer reference tokens
+	// applied to an array that are not valid array indexes
+	ErrInvalidIndex = errors.New("invalid array index")
+
+	// ErrInvalidJSONPath defines the error for JSONPath queries that are
+	// not well-formed or well-typed per RFC 9535
+	ErrInvalidJSONPath = errors.New("invalid JSONPath query")
+
+	// ErrInvalidPatch defines the error for JSON Patches and operations
+	// that are not well-formed per RFC 6902
+	ErrInvalidPatch = errors.New("invalid JSON Patch")
+
+	// ErrTestFailed defines the error for JSON Patch "test" operations
+	// whose value is not equal to the target value
+	ErrTestFailed = errors.New("JSON Patch test failed")
+
+	// ErrMergePatchNull defines the error for creating a JSON Merge Patch
+	// that would need to set an object member to null, which RFC 7396
+	// merge patches cannot do
+	ErrMergePatchNull = errors.New("null member cannot be merge patched")
+
+	// ErrCyclicValue defines the error for converting a Go value that
+	// contains itself, such as a map holding itself as a member
+	ErrCyclicValue = errors.New("cyclic value")
+
+	// ErrSkipSubtree defines the error a Handler returns to have Walk()
+	// skip an object, array or member value
+	ErrSkipSubtree = errors.New("skip this subtree")
+
+	// ErrStopWalk defines the error a Handler returns to have Walk() stop
+	// without returning an error
+	ErrStopWalk = errors.New("stop walking")
+
+	// ErrTooDeep defines the error for decoding values nested in more
+	// than MaxDepth objects and arrays
+	ErrTooDeep = errors.New("JSON value nested too deeply")
 )
 
 // Package constants
 const (
 	// JSONNumRegexpPat defines the regexp pattern for matching JSON numbers
-	// (integers or floats) based on http://json.org
-	JSONNumRegexpPat = `^-?\d+(\.\d+)?([eE][-+]?\d+)?$`
-)
+	// (integers or floats) based on RFC 8259 section 6, which allows no
+	// leading zeros except for a single "0" integer part.  Decoding does
+	// not use it, but it matches exactly the same number text.
+	JSONNumRegexpPat = `^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][-+]?[0-9]+)?$`
 
-// This global regexp is (mostly) thread-safe according to
-// https://golang.org/pkg/regexp/#Regexp
-var jsonNumRegexp = regexp.MustCompile(JSONNumRegexpPat)
+	// MaxDepth defines the most objects and arrays a decoded value may be
+	// nested in, the same limit as encoding/json, so deep text cannot
+	// exhaust the stack of the methods that recurse into values.
+	MaxDepth = 10000
+)
@@ -0,0 +1,161 @@
+package bigjsonvalue
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"math"
+	"math/big"
+)
+
+// Tags starting each value written by proxyHasher, so that values of
+// different kinds never write the same bytes.
+const (
+	hashTagNil    = 'z'
+	hashTagFalse  = 'f'
+	hashTagTrue   = 't'
+	hashTagNaN    = 'N'
+	hashTagNegInf = 'I'
+	hashTagPosInf = 'J'
+	hashTagInt    = 'i'
+	hashTagRat    = 'r'
+	hashTagFloat  = 'F'
+	hashTagString = 's'
+	hashTagArray  = 'a'
+	hashTagObject = 'o'
+)
+
+// proxyHasher writes proxy values to a hash.Hash in a canonical form,
+// where values that compare equal always write the same bytes.
+type proxyHasher struct {
+	h   hash.Hash
+	buf [10]byte
+	mag [8]byte
+}
+
+// hashProxy writes proxy to h in canonical form.
+func hashProxy(h hash.Hash, proxy interface{}) {
+	hasher := proxyHasher{h: h}
+	hasher.write(proxy)
+}
+
+// hash64Proxy returns the 64-bit FNV-1a hash of proxy in canonical form.
+func hash64Proxy(proxy interface{}) uint64 {
+	h := fnv.New64a()
+	hashProxy(h, proxy)
+	return h.Sum64()
+}
+
+// write writes proxy in canonical form.
+func (hasher *proxyHasher) write(proxy interface{}) {
+	switch proxy.(type) {
+	case nil:
+		hasher.writeTag(hashTagNil)
+	case bool:
+		if proxy.(bool) {
+			hasher.writeTag(hashTagTrue)
+		} else {
+			hasher.writeTag(hashTagFalse)
+		}
+	case string:
+		str := proxy.(string)
+		hasher.writeLen(hashTagString, len(str))
+		hasher.h.Write([]byte(str))
+	case int64:
+		i64 := proxy.(int64)
+		if i64 < 0 {
+			hasher.writeInt(true, uint64(-i64))
+		} else {
+			hasher.writeInt(false, uint64(i64))
+		}
+	case uint64:
+		hasher.writeInt(false, proxy.(uint64))
+	case float64:
+		f64 := proxy.(float64)
+		if f64 == math.Trunc(f64) && math.Abs(f64) < 1<<64 {
+			hasher.writeInt(f64 < 0, uint64(math.Abs(f64)))
+		} else {
+			hasher.writeNumber(proxy)
+		}
+	case *bigObject, *natObject, *hybObject:
+		keys := sortedKeys(proxy)
+		hasher.writeLen(hashTagObject, len(keys))
+		for _, key := range keys {
+			member, _ := proxyMember(proxy, key)
+			hasher.write(key)
+			hasher.write(member)
+		}
+	case []BigJSONValue, []NatJSONValue, []HybJSONValue:
+		n := proxyLen(proxy)
+		hasher.writeLen(hashTagArray, n)
+		for idx := 0; idx < n; idx++ {
+			hasher.write(proxyIndex(proxy, idx))
+		}
+	default:
+		hasher.writeNumber(proxy)
+	}
+}
+
+// writeNumber writes a number proxy value using its numKey.
+func (hasher *proxyHasher) writeNumber(proxy interface{}) {
+	key := newNumKey(proxy)
+	switch {
+	case key.nan:
+		hasher.writeTag(hashTagNaN)
+	case key.inf < 0:
+		hasher.writeTag(hashTagNegInf)
+	case key.inf > 0:
+		hasher.writeTag(hashTagPosInf)
+	case key.rat != nil && key.rat.IsInt():
+		hasher.writeBigInt(hashTagInt, key.rat.Num())
+	case key.rat != nil:
+		hasher.writeBigInt(hashTagRat, key.rat.Num())
+		hasher.writeBigInt(hashTagRat, key.rat.Denom())
+	default:
+		text := key.flt.Text('p', 0)
+		hasher.writeLen(hashTagFloat, len(text))
+		hasher.h.Write([]byte(text))
+	}
+}
+
+// writeInt writes an integer given its sign and magnitude, as the same
+// bytes writeBigInt() writes for the same value.
+func (hasher *proxyHasher) writeInt(neg bool, mag uint64) {
+	binary.BigEndian.PutUint64(hasher.mag[:], mag)
+	skip := 0
+	for skip < len(hasher.mag) && hasher.mag[skip] == 0 {
+		skip++
+	}
+	hasher.writeMagnitude(hashTagInt, neg && mag != 0, hasher.mag[skip:])
+}
+
+// writeBigInt writes tag, and the sign and magnitude of bigi.
+func (hasher *proxyHasher) writeBigInt(tag byte, bigi *big.Int) {
+	hasher.writeMagnitude(tag, bigi.Sign() < 0, bigi.Bytes())
+}
+
+// writeMagnitude writes tag, a sign byte, and the length and bytes of
+// the big-endian magnitude mag, which has no leading zeros.
+func (hasher *proxyHasher) writeMagnitude(tag byte, neg bool, mag []byte) {
+	hasher.buf[0] = tag
+	hasher.buf[1] = '+'
+	if neg {
+		hasher.buf[1] = '-'
+	}
+	binary.BigEndian.PutUint64(hasher.buf[2:], uint64(len(mag)))
+	hasher.h.Write(hasher.buf[:])
+	hasher.h.Write(mag)
+}
+
+// writeLen writes tag followed by length n.
+func (hasher *proxyHasher) writeLen(tag byte, n int) {
+	hasher.buf[0] = tag
+	binary.BigEndian.PutUint64(hasher.buf[1:9], uint64(n))
+	hasher.h.Write(hasher.buf[:9])
+}
+
+// writeTag writes tag alone.
+func (hasher *proxyHasher) writeTag(tag byte) {
+	hasher.buf[0] = tag
+	hasher.h.Write(hasher.buf[:1])
+}
@@ -0,0 +1,103 @@
+package bigjsonvalue
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestHashProxies(t *testing.T) {
+	groupHashes := make(map[uint64]int)
+	for idx, group := range compareList {
+		hash := hash64Proxy(group[0])
+		for _, proxy := range group[1:] {
+			if h := hash64Proxy(proxy); h != hash {
+				t.Errorf("%d: hash64Proxy(%v)=%x, expected %x like %v", idx, proxy, h, hash, group[0])
+			}
+		}
+		if prev, dup := groupHashes[hash]; dup {
+			t.Errorf("%d: hash64Proxy(%v) is the same as group %d", idx, group[0], prev)
+		}
+		groupHashes[hash] = idx
+	}
+}
+
+// limitList holds groups of equal number proxy values around the limit
+// of exact comparison, in ascending order.
+var limitList = [][]interface{}{
+	{new(big.Float).SetMantExp(big.NewFloat(0.5), -maxRatBits+1), bigDecimal{
+		unscaled: new(big.Int).Exp(big.NewInt(5), big.NewInt(maxRatBits), nil), scale: maxRatBits}},
+	{decimalProxy("1e-100001"), json.Number("1e-100001")},
+	{decimalProxy("1e100001"), json.Number("10e100000"), mustBigInt("1" + zeros(100001))},
+	{new(big.Int).Lsh(big.NewInt(1), maxRatBits), new(big.Float).SetMantExp(big.NewFloat(0.5), maxRatBits+1)},
+	{new(big.Int).Lsh(big.NewInt(1), maxRatBits+1), new(big.Float).SetMantExp(big.NewFloat(0.5), maxRatBits+2)},
+	{new(big.Int).Lsh(big.NewInt(1), maxRatBits+3), new(big.Float).SetMantExp(big.NewFloat(0.5), maxRatBits+4)},
+}
+
+func TestHashLimits(t *testing.T) {
+	for idx, group := range limitList {
+		var set BigSet
+		set.Add(&BigJSONValue{proxy: group[0]})
+		for _, proxy := range group[1:] {
+			if !equalProxies(group[0], proxy) {
+				t.Errorf("%d: equalProxies(%v, %v)=false", idx, group[0], proxy)
+			}
+			if hash64Proxy(proxy) != hash64Proxy(group[0]) {
+				t.Errorf("%d: hash64Proxy(%v) differs from %v", idx, proxy, group[0])
+			}
+			if !set.Contains(&BigJSONValue{proxy: proxy}) {
+				t.Errorf("%d: Contains(%v)=false", idx, proxy)
+			}
+		}
+		if idx > 0 && compareProxies(limitList[idx-1][0], group[0]) >= 0 {
+			t.Errorf("%d: %v is not less than %v", idx, limitList[idx-1][0], group[0])
+		}
+	}
+}
+
+func TestHashTo(t *testing.T) {
+	bjv, _ := new(BigJSONValue).FromInterface(map[string]interface{}{
+		"id":   json.Number("9007199254740993"),
+		"tags": []interface{}{"a", json.Number("1.50")},
+	})
+	njv, _ := new(NatJSONValue).FromInterface(map[string]interface{}{
+		"tags": []interface{}{"a", 1.5},
+		"id":   uint64(9007199254740993),
+	})
+	hjv, _ := new(HybJSONValue).DecodeJSONValue(`{"tags": ["a", 1.5], "id": 9007199254740993}`)
+	bigHash, natHash, hybHash := sha256.New(), sha256.New(), sha256.New()
+	bjv.HashTo(bigHash)
+	njv.HashTo(natHash)
+	hjv.HashTo(hybHash)
+	if string(bigHash.Sum(nil)) != string(natHash.Sum(nil)) || string(bigHash.Sum(nil)) != string(hybHash.Sum(nil)) {
+		t.Errorf("HashTo() differs for %s, %s and %s", bjv, njv, hjv)
+	}
+	if bjv.Hash64() != njv.Hash64() || bjv.Hash64() != hjv.Hash64() {
+		t.Errorf("Hash64() differs for %s, %s and %s", bjv, njv, hjv)
+	}
+
+	other, _ := new(BigJSONValue).FromInterface(map[string]interface{}{
+		"id":   json.Number("9007199254740992"),
+		"tags": []interface{}{"a", json.Number("1.50")},
+	})
+	if bjv.Hash64() == other.Hash64() {
+		t.Errorf("Hash64() is the same for %s and %s", bjv, other)
+	}
+}
+
+func BenchmarkHash64Int64(b *testing.B) {
+	njv := NewNatInt64(-1234567890)
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		njv.Hash64()
+	}
+}
+
+func BenchmarkHash64Object(b *testing.B) {
+	bjv, _ := new(BigJSONValue).DecodeJSONValue(`{"id": 9007199254740993, "name": "foo", "price": 19.99}`)
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		bjv.Hash64()
+	}
+}
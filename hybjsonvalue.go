@@ -0,0 +1,719 @@
+package bigjsonvalue
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// HybJSONValue is wrapper around interface{} type to force
+// json.Unmarshal() to decode integer values as int64, uint64 or big.Int
+// instead of float64.  The problem with float64 is that it doesn't have
+// enough precision to store exact values of large int64 and uint64 values.
+// Instead of trying to unmarshal JSON into an interface{},
+// unmarshal into a HybJSONValue instead.
+//
+// HybJSONValue is a hybrid of NatJSONValue and BigJSONValue: it stores
+// numbers as native Golang number types int64, uint64, and float64 when
+// they hold the number exactly as written, so is nearly as fast as
+// NatJSONValue, and only promotes them when they do not.  Integers out
+// of range are promoted to big.Int.  Fractional numbers out of range, or
+// with more significant digits than a float64 keeps, e.g.
+// 987654321.987654321, are decoded as BigJSONValue would decode them:
+// as big.Float values with 128 bits of precision rounded to nearest even,
+// or with the options given to BigDecodeOptions.DecodeHybJSONValue(),
+// e.g. as exact Decimal values if its Decimal is true.  Numbers with
+// exponents too large even for big.Float are kept as their original text
+// as json.Number values.
+type HybJSONValue struct {
+	proxy interface{}
+}
+
+// hybObject holds the members of a JSON object decoded by HybJSONValue,
+// remembering the order in which keys appeared in the document.
+type hybObject struct {
+	keys    []string
+	members map[string]*HybJSONValue
+}
+
+// set sets the member with the given key, appending key to keys if not
+// already present.
+func (obj *hybObject) set(key string, member *HybJSONValue) {
+	if _, dup := obj.members[key]; !dup {
+		obj.keys = append(obj.keys, key)
+	}
+	obj.members[key] = member
+}
+
+// hybNatDecodeOptions are the options HybJSONValue decodes numbers with
+// before promoting them.
+var hybNatDecodeOptions = NatDecodeOptions{Overflow: OverflowError}
+
+// hybBigDecodeOptions are the options HybJSONValue promotes numbers with,
+// unless others are given to BigDecodeOptions.DecodeHybJSONValue().
+// They do not follow DefaultBigDecodeOptions, so changing it does not
+// change how HybJSONValue decodes.
+var hybBigDecodeOptions = BigDecodeOptions{Prec: 128, Mode: big.ToNearestEven}
+
+// Kind returns the kind of HybJSONValue it is holding:
+//
+// Returns Bool if value is a bool.
+//
+// Returns String if value is a string.
+//
+// Returns Int64 if value is a int64.
+//
+// Returns Uint64 if value is a uint64.
+//
+// Returns Float64 if value is a float64.
+//
+// Returns BigInt if value is a big.Int.
+//
+// Returns BigFloat if value is a big.Float.
+//
+// Returns Object if value is a JSON object.
+//
+// Returns Array if value is a JSON array.
+//
+// Returns Decimal if value is an exact decimal, or Number if value is a
+// json.Number, which are only decoded for promoted fractional numbers.
+//
+// Otherwise returns Nil.
+func (hjv *HybJSONValue) Kind() Kind {
+	switch hjv.proxy.(type) {
+	case bool:
+		return Bool
+	case string:
+		return String
+	case int64:
+		return Int64
+	case uint64:
+		return Uint64
+	case float64:
+		return Float64
+	case *big.Int:
+		return BigInt
+	case *big.Float:
+		return BigFloat
+	case *hybObject:
+		return Object
+	case []HybJSONValue:
+		return Array
+	case bigDecimal:
+		return Decimal
+	case json.Number:
+		return Number
+	default:
+		return Nil
+	}
+}
+
+// IsNil returns true if value is nil.
+func (hjv *HybJSONValue) IsNil() bool {
+	return (hjv.Kind() == Nil)
+}
+
+// IsBool returns true if value is a bool.
+func (hjv *HybJSONValue) IsBool() bool {
+	return (hjv.Kind() == Bool)
+}
+
+// IsString returns true if value is a string.
+func (hjv *HybJSONValue) IsString() bool {
+	return (hjv.Kind() == String)
+}
+
+// IsFloat64 returns true if value is a float64.
+func (hjv *HybJSONValue) IsFloat64() bool {
+	return (hjv.Kind() == Float64)
+}
+
+// IsInt64 returns true if value is a int64.
+func (hjv *HybJSONValue) IsInt64() bool {
+	return (hjv.Kind() == Int64)
+}
+
+// IsUint64 returns true if value is a uint64.
+func (hjv *HybJSONValue) IsUint64() bool {
+	return (hjv.Kind() == Uint64)
+}
+
+// IsBigFloat returns true if value is a big.Float.
+func (hjv *HybJSONValue) IsBigFloat() bool {
+	return (hjv.Kind() == BigFloat)
+}
+
+// IsBigInt returns true if value is a big.Int.
+func (hjv *HybJSONValue) IsBigInt() bool {
+	return (hjv.Kind() == BigInt)
+}
+
+// IsDecimal returns true if value is an exact decimal.
+func (hjv *HybJSONValue) IsDecimal() bool {
+	return (hjv.Kind() == Decimal)
+}
+
+// IsNumber returns true if value is a json.Number.
+func (hjv *HybJSONValue) IsNumber() bool {
+	return (hjv.Kind() == Number)
+}
+
+// IsObject returns true if value is a JSON object.
+func (hjv *HybJSONValue) IsObject() bool {
+	return (hjv.Kind() == Object)
+}
+
+// IsArray returns true if value is a JSON array.
+func (hjv *HybJSONValue) IsArray() bool {
+	return (hjv.Kind() == Array)
+}
+
+// Value returns the underlying interface{} value that is being wrapped.
+//
+// BigInt and BigFloat values return a copy as a big.Int or big.Float.
+//
+// Object values return a map[string]*HybJSONValue of their members.
+//
+// Array values return a []*HybJSONValue of their elements.
+func (hjv *HybJSONValue) Value() interface{} {
+	switch hjv.proxy.(type) {
+	case *big.Int:
+		return hjv.BigInt()
+	case *big.Float:
+		return hjv.BigFloat()
+	case *hybObject:
+		obj := hjv.proxy.(*hybObject)
+		members := make(map[string]*HybJSONValue, len(obj.members))
+		for key, member := range obj.members {
+			members[key] = member
+		}
+		return members
+	case []HybJSONValue:
+		arr := hjv.proxy.([]HybJSONValue)
+		elems := make([]*HybJSONValue, len(arr))
+		for idx := range arr {
+			elems[idx] = &arr[idx]
+		}
+		return elems
+	default:
+		return hjv.proxy
+	}
+}
+
+// Bool returns the underlying bool value.
+// Panics with runtime error if not a bool.
+func (hjv *HybJSONValue) Bool() bool {
+	return hjv.proxy.(bool)
+}
+
+// Float64 returns the underlying float64 value.
+// Panics with runtime error if not a float64.
+func (hjv *HybJSONValue) Float64() float64 {
+	return hjv.proxy.(float64)
+}
+
+// Int64 returns the underlying int64 value.
+// Panics with runtime error if not a int64.
+func (hjv *HybJSONValue) Int64() int64 {
+	return hjv.proxy.(int64)
+}
+
+// Uint64 returns the underlying uint64 value.
+// Panics with runtime error if not a uint64.
+func (hjv *HybJSONValue) Uint64() uint64 {
+	return hjv.proxy.(uint64)
+}
+
+// BigFloat returns a copy of the underlying big.Float value, which can be
+// changed without affecting the stored value.
+// Panics with runtime error if not a big.Float.
+func (hjv *HybJSONValue) BigFloat() big.Float {
+	var bigf big.Float
+	bigf.Copy(hjv.proxy.(*big.Float))
+	return bigf
+}
+
+// BigFloatRef returns a read-only view of the underlying big.Float value,
+// without copying it.  The result must not be modified, since it is the
+// stored value itself.
+// Panics with runtime error if not a big.Float.
+func (hjv *HybJSONValue) BigFloatRef() *big.Float {
+	return hjv.proxy.(*big.Float)
+}
+
+// BigInt returns a copy of the underlying big.Int value, which can be
+// changed without affecting the stored value.
+// Panics with runtime error if not a big.Int.
+func (hjv *HybJSONValue) BigInt() big.Int {
+	var bigi big.Int
+	bigi.Set(hjv.proxy.(*big.Int))
+	return bigi
+}
+
+// BigIntRef returns a read-only view of the underlying big.Int value,
+// without copying it.  The result must not be modified, since it is the
+// stored value itself.
+// Panics with runtime error if not a big.Int.
+func (hjv *HybJSONValue) BigIntRef() *big.Int {
+	return hjv.proxy.(*big.Int)
+}
+
+// Unscaled returns a copy of the unscaled integer value of an exact decimal,
+// e.g. 1999 for 19.99.
+// Panics with runtime error if not a decimal.
+func (hjv *HybJSONValue) Unscaled() big.Int {
+	var bigi big.Int
+	bigi.Set(hjv.proxy.(bigDecimal).unscaled)
+	return bigi
+}
+
+// Scale returns the number of decimal digits after the decimal point of
+// an exact decimal, e.g. 2 for 19.99, or the negated power of ten it is
+// multiplied by if negative, e.g. -2 for 15e2.
+// Panics with runtime error if not a decimal.
+func (hjv *HybJSONValue) Scale() int {
+	return hjv.proxy.(bigDecimal).scale
+}
+
+// Rat returns the exact value of a decimal as a new big.Rat.
+// Returns ErrOverflow if the decimal is multiplied or divided by a power
+// of ten larger than 10**100000.
+// Panics with runtime error if not a decimal.
+func (hjv *HybJSONValue) Rat() (*big.Rat, error) {
+	return numberRat(hjv.proxy.(bigDecimal))
+}
+
+// Number returns the underlying json.Number value.
+// Panics with runtime error if not a json.Number.
+func (hjv *HybJSONValue) Number() json.Number {
+	return hjv.proxy.(json.Number)
+}
+
+// AsBool returns the value as a bool.
+// Returns a *ConvertError wrapping ErrKindMismatch if not a bool.
+func (hjv *HybJSONValue) AsBool() (bool, error) {
+	b, err := asBool(hjv.proxy)
+	return b, newConvertError(hjv.Kind(), Bool, err)
+}
+
+// AsString returns the value as a string.
+// Returns a *ConvertError wrapping ErrKindMismatch if not a string.
+func (hjv *HybJSONValue) AsString() (string, error) {
+	str, err := asString(hjv.proxy)
+	return str, newConvertError(hjv.Kind(), String, err)
+}
+
+// AsInt64 returns a number value converted to an int64.
+// Returns a *ConvertError wrapping ErrKindMismatch if not a number,
+// ErrOverflow with the nearest int64 if out of range, or
+// ErrPrecisionLoss with the value truncated toward zero if fractional.
+func (hjv *HybJSONValue) AsInt64() (int64, error) {
+	i64, err := asInt64(hjv.proxy)
+	return i64, newConvertError(hjv.Kind(), Int64, err)
+}
+
+// AsUint64 returns a number value converted to a uint64.
+// Returns a *ConvertError wrapping ErrKindMismatch if not a number,
+// ErrOverflow with the nearest uint64 if out of range, or
+// ErrPrecisionLoss with the value truncated toward zero if fractional.
+func (hjv *HybJSONValue) AsUint64() (uint64, error) {
+	u64, err := asUint64(hjv.proxy)
+	return u64, newConvertError(hjv.Kind(), Uint64, err)
+}
+
+// AsFloat64 returns a number value converted to a float64.
+// Returns a *ConvertError wrapping ErrKindMismatch if not a number,
+// ErrOverflow with ±Inf if out of range, or ErrPrecisionLoss with the
+// nearest float64 if not exactly representable.
+func (hjv *HybJSONValue) AsFloat64() (float64, error) {
+	f64, err := asFloat64(hjv.proxy)
+	return f64, newConvertError(hjv.Kind(), Float64, err)
+}
+
+// AsBigInt returns a number value converted to a new big.Int.
+// Returns a *ConvertError wrapping ErrKindMismatch if not a number,
+// ErrOverflow if infinite, or ErrPrecisionLoss with the value truncated
+// toward zero if fractional.  The big.Int is nil if there is no
+// nearest value.
+func (hjv *HybJSONValue) AsBigInt() (*big.Int, error) {
+	bigi, err := asBigInt(hjv.proxy)
+	return bigi, newConvertError(hjv.Kind(), BigInt, err)
+}
+
+// AsBigFloat returns a number value converted to a new big.Float.
+// Integers are held exactly.  Returns a *ConvertError wrapping
+// ErrKindMismatch if not a number, ErrUnsupportedValue if NaN, or
+// ErrPrecisionLoss with the nearest value if a decimal cannot be held
+// exactly at the precision of DefaultBigDecodeOptions.  The big.Float
+// is nil if there is no nearest value.
+func (hjv *HybJSONValue) AsBigFloat() (*big.Float, error) {
+	bigf, err := asBigFloat(hjv.proxy)
+	return bigf, newConvertError(hjv.Kind(), BigFloat, err)
+}
+
+// Member returns the member of an object value with the given key.
+// Returns nil and false if not an object or key is not present.
+func (hjv *HybJSONValue) Member(key string) (*HybJSONValue, bool) {
+	obj, ok := hjv.proxy.(*hybObject)
+	if !ok {
+		return nil, false
+	}
+	member, ok := obj.members[key]
+	return member, ok
+}
+
+// Keys returns the keys of an object value in document order.
+// Returns nil if not an object.
+func (hjv *HybJSONValue) Keys() []string {
+	obj, ok := hjv.proxy.(*hybObject)
+	if !ok {
+		return nil
+	}
+	return append([]string(nil), obj.keys...)
+}
+
+// Len returns the number of members of an object value,
+// or the number of elements of an array value.
+// Returns 0 if neither an object nor an array.
+func (hjv *HybJSONValue) Len() int {
+	switch hjv.proxy.(type) {
+	case *hybObject:
+		return len(hjv.proxy.(*hybObject).keys)
+	case []HybJSONValue:
+		return len(hjv.proxy.([]HybJSONValue))
+	default:
+		return 0
+	}
+}
+
+// Index returns the element of an array value at index idx.
+// Returns nil if not an array or idx is out of range.
+func (hjv *HybJSONValue) Index(idx int) *HybJSONValue {
+	arr, _ := hjv.proxy.([]HybJSONValue)
+	if idx < 0 || idx >= len(arr) {
+		return nil
+	}
+	return &arr[idx]
+}
+
+// Range calls fn for each element of an array value in order,
+// stopping early if fn returns false.  Does nothing if not an array.
+func (hjv *HybJSONValue) Range(fn func(idx int, elem *HybJSONValue) bool) {
+	arr, _ := hjv.proxy.([]HybJSONValue)
+	for idx := range arr {
+		if !fn(idx, &arr[idx]) {
+			return
+		}
+	}
+}
+
+// Equal returns true if the value is equal to other, which is the same
+// as Compare(other) == 0.  Numbers of different kinds are equal if they
+// have exactly the same value, e.g. Int64 1, Uint64 1 and Float64 1.0.
+// Objects are equal if they have the same keys with equal members,
+// in any order.
+func (hjv *HybJSONValue) Equal(other *HybJSONValue) bool {
+	return equalProxies(hjv.proxy, other.proxy)
+}
+
+// Compare returns -1, 0 or +1 if the value is less than, equal to, or
+// greater than other.  Values of different kinds are ordered
+// nil < bool < number < string < array < object, and within a kind:
+//
+// Bool values order false before true.
+//
+// Number values order by exact value, whatever their kind.  NaN is
+// equal to itself and less than all other numbers.  Numbers larger or
+// smaller than about 1e±100000 are compared rounded to 512 bits.
+//
+// String values order by their bytes.
+//
+// Array values order by their first unequal element, or by length if
+// one is a prefix of the other.
+//
+// Object values order by their members sorted by key, as if they were
+// arrays of key and member pairs.
+func (hjv *HybJSONValue) Compare(other *HybJSONValue) int {
+	return compareProxies(hjv.proxy, other.proxy)
+}
+
+// Hash64 returns a 64-bit FNV-1a hash of the value, which is the same for
+// values that are Equal(), so can be used as a map key standing in for
+// the value.  Values that are not Equal() may still hash the same.
+func (hjv *HybJSONValue) Hash64() uint64 {
+	return hash64Proxy(hjv.proxy)
+}
+
+// HashTo writes the value to h in a canonical form, which is the same
+// for values that are Equal(), including members of objects in any order
+// and numbers of different kinds with the same exact value.
+func (hjv *HybJSONValue) HashTo(h hash.Hash) {
+	hashProxy(h, hjv.proxy)
+}
+
+// String implements fmt.Stringer interface for HybJSONValue.
+//
+// Bool values return "true" or "false".
+//
+// String values return as-is (no surround double-quotes are added).
+//
+// Number values return with as much precision as possible.
+//
+// Object and array values return as compact JSON text.
+//
+// Nil values return "nil".
+func (hjv *HybJSONValue) String() string {
+	switch hjv.proxy.(type) {
+	case bool:
+		return fmt.Sprintf("%t", hjv.proxy.(bool))
+	case string:
+		return hjv.proxy.(string)
+	case int64:
+		return strconv.FormatInt(hjv.proxy.(int64), 10)
+	case uint64:
+		return strconv.FormatUint(hjv.proxy.(uint64), 10)
+	case float64:
+		return strconv.FormatFloat(hjv.proxy.(float64), 'g', -1, 64)
+	case *big.Int:
+		return hjv.proxy.(*big.Int).String()
+	case *big.Float:
+		return hjv.proxy.(*big.Float).Text('g', -1)
+	case bigDecimal:
+		dec := hjv.proxy.(bigDecimal)
+		return dec.String()
+	case json.Number:
+		return hjv.proxy.(json.Number).String()
+	case *hybObject, []HybJSONValue:
+		text, _ := hjv.appendJSON(nil)
+		return string(text)
+	default:
+		return "nil"
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface for HybJSONValue.
+// It has a value receiver so that HybJSONValue struct fields marshal
+// correctly even when the enclosing struct is not addressable.
+//
+// Values encode the same way as NatJSONValue and BigJSONValue values of
+// the same kind.  Infinite and NaN values cannot be encoded,
+// and return ErrUnsupportedValue.
+func (hjv HybJSONValue) MarshalJSON() ([]byte, error) {
+	return hjv.appendJSON(nil)
+}
+
+// appendJSON appends the compact JSON text of the value to buf.
+// Values that cannot be encoded are appended in their String() form,
+// and ErrUnsupportedValue is returned after the rest is appended.
+func (hjv *HybJSONValue) appendJSON(buf []byte) ([]byte, error) {
+	var err error
+	switch hjv.proxy.(type) {
+	case nil:
+		buf = append(buf, "null"...)
+	case string:
+		buf = appendJSONString(buf, hjv.proxy.(string))
+	case float64:
+		f64 := hjv.proxy.(float64)
+		if math.IsInf(f64, 0) || math.IsNaN(f64) {
+			err = ErrUnsupportedValue
+		}
+		buf = appendJSONFloat(buf, strconv.FormatFloat(f64, 'g', -1, 64))
+	case *big.Float:
+		bigf := hjv.proxy.(*big.Float)
+		if bigf.IsInf() {
+			err = ErrUnsupportedValue
+		}
+		buf = appendJSONFloat(buf, bigf.Text('g', -1))
+	case *hybObject:
+		obj := hjv.proxy.(*hybObject)
+		buf = append(buf, '{')
+		for idx, key := range obj.keys {
+			if idx > 0 {
+				buf = append(buf, ',')
+			}
+			buf = appendJSONString(buf, key)
+			buf = append(buf, ':')
+			var memberErr error
+			buf, memberErr = obj.members[key].appendJSON(buf)
+			if err == nil {
+				err = memberErr
+			}
+		}
+		buf = append(buf, '}')
+	case []HybJSONValue:
+		arr := hjv.proxy.([]HybJSONValue)
+		buf = append(buf, '[')
+		for idx := range arr {
+			if idx > 0 {
+				buf = append(buf, ',')
+			}
+			var elemErr error
+			buf, elemErr = arr[idx].appendJSON(buf)
+			if err == nil {
+				err = elemErr
+			}
+		}
+		buf = append(buf, ']')
+	default:
+		buf = append(buf, hjv.String()...)
+	}
+	return buf, err
+}
+
+// DecodeJSONValue decodes a JSON value, and returns itself.
+// Results are undefined if error is returned, which is always
+// a *DecodeError.
+//
+// The text "null" is decoded as a nil value.
+//
+// The text "true" and "false" are decoded as bool values.
+//
+// Text surrounded by double-quotes are decoded as string values.
+//
+// Text surrounded by curly braces are decoded as object values,
+// with each member decoded as a HybJSONValue.  If a key appears more
+// than once, the last member value wins.
+//
+// Text surrounded by square brackets are decoded as array values,
+// with each element decoded as a HybJSONValue.
+//
+// Number text containing period "." or the letters "e" or "E"
+// are decoded as float64 values, or if out of range or not held exactly,
+// promoted to big.Float, Decimal or json.Number values as described
+// for HybJSONValue.
+//
+// Otherwise, number text is decoded as int64 for negative values
+// or uint64 for positive values and zero, or as big.Int values
+// if out of range.
+// Whether text is considered a number is based on RFC 8259.
+func (hjv *HybJSONValue) DecodeJSONValue(text string) (*HybJSONValue, error) {
+	opts := hybBigDecodeOptions
+	return hjv, hjv.decodeJSONValue([]byte(text), &opts)
+}
+
+// DecodeHybJSONValue decodes a JSON value into hjv, promoting numbers as
+// BigJSONValue decodes them with these options, except RetainLiterals,
+// and returns hjv.  Results are undefined if error is returned.
+// See HybJSONValue.DecodeJSONValue() for how values are decoded.
+func (opts BigDecodeOptions) DecodeHybJSONValue(hjv *HybJSONValue, text string) (*HybJSONValue, error) {
+	return hjv, hjv.decodeJSONValue([]byte(text), &opts)
+}
+
+// decodeJSONValue decodes a JSON value, promoting numbers using opts.
+func (hjv *HybJSONValue) decodeJSONValue(text []byte, opts *BigDecodeOptions) error {
+	if ns, ok := scanNumber(text); ok {
+		err := hjv.decodeJSONNumber(text, &ns, opts)
+		return newDecodeError(text, 0, hjv.Kind(), err)
+	}
+	builder := hybBuilder{opts: *opts}
+	if err := decodeJSONText(text, &builder); err != nil {
+		*hjv = HybJSONValue{}
+		return err
+	}
+	*hjv = builder.value
+	return nil
+}
+
+// decodeJSONNumber decodes the JSON number in text, already scanned as
+// ns, as a native number, or promoted using opts if it does not fit.
+func (hjv *HybJSONValue) decodeJSONNumber(text []byte, ns *numScan, opts *BigDecodeOptions) error {
+	nat := NatJSONValue{}
+	err := nat.decodeJSONNumber(text, ns, &hybNatDecodeOptions)
+	hjv.proxy = nat.proxy
+	if f64, ok := nat.proxy.(float64); err == strconv.ErrRange || (ok && err == nil && !floatRoundTrips(text, f64)) {
+		err = nil
+		hjv.promoteNumber(text, ns, opts)
+	}
+	return err
+}
+
+// promoteNumber decodes the JSON number in text, already scanned as ns,
+// as BigJSONValue would with opts, without retaining its literal, or as
+// a json.Number if its exponent is too large for that.
+func (hjv *HybJSONValue) promoteNumber(text []byte, ns *numScan, opts *BigDecodeOptions) {
+	bjv := BigJSONValue{}
+	bigOpts := *opts
+	bigOpts.RetainLiterals = false
+	if err := bjv.decodeJSONNumber(text, ns, &bigOpts); err != nil {
+		hjv.proxy = json.Number(text)
+		return
+	}
+	hjv.proxy = bjv.proxy
+}
+
+// hybBuilder builds a HybJSONValue from the parts a valueScanner finds,
+// promoting numbers using opts.
+type hybBuilder struct {
+	opts   BigDecodeOptions
+	value  HybJSONValue    // the top-level value
+	frames []hybBuildFrame // objects and arrays begun and not yet ended
+}
+
+// hybBuildFrame is an object or array being built by a hybBuilder, with
+// the key of its next member if an object.
+type hybBuildFrame struct {
+	obj *hybObject
+	arr []HybJSONValue
+	key string
+}
+
+// add adds value to the innermost object or array, or sets the top-level
+// value if there is none.
+func (hb *hybBuilder) add(value HybJSONValue) {
+	if len(hb.frames) == 0 {
+		hb.value = value
+		return
+	}
+	frame := &hb.frames[len(hb.frames)-1]
+	if frame.obj != nil {
+		frame.obj.set(frame.key, &value)
+	} else {
+		frame.arr = append(frame.arr, value)
+	}
+}
+
+func (hb *hybBuilder) beginObject() {
+	obj := &hybObject{members: make(map[string]*HybJSONValue)}
+	hb.frames = append(hb.frames, hybBuildFrame{obj: obj})
+}
+
+func (hb *hybBuilder) beginArray() {
+	hb.frames = append(hb.frames, hybBuildFrame{arr: []HybJSONValue{}})
+}
+
+func (hb *hybBuilder) key(key string) {
+	hb.frames[len(hb.frames)-1].key = key
+}
+
+func (hb *hybBuilder) end() {
+	frame := hb.frames[len(hb.frames)-1]
+	hb.frames = hb.frames[:len(hb.frames)-1]
+	if frame.obj != nil {
+		hb.add(HybJSONValue{proxy: frame.obj})
+	} else {
+		hb.add(HybJSONValue{proxy: frame.arr})
+	}
+}
+
+func (hb *hybBuilder) scalar(proxy interface{}) {
+	hb.add(HybJSONValue{proxy: proxy})
+}
+
+func (hb *hybBuilder) number(text []byte, ns *numScan) (Kind, error) {
+	var hjv HybJSONValue
+	err := hjv.decodeJSONNumber(text, ns, &hb.opts)
+	hb.add(hjv)
+	return hjv.Kind(), err
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for HybJSONValue.
+//
+// As with BigJSONValue.UnmarshalJSON(), a *DecodeError it returns has
+// Offset and Path relative to the text of the value, not the document.
+func (hjv *HybJSONValue) UnmarshalJSON(text []byte) error {
+	opts := hybBigDecodeOptions
+	return hjv.decodeJSONValue(text, &opts)
+}
@@ -0,0 +1,286 @@
+package bigjsonvalue
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestHybDefaultValueIsNil(t *testing.T) {
+	hjvN := HybJSONValue{}
+	if !hjvN.IsNil() {
+		t.Errorf("hjvN is not nil HybJSONValue")
+	}
+	if hjvN.Kind() != Nil {
+		t.Errorf("hjvN.Kind() unexpectedly returned %s", hjvN.Kind())
+	}
+
+	hjvM := new(HybJSONValue)
+	if !hjvM.IsNil() {
+		t.Errorf("hjvM is not nil HybJSONValue")
+	}
+	if hjvM.Kind() != Nil {
+		t.Errorf("hjvM.Kind() unexpectedly returned %s", hjvM.Kind())
+	}
+}
+
+func TestHybDecodeJSONValue(t *testing.T) {
+	for idx, rec := range testList {
+		// HybJSONValue decodes like NatJSONValue, except where only
+		// NatJSONValue fails, or its float64 has fewer digits
+		hybString, hybKind, hybIsNil, hybErr := rec.natString, rec.natKind, rec.natIsNil, rec.natErr
+		if rec.natErr != nil && rec.bigErr == nil || rec.natKind == Float64 && rec.bigKind == BigFloat && !sameDigits(rec.natString, rec.bigString) {
+			hybString, hybKind, hybIsNil, hybErr = rec.bigString, rec.bigKind, rec.bigIsNil, rec.bigErr
+		}
+
+		hjv := HybJSONValue{}
+		_, err := hjv.DecodeJSONValue(rec.jsonStr)
+		if !errors.Is(err, hybErr) {
+			t.Errorf("%d: Unexpected err=%+v, hybErr=%s, testRec=%+v\n", idx, err, hybErr, rec)
+		}
+		if hjv.Kind() != hybKind {
+			t.Errorf("%d: Unexpected Kind()=%s, testRec=%+v\n", idx, hjv.Kind(), rec)
+		}
+		if hjv.Kind() == Nil && !hjv.IsNil() {
+			t.Errorf("%d: Unexpected IsNil()=%t, testRec=%+v\n", idx, hjv.IsNil(), rec)
+		}
+		if hjv.Kind() == Bool && !hjv.IsBool() {
+			t.Errorf("%d: Unexpected IsBool()=%t, testRec=%+v\n", idx, hjv.IsBool(), rec)
+		}
+		if hjv.Kind() == String && !hjv.IsString() {
+			t.Errorf("%d: Unexpected IsString()=%t, testRec=%+v\n", idx, hjv.IsString(), rec)
+		}
+		if hjv.Kind() == Int64 && !hjv.IsInt64() {
+			t.Errorf("%d: Unexpected IsInt64()=%t, testRec=%+v\n", idx, hjv.IsInt64(), rec)
+		}
+		if hjv.Kind() == Uint64 && !hjv.IsUint64() {
+			t.Errorf("%d: Unexpected IsUint64()=%t, testRec=%+v\n", idx, hjv.IsUint64(), rec)
+		}
+		if hjv.Kind() == Float64 && !hjv.IsFloat64() {
+			t.Errorf("%d: Unexpected IsFloat64()=%t, testRec=%+v\n", idx, hjv.IsFloat64(), rec)
+		}
+		if hjv.Kind() == BigInt && !hjv.IsBigInt() {
+			t.Errorf("%d: Unexpected IsBigInt()=%t, testRec=%+v\n", idx, hjv.IsBigInt(), rec)
+		}
+		if hjv.Kind() == BigFloat && !hjv.IsBigFloat() {
+			t.Errorf("%d: Unexpected IsBigFloat()=%t, testRec=%+v\n", idx, hjv.IsBigFloat(), rec)
+		}
+		if hjv.Kind() == Object && !hjv.IsObject() {
+			t.Errorf("%d: Unexpected IsObject()=%t, testRec=%+v\n", idx, hjv.IsObject(), rec)
+		}
+		if hjv.Kind() == Array && !hjv.IsArray() {
+			t.Errorf("%d: Unexpected IsArray()=%t, testRec=%+v\n", idx, hjv.IsArray(), rec)
+		}
+		if hjv.IsNil() != hybIsNil {
+			t.Errorf("%d: Unexpected IsNil()=%t, testRec=%+v\n", idx, hjv.IsNil(), rec)
+		}
+		if hjv.IsNil() && hjv.Value() != nil {
+			t.Errorf("%d: Unexpected non-nil Value()=%+v, testRec=%+v\n", idx, hjv.Value(), rec)
+		}
+		if !hjv.IsNil() && hjv.Value() == nil {
+			t.Errorf("%d: Unexpected nil Value()=%+v, testRec=%+v\n", idx, hjv.Value(), rec)
+		}
+		if hjv.IsBool() {
+			hjv.Bool() // panics with runtime error if not a bool
+		}
+		if hjv.IsInt64() {
+			hjv.Int64() // panics with runtime error if not a int64
+		}
+		if hjv.IsUint64() {
+			hjv.Uint64() // panics with runtime error if not a uint64
+		}
+		if hjv.IsFloat64() {
+			hjv.Float64() // panics with runtime error if not a float64
+		}
+		if hjv.IsBigInt() {
+			hjv.BigInt() // panics with runtime error if not a big.Int
+		}
+		if hjv.IsBigFloat() {
+			hjv.BigFloat() // panics with runtime error if not a big.Float
+		}
+		if hjv.String() != hybString {
+			t.Errorf("%d: Unexpected String()=%s, testRec=%+v\n", idx, hjv.String(), rec)
+		}
+	}
+}
+
+// sameDigits returns true if the number texts a and b have the same value.
+func sameDigits(a string, b string) bool {
+	bigfA, _, errA := big.ParseFloat(a, 10, 256, big.ToNearestEven)
+	bigfB, _, errB := big.ParseFloat(b, 10, 256, big.ToNearestEven)
+	return errA == nil && errB == nil && bigfA.Cmp(bigfB) == 0
+}
+
+func TestHybPromotePrecision(t *testing.T) {
+	tests := []struct {
+		jsonStr string
+		kind    Kind
+		str     string
+	}{
+		{`0.1`, Float64, "0.1"},
+		{`-1.5e300`, Float64, "-1.5e+300"},
+		{`123456789012345.6`, Float64, "1.234567890123456e+14"},
+		{`0.30000000000000004`, Float64, "0.30000000000000004"},
+		{`1.000000000000000000`, Float64, "1"},
+		{`0e-99999999999999999999`, Float64, "0"},
+		{`987654321.987654321`, BigFloat, "9.87654321987654321e+08"},
+		{`0.1000000000000000055511151231257827`, BigFloat, "0.1000000000000000055511151231257827"},
+		{`1e-400`, BigFloat, "1e-400"},
+		{`1e400`, BigFloat, "1e+400"},
+		{`1e2147483648`, Number, "1e2147483648"},
+	}
+	for idx, test := range tests {
+		hjv, err := new(HybJSONValue).DecodeJSONValue(test.jsonStr)
+		if err != nil || hjv.Kind() != test.kind || hjv.String() != test.str {
+			t.Errorf("%d: Unexpected Kind()=%s, String()=%s, err=%v for %s", idx, hjv.Kind(), hjv.String(), err, test.jsonStr)
+		}
+	}
+
+	hjv, _ := new(HybJSONValue).DecodeJSONValue(`1e2147483648`)
+	if !hjv.IsNumber() || hjv.Number() != "1e2147483648" {
+		t.Errorf("Unexpected Number()=%s", hjv.Number())
+	}
+	if text, err := json.Marshal(hjv); err != nil || string(text) != "1e2147483648" {
+		t.Errorf("Unexpected json.Marshal()=%s, err=%v", text, err)
+	}
+
+	saved := DefaultBigDecodeOptions
+	defer func() { DefaultBigDecodeOptions = saved }()
+	DefaultBigDecodeOptions.Decimal = true
+	DefaultBigDecodeOptions.Prec = 24
+	if hjv, _ = new(HybJSONValue).DecodeJSONValue(`987654321.987654321`); hjv.Kind() != BigFloat || hjv.String() != "9.87654321987654321e+08" {
+		t.Errorf("Unexpected Kind()=%s, String()=%s with changed DefaultBigDecodeOptions", hjv.Kind(), hjv.String())
+	}
+	opts := BigDecodeOptions{Decimal: true}
+	hjv, _ = opts.DecodeHybJSONValue(new(HybJSONValue), `987654321.987654321`)
+	if !hjv.IsDecimal() || hjv.Scale() != 9 || hjv.String() != "987654321.987654321" {
+		t.Errorf("Unexpected Kind()=%s, String()=%s", hjv.Kind(), hjv.String())
+	}
+	unscaled := hjv.Unscaled()
+	if unscaled.String() != "987654321987654321" {
+		t.Errorf("Unexpected Unscaled()=%s", unscaled.String())
+	}
+	if rat, err := hjv.Rat(); err != nil || rat.RatString() != "987654321987654321/1000000000" {
+		t.Errorf("Unexpected Rat()=%v, err=%v", rat, err)
+	}
+	if hjv, _ = opts.DecodeHybJSONValue(new(HybJSONValue), `19.99`); !hjv.IsFloat64() {
+		t.Errorf("Unexpected Kind()=%s for 19.99", hjv.Kind())
+	}
+}
+
+func TestHybDecodeComposite(t *testing.T) {
+	jsonStr := `{ "id": 18446744073709551616, "small": 42, "neg": -7,
+		"vals": [ 1.5, 1e400, "x", null, { "deep": [ true ] } ] }`
+
+	hjv, err := new(HybJSONValue).DecodeJSONValue(jsonStr)
+	if err != nil {
+		t.Fatalf("DecodeJSONValue err=%s", err)
+	}
+	if keys := strings.Join(hjv.Keys(), ","); keys != "id,small,neg,vals" || hjv.Len() != 4 {
+		t.Errorf("Unexpected Keys()=%s, Len()=%d", keys, hjv.Len())
+	}
+	expectedKinds := map[string]Kind{"id": BigInt, "small": Uint64, "neg": Int64, "vals": Array}
+	for key, kind := range expectedKinds {
+		if member, ok := hjv.Member(key); !ok || member.Kind() != kind {
+			t.Errorf("Unexpected Member(%s) Kind()=%s, ok=%t", key, member.Kind(), ok)
+		}
+	}
+	vals, _ := hjv.Member("vals")
+	expectedElemKinds := []Kind{Float64, BigFloat, String, Nil, Object}
+	vals.Range(func(idx int, elem *HybJSONValue) bool {
+		if elem.Kind() != expectedElemKinds[idx] {
+			t.Errorf("Unexpected vals[%d] Kind()=%s", idx, elem.Kind())
+		}
+		return true
+	})
+	if deep, ok := vals.Index(4).Member("deep"); !ok || deep.Index(0).Bool() != true {
+		t.Errorf("Unexpected deep member=%s", deep)
+	}
+	if vals.Index(5) != nil || hjv.Index(0) != nil {
+		t.Errorf("Unexpected non-nil Index()")
+	}
+	expected := `{"id":18446744073709551616,"small":42,"neg":-7,"vals":[1.5,1e+400,"x",null,{"deep":[true]}]}`
+	if text, err := json.Marshal(hjv); err != nil || string(text) != expected {
+		t.Errorf("json.Marshal=%s, err=%v", text, err)
+	}
+}
+
+func TestHybAccessorCopies(t *testing.T) {
+	hjv, _ := new(HybJSONValue).DecodeJSONValue(`[ 123456789012345678901234567890, 1e400 ]`)
+	bigi := hjv.Index(0).BigInt()
+	bigi.SetInt64(0)
+	bigf := hjv.Index(1).BigFloat()
+	bigf.SetInt64(0)
+	if str := hjv.String(); str != "[123456789012345678901234567890,1e+400]" {
+		t.Errorf("Changing BigInt() or BigFloat() copy changed value to %s", str)
+	}
+	if hjv.Index(0).BigIntRef() != hjv.Index(0).BigIntRef() || hjv.Index(1).BigFloatRef() != hjv.Index(1).BigFloatRef() {
+		t.Errorf("BigIntRef() or BigFloatRef() does not return the stored value")
+	}
+}
+
+type hybWalChangeRec struct {
+	ColumnValues []HybJSONValue `json:"columnvalues"`
+}
+
+func TestHybUnmarshalJSON(t *testing.T) {
+	jsonStr := `{"columnvalues":[null,true,"a\\b\\c new\nline",987654321987654321,` +
+		`-987654321987654321,3.14,18446744073709551616,-1e+400,{"a":[1]},[]]}`
+
+	expectedKinds := []Kind{
+		Nil,
+		Bool,
+		String,
+		Uint64,
+		Int64,
+		Float64,
+		BigInt,
+		BigFloat,
+		Object,
+		Array,
+	}
+
+	var hybRec hybWalChangeRec
+	err := json.Unmarshal([]byte(jsonStr), &hybRec)
+	if err != nil {
+		t.Fatalf("json.Unmarshal err=%s\n", err)
+	}
+	for idx, hjv := range hybRec.ColumnValues {
+		if hjv.Kind() != expectedKinds[idx] {
+			t.Errorf("json.Unmarshal HybJSONValue <%s> is of kind %s, does not match expectedKinds[%d]=%s",
+				hjv.String(), hjv.Kind(), idx, expectedKinds[idx])
+		}
+	}
+	if text, err := json.Marshal(hybRec); err != nil || string(text) != jsonStr {
+		t.Errorf("json.Marshal=%s, err=%v", text, err)
+	}
+}
+
+func BenchmarkHybDecodeJSONNumbers(b *testing.B) {
+	hjv := HybJSONValue{}
+	for n := 0; n < b.N; n++ {
+		hjv.DecodeJSONValue(`987654321987654321`)
+		hjv.DecodeJSONValue(`987654321.987654321`)
+	}
+}
+
+func BenchmarkHybUnmarshalJSONNumbers(b *testing.B) {
+	b.ReportAllocs()
+	hjv := HybJSONValue{}
+	for n := 0; n < b.N; n++ {
+		for _, text := range benchNumberList {
+			hjv.UnmarshalJSON(text)
+		}
+	}
+}
+
+func BenchmarkHybUnmarshalJSONBigNumbers(b *testing.B) {
+	b.ReportAllocs()
+	hjv := HybJSONValue{}
+	text := []byte(`-98765432198765432198765432`)
+	for n := 0; n < b.N; n++ {
+		hjv.UnmarshalJSON(text)
+	}
+}
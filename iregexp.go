@@ -0,0 +1,235 @@
+package bigjsonvalue
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// compileIRegexp compiles an I-Regexp pattern, per RFC 9485, as a Go
+// regexp matching whole strings if whole is true, or substrings
+// otherwise.  Returns an error for any pattern outside the I-Regexp
+// grammar, even if Go would accept it, e.g. flags such as "(?i)",
+// non-greedy quantifiers, and escapes such as "\z" or "\d".
+//
+// The pattern is translated so Go matches it as I-Regexp does: "."
+// outside character classes matches any character but "\n" and "\r",
+// and "^" and "$" match themselves rather than the start and end.  The
+// unassigned category \p{Cn} is not supported, as Go has no table for
+// it.
+func compileIRegexp(pattern string, whole bool) (*regexp.Regexp, error) {
+	rp := iRegexpParser{pattern: pattern}
+	if err := rp.parseRegexp(); err != nil {
+		return nil, err
+	} else if rp.pos < len(pattern) {
+		return nil, rp.errorf("unexpected %q", rp.pattern[rp.pos])
+	}
+	if whole {
+		return regexp.Compile(`^(?:` + rp.buf.String() + `)$`)
+	}
+	return regexp.Compile(rp.buf.String())
+}
+
+// iRegexpParser translates I-Regexp patterns to Go regexp syntax, per
+// the RFC 9485 grammar.
+type iRegexpParser struct {
+	pattern string
+	pos     int
+	buf     strings.Builder
+}
+
+// errorf returns an error at the current position.
+func (rp *iRegexpParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s at offset %d of pattern", fmt.Sprintf(format, args...), rp.pos)
+}
+
+// peek returns the next byte, or 0 at the end of the pattern.
+func (rp *iRegexpParser) peek() byte {
+	if rp.pos < len(rp.pattern) {
+		return rp.pattern[rp.pos]
+	}
+	return 0
+}
+
+// parseRegexp parses branches separated by "|".
+func (rp *iRegexpParser) parseRegexp() error {
+	for {
+		for rp.pos < len(rp.pattern) && rp.peek() != '|' && rp.peek() != ')' {
+			if err := rp.parsePiece(); err != nil {
+				return err
+			}
+		}
+		if rp.peek() != '|' {
+			return nil
+		}
+		rp.buf.WriteByte('|')
+		rp.pos++
+	}
+}
+
+// parsePiece parses an atom and an optional quantifier.
+func (rp *iRegexpParser) parsePiece() error {
+	if err := rp.parseAtom(); err != nil {
+		return err
+	}
+	switch rp.peek() {
+	case '*', '+', '?':
+		rp.buf.WriteByte(rp.peek())
+		rp.pos++
+	case '{':
+		start := rp.pos
+		rp.pos++
+		if !rp.skipDigits() {
+			return rp.errorf("expected digits")
+		} else if rp.peek() == ',' {
+			rp.pos++
+			rp.skipDigits()
+		}
+		if rp.peek() != '}' {
+			return rp.errorf("expected '}'")
+		}
+		rp.pos++
+		rp.buf.WriteString(rp.pattern[start:rp.pos])
+	}
+	return nil
+}
+
+// skipDigits advances past decimal digits, and returns true if there
+// were any.
+func (rp *iRegexpParser) skipDigits() bool {
+	start := rp.pos
+	for rp.peek() >= '0' && rp.peek() <= '9' {
+		rp.pos++
+	}
+	return rp.pos > start
+}
+
+// parseAtom parses a character, character class or parenthesized
+// pattern.
+func (rp *iRegexpParser) parseAtom() error {
+	switch ch := rp.peek(); ch {
+	case '(':
+		rp.pos++
+		rp.buf.WriteString("(?:")
+		if err := rp.parseRegexp(); err != nil {
+			return err
+		} else if rp.peek() != ')' {
+			return rp.errorf("expected ')'")
+		}
+		rp.pos++
+		rp.buf.WriteByte(')')
+	case '.':
+		rp.pos++
+		rp.buf.WriteString(`[^\n\r]`)
+	case '[':
+		return rp.parseClass()
+	case '\\':
+		_, err := rp.parseEscape()
+		return err
+	case ')', '*', '+', '?', ']', '{', '|', '}':
+		return rp.errorf("unexpected %q", ch)
+	default:
+		_, size := utf8.DecodeRuneInString(rp.pattern[rp.pos:])
+		rp.buf.WriteString(regexp.QuoteMeta(rp.pattern[rp.pos : rp.pos+size]))
+		rp.pos += size
+	}
+	return nil
+}
+
+// parseEscape parses an escape starting with a backslash, returning
+// true if it is a single character, so may start or end a range in a
+// character class.
+func (rp *iRegexpParser) parseEscape() (bool, error) {
+	rp.pos++
+	switch ch := rp.peek(); ch {
+	case '(', ')', '*', '+', '-', '.', '?', '[', '\\', ']', '^', '{', '|', '}', 'n', 'r', 't':
+		rp.buf.WriteByte('\\')
+		rp.buf.WriteByte(ch)
+		rp.pos++
+		return true, nil
+	case 'p', 'P':
+		rp.pos++
+		if rp.peek() != '{' {
+			return false, rp.errorf("expected '{'")
+		}
+		end := strings.IndexByte(rp.pattern[rp.pos:], '}')
+		if end < 0 || !isIRegexpCategory(rp.pattern[rp.pos+1:rp.pos+end]) {
+			return false, rp.errorf("invalid category")
+		}
+		rp.buf.WriteString(rp.pattern[rp.pos-2 : rp.pos+end+1])
+		rp.pos += end + 1
+		return false, nil
+	}
+	return false, rp.errorf("invalid escape")
+}
+
+// parseClass parses a character class expression in square brackets.
+func (rp *iRegexpParser) parseClass() error {
+	rp.pos++
+	rp.buf.WriteByte('[')
+	if rp.peek() == '^' {
+		rp.pos++
+		rp.buf.WriteByte('^')
+	}
+	for count := 0; ; count++ {
+		switch ch := rp.peek(); {
+		case rp.pos >= len(rp.pattern):
+			return rp.errorf("expected ']'")
+		case ch == ']' && count > 0:
+			rp.pos++
+			rp.buf.WriteByte(']')
+			return nil
+		case ch == '-' && (count == 0 || rp.pos+1 < len(rp.pattern) && rp.pattern[rp.pos+1] == ']'):
+			rp.pos++
+			rp.buf.WriteString(`\-`)
+		default:
+			single, err := rp.parseClassChar()
+			if err != nil {
+				return err
+			} else if single && rp.peek() == '-' && rp.pos+1 < len(rp.pattern) && rp.pattern[rp.pos+1] != ']' {
+				rp.pos++
+				rp.buf.WriteByte('-')
+				if single, err = rp.parseClassChar(); err != nil {
+					return err
+				} else if !single {
+					return rp.errorf("invalid range")
+				}
+			}
+		}
+	}
+}
+
+// parseClassChar parses a character or escape in a character class,
+// returning true if it is a single character.
+func (rp *iRegexpParser) parseClassChar() (bool, error) {
+	switch ch := rp.peek(); ch {
+	case '\\':
+		return rp.parseEscape()
+	case '-', '[', ']':
+		return false, rp.errorf("unexpected %q", ch)
+	}
+	_, size := utf8.DecodeRuneInString(rp.pattern[rp.pos:])
+	if rp.pattern[rp.pos] == '^' {
+		rp.buf.WriteByte('\\')
+	}
+	rp.buf.WriteString(rp.pattern[rp.pos : rp.pos+size])
+	rp.pos += size
+	return true, nil
+}
+
+// iRegexpCategories maps the first letter of each Unicode general
+// category that I-Regexp allows to the second letters it allows.
+var iRegexpCategories = map[byte]string{
+	'L': "lmotu", 'M': "cen", 'N': "dlo", 'P': "cdefios", 'Z': "lps", 'S': "ckmo", 'C': "cfo",
+}
+
+// isIRegexpCategory returns true if name is a Unicode general category
+// that I-Regexp allows and Go supports, which is all but "Cn".
+func isIRegexpCategory(name string) bool {
+	if len(name) == 0 || len(name) > 2 {
+		return false
+	}
+	allowed, ok := iRegexpCategories[name[0]]
+	return ok && (len(name) == 1 || strings.IndexByte(allowed, name[1]) >= 0)
+}
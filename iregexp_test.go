@@ -0,0 +1,79 @@
+package bigjsonvalue
+
+import (
+	"testing"
+)
+
+var iRegexpList = []struct {
+	pattern string
+	whole   bool
+	matches []string
+	misses  []string
+}{
+	{`a.c`, true, []string{"abc", "aéc"}, []string{"a\nc", "a\rc", "xabc"}},
+	{`a.c`, false, []string{"xabcx"}, []string{"ac"}},
+	{`^a$`, false, []string{"^a$", "x^a$x"}, []string{"a"}},
+	{`[^a-c]x|y{2,3}`, true, []string{"dx", "yy", "yyy"}, []string{"ax", "y", "yyyy"}},
+	{`[-a]+[b-]\.`, true, []string{"-a-b.", "aa-."}, []string{"ab-x"}},
+	{`[\p{Lu}\-]\P{L}`, true, []string{"A1", "-1"}, []string{"a1", "AB"}},
+	{`\p{Nd}{3}`, true, []string{"123"}, []string{"12"}},
+	{`(a|b)*\(\n\t\)`, true, []string{"abba(\n\t)"}, []string{"c(\n\t)"}},
+	{`[.^]`, true, []string{".", "^"}, []string{"a"}},
+}
+
+func TestIRegexp(t *testing.T) {
+	for idx, rec := range iRegexpList {
+		re, err := compileIRegexp(rec.pattern, rec.whole)
+		if err != nil {
+			t.Errorf("%d: compileIRegexp(%s) err=%v", idx, rec.pattern, err)
+			continue
+		}
+		for _, str := range rec.matches {
+			if !re.MatchString(str) {
+				t.Errorf("%d: %s does not match %q", idx, rec.pattern, str)
+			}
+		}
+		for _, str := range rec.misses {
+			if re.MatchString(str) {
+				t.Errorf("%d: %s matches %q", idx, rec.pattern, str)
+			}
+		}
+	}
+}
+
+func TestIRegexpInvalid(t *testing.T) {
+	invalidList := []string{
+		`(?i)a`,
+		`(?:a)`,
+		`a*?`,
+		`a**`,
+		`a{,2}`,
+		`a{2`,
+		`a\z`,
+		`\d`,
+		`\w+`,
+		`\b`,
+		`\x41`,
+		`\`,
+		`a)`,
+		`(a`,
+		`[]`,
+		`[^]`,
+		`[a`,
+		`[a-b-c]`,
+		`[[:alpha:]]`,
+		`[\p{L}-z]`,
+		`\p{Cn}`,
+		`\p{Lx}`,
+		`\p{IsLatin}`,
+		`\pL`,
+		`*`,
+		`{1}`,
+		`a|}`,
+	}
+	for _, pattern := range invalidList {
+		if _, err := compileIRegexp(pattern, false); err == nil {
+			t.Errorf("compileIRegexp(%s) did not fail", pattern)
+		}
+	}
+}
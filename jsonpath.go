@@ -0,0 +1,1163 @@
+package bigjsonvalue
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// maxPathInt is the largest magnitude of an index, slice or number in a
+// JSONPath query that RFC 9535 allows, the I-JSON exact integer range.
+const maxPathInt = 1<<53 - 1
+
+// JSONPathError reports why a JSONPath query could not be compiled.
+type JSONPathError struct {
+	Query  string // query being compiled
+	Offset int    // byte offset of the offending text
+	Detail string // what was wrong, e.g. "expected ']'"
+}
+
+// Error implements the error interface for JSONPathError.
+func (e *JSONPathError) Error() string {
+	return fmt.Sprintf("cannot compile %q at offset %d: %s", e.Query, e.Offset, e.Detail)
+}
+
+// Unwrap returns ErrInvalidJSONPath, so errors.Is(err, ErrInvalidJSONPath)
+// works on a JSONPathError.
+func (e *JSONPathError) Unwrap() error {
+	return ErrInvalidJSONPath
+}
+
+// JSONPath is a compiled JSONPath query, per RFC 9535, that selects
+// values from BigJSONValue trees.  It is safe for concurrent use.
+//
+// Supported are child and descendant segments (".name", "[...]", "..");
+// name, wildcard, index, slice and filter selectors; and in filters, the
+// logical operators "||", "&&" and "!", the comparison operators "==",
+// "!=", "<", "<=", ">" and ">=", and the functions length(), count(),
+// match(), search() and value().  Patterns for match() and search() are
+// I-Regexp, per RFC 9485; a literal pattern that is not is a compile
+// error, while any other pattern that is not matches nothing.
+//
+// Numbers are compared exactly, e.g. $[?@.id > 9007199254740992] selects
+// 9007199254740993, which a float64 cannot tell apart from 9007199254740992.
+// Number literals with a fraction or exponent, e.g. 19.99, that are
+// compared with a BigFloat value are first rounded to its precision, as
+// decoding the literal would have rounded it, so that they compare equal
+// to BigFloat values decoded from the same text.
+type JSONPath struct {
+	query string
+	root  *pathQuery
+}
+
+// CompileJSONPath compiles a JSONPath query, e.g. "$.store.book[?@.price < 10].title".
+// Returns a *JSONPathError if query is not a well-formed and well-typed
+// JSONPath query.
+func CompileJSONPath(query string) (*JSONPath, error) {
+	parser := pathParser{query: query}
+	root, err := parser.parseQuery()
+	if err == nil && parser.pos < len(query) {
+		err = parser.errorf("unexpected %q", parser.rest())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &JSONPath{query: query, root: root}, nil
+}
+
+// MustCompileJSONPath is like CompileJSONPath() but panics if query
+// cannot be compiled.
+func MustCompileJSONPath(query string) *JSONPath {
+	jp, err := CompileJSONPath(query)
+	if err != nil {
+		panic(err)
+	}
+	return jp
+}
+
+// String returns the query the JSONPath was compiled from.
+func (jp *JSONPath) String() string {
+	return jp.query
+}
+
+// Select returns the values selected by the query from bjv, in the order
+// RFC 9535 selects them, with object members in document order.  The
+// values returned are not copies, so changing them changes bjv.
+func (jp *JSONPath) Select(bjv *BigJSONValue) []*BigJSONValue {
+	nodes := jp.root.eval(bjv, nil)
+	values := make([]*BigJSONValue, len(nodes))
+	for idx, node := range nodes {
+		values[idx] = node.value
+	}
+	return values
+}
+
+// SelectPaths returns the normalized paths of the values Select() would
+// return, e.g. "$['tags'][0]".
+func (jp *JSONPath) SelectPaths(bjv *BigJSONValue) []string {
+	nodes := jp.root.eval(bjv, nil)
+	paths := make([]string, len(nodes))
+	for idx, node := range nodes {
+		paths[idx] = node.path()
+	}
+	return paths
+}
+
+// pathNode is a value selected by a query, with the node it was
+// selected from, so that its normalized path can be built.
+type pathNode struct {
+	value  *BigJSONValue
+	parent *pathNode
+	key    string // member key, if parent is an object
+	idx    int    // element index, if parent is an array
+}
+
+// path returns the normalized path of the node, per RFC 9535.
+func (node *pathNode) path() string {
+	if node.parent == nil {
+		return "$"
+	}
+	buf := []byte(node.parent.path())
+	if _, ok := node.parent.value.proxy.([]BigJSONValue); ok {
+		buf = append(buf, '[')
+		buf = strconv.AppendInt(buf, int64(node.idx), 10)
+		return string(append(buf, ']'))
+	}
+	buf = append(buf, "['"...)
+	for _, r := range node.key {
+		switch r {
+		case '\b':
+			buf = append(buf, `\b`...)
+		case '\f':
+			buf = append(buf, `\f`...)
+		case '\n':
+			buf = append(buf, `\n`...)
+		case '\r':
+			buf = append(buf, `\r`...)
+		case '\t':
+			buf = append(buf, `\t`...)
+		case '\'', '\\':
+			buf = append(buf, '\\', byte(r))
+		default:
+			if r < 0x20 {
+				buf = append(buf, fmt.Sprintf(`\u%04x`, r)...)
+			} else {
+				buf = append(buf, string(r)...)
+			}
+		}
+	}
+	return string(append(buf, "']"...))
+}
+
+// children appends the member or element nodes of node to out.
+func (node *pathNode) children(out []*pathNode) []*pathNode {
+	switch node.value.proxy.(type) {
+	case *bigObject:
+		obj := node.value.proxy.(*bigObject)
+		for _, key := range obj.keys {
+			out = append(out, &pathNode{value: obj.members[key], parent: node, key: key})
+		}
+	case []BigJSONValue:
+		arr := node.value.proxy.([]BigJSONValue)
+		for idx := range arr {
+			out = append(out, &pathNode{value: &arr[idx], parent: node, idx: idx})
+		}
+	}
+	return out
+}
+
+// pathQuery is a compiled absolute ("$") or relative ("@") query.
+type pathQuery struct {
+	relative bool
+	segments []pathSegment
+	singular bool // only single name or index selectors
+}
+
+// eval returns the nodes selected by the query, starting from root, or
+// from current for relative queries.
+func (q *pathQuery) eval(root *BigJSONValue, current *BigJSONValue) []*pathNode {
+	start := root
+	if q.relative {
+		start = current
+	}
+	nodes := []*pathNode{{value: start}}
+	for idx := range q.segments {
+		if len(nodes) == 0 {
+			break
+		}
+		nodes = q.segments[idx].apply(root, nodes)
+	}
+	return nodes
+}
+
+// pathSegment is a compiled child or descendant segment.
+type pathSegment struct {
+	descendant bool
+	selectors  []pathSelector
+}
+
+// apply returns the nodes the segment selects from nodes.
+func (seg *pathSegment) apply(root *BigJSONValue, nodes []*pathNode) []*pathNode {
+	var out []*pathNode
+	for _, node := range nodes {
+		if seg.descendant {
+			out = seg.descend(root, node, out)
+		} else {
+			out = seg.selectFrom(root, node, out)
+		}
+	}
+	return out
+}
+
+// selectFrom appends the nodes each selector selects from node to out.
+func (seg *pathSegment) selectFrom(root *BigJSONValue, node *pathNode, out []*pathNode) []*pathNode {
+	for idx := range seg.selectors {
+		out = seg.selectors[idx].selectFrom(root, node, out)
+	}
+	return out
+}
+
+// descend appends the nodes the selectors select from node and all its
+// descendants to out, in pre-order.
+func (seg *pathSegment) descend(root *BigJSONValue, node *pathNode, out []*pathNode) []*pathNode {
+	out = seg.selectFrom(root, node, out)
+	for _, child := range node.children(nil) {
+		out = seg.descend(root, child, out)
+	}
+	return out
+}
+
+// selectorKind enumerates the kinds of selector.
+type selectorKind int
+
+const (
+	nameSelector selectorKind = iota
+	wildcardSelector
+	indexSelector
+	sliceSelector
+	filterSelector
+)
+
+// pathSelector is a compiled selector.
+type pathSelector struct {
+	kind   selectorKind
+	name   string
+	index  int64
+	slice  [3]int64 // start, end and step
+	bounds [3]bool  // true if slice start, end or step is present
+	filter pathLogical
+}
+
+// selectFrom appends the nodes the selector selects from node to out.
+func (sel *pathSelector) selectFrom(root *BigJSONValue, node *pathNode, out []*pathNode) []*pathNode {
+	switch sel.kind {
+	case nameSelector:
+		if obj, ok := node.value.proxy.(*bigObject); ok {
+			if member, ok := obj.members[sel.name]; ok {
+				out = append(out, &pathNode{value: member, parent: node, key: sel.name})
+			}
+		}
+	case wildcardSelector:
+		out = node.children(out)
+	case indexSelector:
+		arr, _ := node.value.proxy.([]BigJSONValue)
+		idx := sel.index
+		if idx < 0 {
+			idx += int64(len(arr))
+		}
+		if idx >= 0 && idx < int64(len(arr)) {
+			out = append(out, &pathNode{value: &arr[idx], parent: node, idx: int(idx)})
+		}
+	case sliceSelector:
+		if arr, ok := node.value.proxy.([]BigJSONValue); ok {
+			out = sel.selectSlice(node, arr, out)
+		}
+	case filterSelector:
+		for _, child := range node.children(nil) {
+			if sel.filter.test(root, child.value) {
+				out = append(out, child)
+			}
+		}
+	}
+	return out
+}
+
+// selectSlice appends the elements of arr the slice selects to out, per
+// RFC 9535 section 2.3.4.2.2.
+func (sel *pathSelector) selectSlice(node *pathNode, arr []BigJSONValue, out []*pathNode) []*pathNode {
+	n := int64(len(arr))
+	step := int64(1)
+	if sel.bounds[2] {
+		step = sel.slice[2]
+	}
+	normalize := func(idx int64) int64 {
+		if idx < 0 {
+			return n + idx
+		}
+		return idx
+	}
+	clamp := func(idx int64, lo int64, hi int64) int64 {
+		if idx < lo {
+			return lo
+		} else if idx > hi {
+			return hi
+		}
+		return idx
+	}
+	switch {
+	case step > 0:
+		start, end := int64(0), n
+		if sel.bounds[0] {
+			start = normalize(sel.slice[0])
+		}
+		if sel.bounds[1] {
+			end = normalize(sel.slice[1])
+		}
+		for idx := clamp(start, 0, n); idx < clamp(end, 0, n); idx += step {
+			out = append(out, &pathNode{value: &arr[idx], parent: node, idx: int(idx)})
+		}
+	case step < 0:
+		start, end := n-1, -n-1
+		if sel.bounds[0] {
+			start = normalize(sel.slice[0])
+		}
+		if sel.bounds[1] {
+			end = normalize(sel.slice[1])
+		}
+		for idx := clamp(start, -1, n-1); clamp(end, -1, n-1) < idx; idx += step {
+			out = append(out, &pathNode{value: &arr[idx], parent: node, idx: int(idx)})
+		}
+	}
+	return out
+}
+
+// pathLogical is a compiled filter expression of LogicalType.
+type pathLogical interface {
+	test(root *BigJSONValue, current *BigJSONValue) bool
+}
+
+// pathValuer is a compiled filter expression of ValueType, whose value is
+// nil for Nothing.
+type pathValuer interface {
+	value(root *BigJSONValue, current *BigJSONValue) *BigJSONValue
+}
+
+// pathOr is a "||" expression.
+type pathOr []pathLogical
+
+func (expr pathOr) test(root *BigJSONValue, current *BigJSONValue) bool {
+	for _, operand := range expr {
+		if operand.test(root, current) {
+			return true
+		}
+	}
+	return false
+}
+
+// pathAnd is a "&&" expression.
+type pathAnd []pathLogical
+
+func (expr pathAnd) test(root *BigJSONValue, current *BigJSONValue) bool {
+	for _, operand := range expr {
+		if !operand.test(root, current) {
+			return false
+		}
+	}
+	return true
+}
+
+// pathNot is a "!" expression.
+type pathNot struct {
+	operand pathLogical
+}
+
+func (expr pathNot) test(root *BigJSONValue, current *BigJSONValue) bool {
+	return !expr.operand.test(root, current)
+}
+
+// pathExists is a query used as a test, true if it selects any node.
+type pathExists struct {
+	query *pathQuery
+}
+
+func (expr pathExists) test(root *BigJSONValue, current *BigJSONValue) bool {
+	return len(expr.query.eval(root, current)) > 0
+}
+
+// pathLiteral is a literal value.
+type pathLiteral struct {
+	literal *BigJSONValue
+}
+
+func (expr pathLiteral) value(root *BigJSONValue, current *BigJSONValue) *BigJSONValue {
+	return expr.literal
+}
+
+// pathSingular is a singular query used as a value, which is Nothing
+// unless it selects a node.
+type pathSingular struct {
+	query *pathQuery
+}
+
+func (expr pathSingular) value(root *BigJSONValue, current *BigJSONValue) *BigJSONValue {
+	if nodes := expr.query.eval(root, current); len(nodes) == 1 {
+		return nodes[0].value
+	}
+	return nil
+}
+
+// pathComparison is a comparison expression.
+type pathComparison struct {
+	op          string
+	left, right pathValuer
+}
+
+func (expr pathComparison) test(root *BigJSONValue, current *BigJSONValue) bool {
+	left := expr.left.value(root, current)
+	right := expr.right.value(root, current)
+	if lit, ok := expr.left.(pathLiteral); ok {
+		left = roundLiteral(lit.literal, right)
+	} else if lit, ok := expr.right.(pathLiteral); ok {
+		right = roundLiteral(lit.literal, left)
+	}
+	switch expr.op {
+	case "==":
+		return pathEqual(left, right)
+	case "!=":
+		return !pathEqual(left, right)
+	case "<":
+		return pathLess(left, right)
+	case "<=":
+		return pathLess(left, right) || pathEqual(left, right)
+	case ">":
+		return pathLess(right, left)
+	default:
+		return pathLess(right, left) || pathEqual(left, right)
+	}
+}
+
+// roundLiteral returns a Decimal literal rounded to the precision and
+// mode of other if other is a BigFloat, otherwise returns literal as-is.
+func roundLiteral(literal *BigJSONValue, other *BigJSONValue) *BigJSONValue {
+	dec, ok := literal.proxy.(bigDecimal)
+	if other == nil || !ok {
+		return literal
+	}
+	bigf, ok := other.proxy.(*big.Float)
+	if !ok {
+		return literal
+	}
+	rounded := new(big.Float).SetPrec(bigf.Prec()).SetMode(bigf.Mode())
+	if rat, err := numberRat(dec); err == nil {
+		rounded.SetRat(rat)
+	} else {
+		rounded.Set(approxNumber(numberText(dec)))
+	}
+	return &BigJSONValue{proxy: rounded}
+}
+
+// pathEqual returns true if a and b are equal, or both Nothing.
+func pathEqual(a *BigJSONValue, b *BigJSONValue) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return equalProxies(a.proxy, b.proxy)
+}
+
+// pathLess returns true if a and b are both numbers or both strings, and
+// a is less than b.
+func pathLess(a *BigJSONValue, b *BigJSONValue) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	kindA, kindB := proxyKind(a.proxy), proxyKind(b.proxy)
+	if kindA == String && kindB == String {
+		return a.proxy.(string) < b.proxy.(string)
+	}
+	return kindRank(kindA) == 2 && kindRank(kindB) == 2 && compareNumbers(a.proxy, b.proxy) < 0
+}
+
+// pathType enumerates the types of RFC 9535 function parameters and
+// results.
+type pathType int
+
+const (
+	valueType pathType = iota
+	logicalType
+	nodesType
+)
+
+// pathFuncTypes holds the parameter and result types of each function.
+var pathFuncTypes = map[string]struct {
+	params []pathType
+	result pathType
+}{
+	"length": {[]pathType{valueType}, valueType},
+	"count":  {[]pathType{nodesType}, valueType},
+	"match":  {[]pathType{valueType, valueType}, logicalType},
+	"search": {[]pathType{valueType, valueType}, logicalType},
+	"value":  {[]pathType{nodesType}, valueType},
+}
+
+// pathFunction is a function expression.
+type pathFunction struct {
+	name   string
+	args   []pathOperand
+	result pathType
+	re     *regexp.Regexp // compiled match() or search() literal pattern
+}
+
+func (expr *pathFunction) value(root *BigJSONValue, current *BigJSONValue) *BigJSONValue {
+	switch expr.name {
+	case "length":
+		switch arg := expr.args[0].valueOf(root, current); proxyKind(arg.proxyOrNil()) {
+		case String:
+			return &BigJSONValue{proxy: big.NewInt(int64(utf8.RuneCountInString(arg.proxy.(string))))}
+		case Object, Array:
+			return &BigJSONValue{proxy: big.NewInt(int64(arg.Len()))}
+		}
+	case "count":
+		return &BigJSONValue{proxy: big.NewInt(int64(len(expr.args[0].query.eval(root, current))))}
+	case "value":
+		if nodes := expr.args[0].query.eval(root, current); len(nodes) == 1 {
+			return nodes[0].value
+		}
+	}
+	return nil
+}
+
+func (expr *pathFunction) test(root *BigJSONValue, current *BigJSONValue) bool {
+	str, ok := expr.args[0].valueOf(root, current).proxyOrNil().(string)
+	if !ok {
+		return false
+	}
+	re := expr.re
+	if re == nil {
+		pattern, ok := expr.args[1].valueOf(root, current).proxyOrNil().(string)
+		if !ok {
+			return false
+		}
+		var err error
+		if re, err = compileIRegexp(pattern, expr.name == "match"); err != nil {
+			return false
+		}
+	}
+	return re.MatchString(str)
+}
+
+// proxyOrNil returns the proxy value of bjv, or nil for Nothing.
+func (bjv *BigJSONValue) proxyOrNil() interface{} {
+	if bjv == nil {
+		return nil
+	}
+	return bjv.proxy
+}
+
+// operandKind enumerates the kinds of pathOperand.
+type operandKind int
+
+const (
+	literalOperand operandKind = iota
+	queryOperand
+	functionOperand
+	logicalOperand
+)
+
+// pathOperand is a parsed filter expression, before it is known whether
+// it is used as a value, a test, or a function argument.
+type pathOperand struct {
+	kind     operandKind
+	literal  *BigJSONValue
+	query    *pathQuery
+	function *pathFunction
+	logical  pathLogical
+}
+
+// valueOf returns the value of a literal, singular query or ValueType
+// function operand.
+func (operand *pathOperand) valueOf(root *BigJSONValue, current *BigJSONValue) *BigJSONValue {
+	return operand.valuer().value(root, current)
+}
+
+// valuer returns the operand as a ValueType expression, or nil if it is
+// not one.
+func (operand *pathOperand) valuer() pathValuer {
+	switch {
+	case operand.kind == literalOperand:
+		return pathLiteral{operand.literal}
+	case operand.kind == queryOperand && operand.query.singular:
+		return pathSingular{operand.query}
+	case operand.kind == functionOperand && operand.function.result == valueType:
+		return operand.function
+	default:
+		return nil
+	}
+}
+
+// tester returns the operand as a LogicalType expression, with queries
+// converted to existence tests, or nil if it is not one.
+func (operand *pathOperand) tester() pathLogical {
+	switch {
+	case operand.kind == logicalOperand:
+		return operand.logical
+	case operand.kind == queryOperand:
+		return pathExists{operand.query}
+	case operand.kind == functionOperand && operand.function.result == logicalType:
+		return operand.function
+	default:
+		return nil
+	}
+}
+
+// pathParser parses JSONPath queries, per the RFC 9535 grammar.
+type pathParser struct {
+	query string
+	pos   int
+}
+
+// errorf returns a JSONPathError at the current position.
+func (p *pathParser) errorf(format string, args ...interface{}) error {
+	return &JSONPathError{Query: p.query, Offset: p.pos, Detail: fmt.Sprintf(format, args...)}
+}
+
+// rest returns the unparsed text, truncated for error messages.
+func (p *pathParser) rest() string {
+	return snippet([]byte(p.query[p.pos:]))
+}
+
+// peek returns the next byte, or 0 at the end of the query.
+func (p *pathParser) peek() byte {
+	if p.pos < len(p.query) {
+		return p.query[p.pos]
+	}
+	return 0
+}
+
+// consume advances past prefix and returns true if the unparsed text
+// starts with it.
+func (p *pathParser) consume(prefix string) bool {
+	if strings.HasPrefix(p.query[p.pos:], prefix) {
+		p.pos += len(prefix)
+		return true
+	}
+	return false
+}
+
+// expect advances past prefix, or returns an error if the unparsed text
+// does not start with it.
+func (p *pathParser) expect(prefix string) error {
+	if !p.consume(prefix) {
+		return p.errorf("expected %q", prefix)
+	}
+	return nil
+}
+
+// skipSpace advances past blank space.
+func (p *pathParser) skipSpace() {
+	for p.pos < len(p.query) && strings.IndexByte(" \t\n\r", p.query[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// parseQuery parses an absolute query starting with "$".
+func (p *pathParser) parseQuery() (*pathQuery, error) {
+	if err := p.expect("$"); err != nil {
+		return nil, err
+	}
+	return p.parseSegments(false)
+}
+
+// parseSegments parses the segments following "$" or "@".
+func (p *pathParser) parseSegments(relative bool) (*pathQuery, error) {
+	q := &pathQuery{relative: relative, singular: true}
+	for {
+		start := p.pos
+		p.skipSpace()
+		if ch := p.peek(); ch != '.' && ch != '[' {
+			p.pos = start
+			return q, nil
+		}
+		seg, err := p.parseSegment()
+		if err != nil {
+			return nil, err
+		}
+		if seg.descendant || len(seg.selectors) != 1 ||
+			(seg.selectors[0].kind != nameSelector && seg.selectors[0].kind != indexSelector) {
+			q.singular = false
+		}
+		q.segments = append(q.segments, seg)
+	}
+}
+
+// parseSegment parses a child or descendant segment.
+func (p *pathParser) parseSegment() (pathSegment, error) {
+	var seg pathSegment
+	if p.consume("..") {
+		seg.descendant = true
+		if p.peek() == '[' {
+			return p.parseBracketed(seg)
+		}
+	} else if p.peek() == '[' {
+		return p.parseBracketed(seg)
+	} else {
+		p.pos++
+	}
+	if p.consume("*") {
+		seg.selectors = []pathSelector{{kind: wildcardSelector}}
+		return seg, nil
+	}
+	name := p.parseName()
+	if name == "" {
+		return seg, p.errorf("expected member name or '*'")
+	}
+	seg.selectors = []pathSelector{{kind: nameSelector, name: name}}
+	return seg, nil
+}
+
+// parseName parses a member-name-shorthand, returning "" if there is none.
+func (p *pathParser) parseName() string {
+	start := p.pos
+	for p.pos < len(p.query) {
+		r, size := utf8.DecodeRuneInString(p.query[p.pos:])
+		if !(r == '_' || (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') || r >= 0x80 && r != utf8.RuneError ||
+			(r >= '0' && r <= '9' && p.pos > start)) {
+			break
+		}
+		p.pos += size
+	}
+	return p.query[start:p.pos]
+}
+
+// parseBracketed parses a bracketed selection into seg.
+func (p *pathParser) parseBracketed(seg pathSegment) (pathSegment, error) {
+	p.pos++
+	for {
+		p.skipSpace()
+		sel, err := p.parseSelector()
+		if err != nil {
+			return seg, err
+		}
+		seg.selectors = append(seg.selectors, sel)
+		p.skipSpace()
+		if p.consume("]") {
+			return seg, nil
+		} else if err = p.expect(","); err != nil {
+			return seg, p.errorf("expected ',' or ']'")
+		}
+	}
+}
+
+// parseSelector parses a name, wildcard, index, slice or filter selector.
+func (p *pathParser) parseSelector() (pathSelector, error) {
+	var sel pathSelector
+	var err error
+	switch ch := p.peek(); {
+	case ch == '\'' || ch == '"':
+		sel.kind = nameSelector
+		sel.name, err = p.parseString()
+		return sel, err
+	case ch == '*':
+		p.pos++
+		sel.kind = wildcardSelector
+		return sel, nil
+	case ch == '?':
+		p.pos++
+		p.skipSpace()
+		sel.kind = filterSelector
+		sel.filter, err = p.parseLogical()
+		return sel, err
+	}
+	sel.kind = indexSelector
+	if ch := p.peek(); ch != ':' {
+		if sel.index, err = p.parseInt(); err != nil {
+			return sel, err
+		}
+		sel.slice[0], sel.bounds[0] = sel.index, true
+		p.skipSpace()
+	}
+	if !p.consume(":") {
+		if !sel.bounds[0] {
+			return sel, p.errorf("expected selector")
+		}
+		return sel, nil
+	}
+	sel.kind = sliceSelector
+	for bound := 1; bound <= 2; bound++ {
+		p.skipSpace()
+		if ch := p.peek(); ch == '-' || (ch >= '0' && ch <= '9') {
+			if sel.slice[bound], err = p.parseInt(); err != nil {
+				return sel, err
+			}
+			sel.bounds[bound] = true
+			p.skipSpace()
+		}
+		if bound == 1 && !p.consume(":") {
+			break
+		}
+	}
+	return sel, nil
+}
+
+// parseInt parses an index or slice integer, which must be within the
+// I-JSON exact integer range.
+func (p *pathParser) parseInt() (int64, error) {
+	start := p.pos
+	p.consume("-")
+	digits := p.pos
+	for ch := p.peek(); ch >= '0' && ch <= '9'; ch = p.peek() {
+		p.pos++
+	}
+	text := p.query[start:p.pos]
+	if p.pos == digits || (p.query[digits] == '0' && (p.pos > digits+1 || digits > start)) {
+		p.pos = start
+		return 0, p.errorf("expected integer")
+	}
+	n, err := strconv.ParseInt(text, 10, 64)
+	if err != nil || n > maxPathInt || n < -maxPathInt {
+		p.pos = start
+		return 0, p.errorf("integer %s out of range", text)
+	}
+	return n, nil
+}
+
+// parseString parses a single or double quoted string literal.
+func (p *pathParser) parseString() (string, error) {
+	quote := p.query[p.pos]
+	p.pos++
+	var buf strings.Builder
+	for {
+		if p.pos >= len(p.query) {
+			return "", p.errorf("unterminated string")
+		}
+		ch := p.query[p.pos]
+		switch {
+		case ch == quote:
+			p.pos++
+			return buf.String(), nil
+		case ch < 0x20:
+			return "", p.errorf("control character in string")
+		case ch != '\\':
+			buf.WriteByte(ch)
+			p.pos++
+			continue
+		}
+		p.pos++
+		esc := p.peek()
+		p.pos++
+		switch esc {
+		case 'b':
+			buf.WriteByte('\b')
+		case 'f':
+			buf.WriteByte('\f')
+		case 'n':
+			buf.WriteByte('\n')
+		case 'r':
+			buf.WriteByte('\r')
+		case 't':
+			buf.WriteByte('\t')
+		case '/', '\\':
+			buf.WriteByte(esc)
+		case 'u':
+			r, err := p.parseUnicodeEscape()
+			if err != nil {
+				return "", err
+			}
+			buf.WriteRune(r)
+		default:
+			if esc != quote {
+				p.pos -= 2
+				return "", p.errorf("invalid escape in string")
+			}
+			buf.WriteByte(esc)
+		}
+	}
+}
+
+// parseUnicodeEscape parses the hex digits of a "\u" escape, and of a
+// following low surrogate escape if the first is a high surrogate.
+func (p *pathParser) parseUnicodeEscape() (rune, error) {
+	hex := func() (rune, bool) {
+		if p.pos+4 > len(p.query) {
+			return 0, false
+		}
+		n, err := strconv.ParseUint(p.query[p.pos:p.pos+4], 16, 16)
+		if err != nil {
+			return 0, false
+		}
+		p.pos += 4
+		return rune(n), true
+	}
+	r, ok := hex()
+	if !ok {
+		return 0, p.errorf("invalid \\u escape in string")
+	} else if r >= 0xDC00 && r <= 0xDFFF {
+		return 0, p.errorf("unpaired surrogate in string")
+	} else if r < 0xD800 || r > 0xDBFF {
+		return r, nil
+	}
+	low := rune(0)
+	if p.consume(`\u`) {
+		low, ok = hex()
+	}
+	if !ok || low < 0xDC00 || low > 0xDFFF {
+		return 0, p.errorf("unpaired surrogate in string")
+	}
+	return utf16.DecodeRune(r, low), nil
+}
+
+// parseLogical parses a logical expression used as a filter.
+func (p *pathParser) parseLogical() (pathLogical, error) {
+	start := p.pos
+	operand, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	logical := operand.tester()
+	if logical == nil {
+		p.pos = start
+		return nil, p.errorf("expected logical expression")
+	}
+	return logical, nil
+}
+
+// parseOr parses "||" expressions, or a single operand.
+func (p *pathParser) parseOr() (pathOperand, error) {
+	return p.parseBinary("||", p.parseAnd, func(operands []pathLogical) pathLogical {
+		return pathOr(operands)
+	})
+}
+
+// parseAnd parses "&&" expressions, or a single operand.
+func (p *pathParser) parseAnd() (pathOperand, error) {
+	return p.parseBinary("&&", p.parseComparison, func(operands []pathLogical) pathLogical {
+		return pathAnd(operands)
+	})
+}
+
+// parseBinary parses operands parsed by next separated by op, combining
+// them with join if there is more than one.
+func (p *pathParser) parseBinary(op string, next func() (pathOperand, error),
+	join func([]pathLogical) pathLogical) (pathOperand, error) {
+	first, err := next()
+	if err != nil {
+		return first, err
+	}
+	var operands []pathLogical
+	for {
+		start := p.pos
+		p.skipSpace()
+		if !p.consume(op) {
+			p.pos = start
+			break
+		}
+		if operands == nil {
+			if operands = []pathLogical{first.tester()}; operands[0] == nil {
+				p.pos = start
+				return first, p.errorf("expected logical expression before %q", op)
+			}
+		}
+		p.skipSpace()
+		operandStart := p.pos
+		operand, err := next()
+		if err != nil {
+			return operand, err
+		}
+		logical := operand.tester()
+		if logical == nil {
+			p.pos = operandStart
+			return operand, p.errorf("expected logical expression after %q", op)
+		}
+		operands = append(operands, logical)
+	}
+	if operands == nil {
+		return first, nil
+	}
+	return pathOperand{kind: logicalOperand, logical: join(operands)}, nil
+}
+
+// parseComparison parses a comparison expression, or a single operand.
+func (p *pathParser) parseComparison() (pathOperand, error) {
+	leftStart := p.pos
+	left, err := p.parsePrimary()
+	if err != nil {
+		return left, err
+	}
+	start := p.pos
+	p.skipSpace()
+	var op string
+	for _, candidate := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consume(candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		p.pos = start
+		return left, nil
+	}
+	leftValue := left.valuer()
+	if leftValue == nil {
+		p.pos = leftStart
+		return left, p.errorf("expected literal, singular query or value function before %q", op)
+	}
+	p.skipSpace()
+	rightStart := p.pos
+	right, err := p.parsePrimary()
+	if err != nil {
+		return right, err
+	}
+	rightValue := right.valuer()
+	if rightValue == nil {
+		p.pos = rightStart
+		return right, p.errorf("expected literal, singular query or value function after %q", op)
+	}
+	return pathOperand{kind: logicalOperand, logical: pathComparison{op, leftValue, rightValue}}, nil
+}
+
+// parsePrimary parses a literal, query, function expression,
+// parenthesized expression or "!" expression.
+func (p *pathParser) parsePrimary() (pathOperand, error) {
+	var operand pathOperand
+	var err error
+	switch ch := p.peek(); {
+	case ch == '!':
+		p.pos++
+		p.skipSpace()
+		start := p.pos
+		if operand, err = p.parsePrimary(); err != nil {
+			return operand, err
+		}
+		logical := operand.tester()
+		if logical == nil || (operand.kind == logicalOperand && p.query[start] != '(') {
+			p.pos = start
+			return operand, p.errorf("expected test or parenthesized expression after '!'")
+		}
+		return pathOperand{kind: logicalOperand, logical: pathNot{logical}}, nil
+	case ch == '(':
+		p.pos++
+		p.skipSpace()
+		logical, err := p.parseLogical()
+		if err != nil {
+			return operand, err
+		}
+		p.skipSpace()
+		return pathOperand{kind: logicalOperand, logical: logical}, p.expect(")")
+	case ch == '@' || ch == '$':
+		p.pos++
+		operand.kind = queryOperand
+		operand.query, err = p.parseSegments(ch == '@')
+		return operand, err
+	case ch == '\'' || ch == '"':
+		str, err := p.parseString()
+		return pathOperand{kind: literalOperand, literal: &BigJSONValue{proxy: str}}, err
+	case ch == '-' || (ch >= '0' && ch <= '9'):
+		return p.parseNumber()
+	case ch >= 'a' && ch <= 'z':
+		return p.parseWord()
+	default:
+		return operand, p.errorf("expected expression")
+	}
+}
+
+// parseNumber parses a number literal, keeping its exact value.
+func (p *pathParser) parseNumber() (pathOperand, error) {
+	start := p.pos
+	digits := func() bool {
+		digitsStart := p.pos
+		for ch := p.peek(); ch >= '0' && ch <= '9'; ch = p.peek() {
+			p.pos++
+		}
+		return p.pos > digitsStart
+	}
+	p.consume("-")
+	ok := digits()
+	if ok && p.consume(".") {
+		ok = digits()
+	}
+	if ok && (p.consume("e") || p.consume("E")) {
+		if !p.consume("+") {
+			p.consume("-")
+		}
+		ok = digits()
+	}
+	literal := new(BigJSONValue)
+	opts := BigDecodeOptions{Decimal: true}
+	if !ok || literal.decodeJSONValue([]byte(p.query[start:p.pos]), &opts) != nil {
+		p.pos = start
+		return pathOperand{}, p.errorf("invalid number")
+	}
+	return pathOperand{kind: literalOperand, literal: literal}, nil
+}
+
+// parseWord parses true, false, null or a function expression.
+func (p *pathParser) parseWord() (pathOperand, error) {
+	start := p.pos
+	for ch := p.peek(); ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= '0' && ch <= '9'); ch = p.peek() {
+		p.pos++
+	}
+	word := p.query[start:p.pos]
+	if p.peek() != '(' {
+		switch word {
+		case "true", "false":
+			return pathOperand{kind: literalOperand, literal: &BigJSONValue{proxy: word == "true"}}, nil
+		case "null":
+			return pathOperand{kind: literalOperand, literal: &BigJSONValue{}}, nil
+		}
+		p.pos = start
+		return pathOperand{}, p.errorf("expected expression")
+	}
+	types, ok := pathFuncTypes[word]
+	if !ok {
+		p.pos = start
+		return pathOperand{}, p.errorf("unknown function %s()", word)
+	}
+	fn := &pathFunction{name: word, result: types.result}
+	argStart := 0
+	p.pos++
+	for idx := range types.params {
+		p.skipSpace()
+		if idx > 0 {
+			if err := p.expect(","); err != nil {
+				return pathOperand{}, err
+			}
+			p.skipSpace()
+		}
+		argStart = p.pos
+		arg, err := p.parseOr()
+		if err != nil {
+			return arg, err
+		}
+		switch types.params[idx] {
+		case valueType:
+			ok = arg.valuer() != nil
+		case logicalType:
+			arg = pathOperand{kind: logicalOperand, logical: arg.tester()}
+			ok = arg.logical != nil
+		case nodesType:
+			ok = arg.kind == queryOperand
+		}
+		if !ok {
+			p.pos = argStart
+			return arg, p.errorf("invalid argument %d of %s()", idx+1, word)
+		}
+		fn.args = append(fn.args, arg)
+	}
+	p.skipSpace()
+	if err := p.expect(")"); err != nil {
+		return pathOperand{}, err
+	}
+	if fn.result == logicalType && fn.args[1].kind == literalOperand {
+		if pattern, ok := fn.args[1].literal.proxy.(string); ok {
+			var err error
+			if fn.re, err = compileIRegexp(pattern, word == "match"); err != nil {
+				p.pos = argStart
+				return pathOperand{}, p.errorf("invalid pattern in %s(): %v", word, err)
+			}
+		}
+	}
+	return pathOperand{kind: functionOperand, function: fn}, nil
+}
@@ -0,0 +1,252 @@
+package bigjsonvalue
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Example document from RFC 9535 section 1.5.
+const storeDoc = `{ "store": {
+	"book": [
+		{ "category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95 },
+		{ "category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99 },
+		{ "category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3",
+			"price": 8.99 },
+		{ "category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings",
+			"isbn": "0-395-19395-8", "price": 22.99 }
+	],
+	"bicycle": { "color": "red", "price": 399 }
+} }`
+
+const idsDoc = `{ "rows": [ { "id": 9007199254740992, "name": "a" }, { "id": 9007199254740993, "name": "b" },
+	{ "id": 123456789012345678901234567890, "name": "c" }, { "id": 1.5, "name": "d" }, { "name": "e" } ] }`
+
+type jsonPathRec struct {
+	doc    string
+	query  string
+	result string
+}
+
+var jsonPathList = []jsonPathRec{
+	{storeDoc, `$.store.book[*].author`, "Nigel Rees|Evelyn Waugh|Herman Melville|J. R. R. Tolkien"},
+	{storeDoc, `$..author`, "Nigel Rees|Evelyn Waugh|Herman Melville|J. R. R. Tolkien"},
+	{storeDoc, `$.store.*`, `[{"category":"reference","author":"Nigel Rees","title":"Sayings of the Century","price":8.95},` +
+		`{"category":"fiction","author":"Evelyn Waugh","title":"Sword of Honour","price":12.99},` +
+		`{"category":"fiction","author":"Herman Melville","title":"Moby Dick","isbn":"0-553-21311-3","price":8.99},` +
+		`{"category":"fiction","author":"J. R. R. Tolkien","title":"The Lord of the Rings","isbn":"0-395-19395-8","price":22.99}]` +
+		`|{"color":"red","price":399}`},
+	{storeDoc, `$.store..price`, "8.95|12.99|8.99|22.99|399"},
+	{storeDoc, `$..book[2].title`, "Moby Dick"},
+	{storeDoc, `$..book[-1].title`, "The Lord of the Rings"},
+	{storeDoc, `$..book[0,1].title`, "Sayings of the Century|Sword of Honour"},
+	{storeDoc, `$..book[:2].title`, "Sayings of the Century|Sword of Honour"},
+	{storeDoc, `$..book[?@.isbn].title`, "Moby Dick|The Lord of the Rings"},
+	{storeDoc, `$..book[?@.price<10].title`, "Sayings of the Century|Moby Dick"},
+	{storeDoc, `$..book[?@.price == 8.95].title`, "Sayings of the Century"},
+	{storeDoc, `$..book[?@.price > 8.95 && @.price <= 12.99].title`, "Sword of Honour|Moby Dick"},
+	{storeDoc, `$..book[?!(@.category == 'fiction') || @.author == "Herman Melville"].title`,
+		"Sayings of the Century|Moby Dick"},
+	{storeDoc, `$..book[?@.price > $.store.bicycle.price]`, ""},
+	{storeDoc, `$.store.bicycle[ 'color' , "price" ]`, "red|399"},
+	{storeDoc, `$["store"]['bicycle'].color`, "red"},
+	{storeDoc, `$.store.missing`, ""},
+	{storeDoc, `$.store.book.title`, ""},
+	{storeDoc, `$`, ""},
+	{idsDoc, `$.rows[?@.id > 9007199254740992].name`, "b|c"},
+	{idsDoc, `$.rows[?@.id == 9007199254740993].name`, "b"},
+	{idsDoc, `$.rows[?@.id == 9007199254740992].name`, "a"},
+	{idsDoc, `$.rows[?@.id >= 123456789012345678901234567890].name`, "c"},
+	{idsDoc, `$.rows[?@.id < 2].name`, "d"},
+	{idsDoc, `$.rows[?@.id == 1.50].name`, "d"},
+	{idsDoc, `$.rows[?@.id != 1.5].name`, "a|b|c|e"},
+	{idsDoc, `$.rows[?!@.id].name`, "e"},
+	{`["a","b","c","d","e","f","g"]`, `$[1:3]`, "b|c"},
+	{`["a","b","c","d","e","f","g"]`, `$[5:]`, "f|g"},
+	{`["a","b","c","d","e","f","g"]`, `$[1:5:2]`, "b|d"},
+	{`["a","b","c","d","e","f","g"]`, `$[5:1:-2]`, "f|d"},
+	{`["a","b","c","d","e","f","g"]`, `$[::-1]`, "g|f|e|d|c|b|a"},
+	{`["a","b","c","d","e","f","g"]`, `$[ -2 : ]`, "f|g"},
+	{`["a","b","c","d","e","f","g"]`, `$[::0]`, ""},
+	{`["a","b","c","d","e","f","g"]`, `$[-99:99:3]`, "a|d|g"},
+	{`["a","b","c","d","e","f","g"]`, `$[7,-8,0]`, "a"},
+	{`{"o": {"j": 1, "k": 2}, "a": [5, 3, [{"j": 4}, {"k": 6}]]}`, `$..j`, "1|4"},
+	{`{"o": {"j": 1, "k": 2}, "a": [5, 3, [{"j": 4}, {"k": 6}]]}`, `$..[0]`, `5|{"j":4}`},
+	{`{"o": {"j": 1, "k": 2}, "a": [5, 3, [{"j": 4}, {"k": 6}]]}`, `$.a..*`, `5|3|[{"j":4},{"k":6}]|{"j":4}|{"k":6}|4|6`},
+	{`[{"s":"abc"},{"s":"xabcx"},{"s":"a\nc"},{"s":["a","b"]},{"s":"aé"}]`, `$[?match(@.s, 'a.c')].s`, "abc"},
+	{`[{"s":"abc"},{"s":"xabcx"},{"s":"a\nc"},{"s":["a","b"]},{"s":"aé"}]`, `$[?search(@.s, 'a.c')].s`, "abc|xabcx"},
+	{`[{"s":"abc"},{"s":"xabcx"},{"s":"a\nc"},{"s":["a","b"]},{"s":"aé"}]`, `$[?length(@.s) == 2].s`, `["a","b"]|aé`},
+	{`[{"s":"abc"},{"s":"xabcx"},{"s":"a\nc"},{"s":["a","b"]},{"s":"aé"}]`, `$[?count(@.s.*) > 1].s`, `["a","b"]`},
+	{`[{"s":"abc", "p":"^a"},{"s":"xbc", "p":"b"},{"s":"x^a", "p":"^a"}]`, `$[?search(@.s, @.p)].s`, "xbc|x^a"},
+	{`[{"s":"abc", "p":"(?i)A"},{"s":"ab", "p":"a|b)"}]`, `$[?search(@.s, @.p)].s`, ""},
+	{`[{"a":[{"x":1}]},{"a":[{"x":2},{"x":1}]}]`, `$[?value(@..x) == 1].a`, `[{"x":1}]`},
+	{`{"é'":"😀"}`, `$['é\'']`, "😀"},
+	{`{"é'":"😀"}`, `$["\u00e9'"]`, "😀"},
+	{`{"😀":1}`, `$["\ud83d\ude00"]`, "1"},
+	{`{"😀":1}`, `$.😀`, "1"},
+}
+
+func TestJSONPathSelect(t *testing.T) {
+	for idx, rec := range jsonPathList {
+		bjv, err := new(BigJSONValue).DecodeJSONValue(rec.doc)
+		if err != nil {
+			t.Fatalf("%d: Unexpected decode err=%v", idx, err)
+		}
+		values, err := bjv.Query(rec.query)
+		if err != nil {
+			t.Errorf("%d: Query(%s) err=%v", idx, rec.query, err)
+			continue
+		}
+		strs := make([]string, len(values))
+		for valueIdx, value := range values {
+			strs[valueIdx] = value.String()
+		}
+		if rec.query == "$" {
+			if len(values) != 1 || values[0] != bjv {
+				t.Errorf("%d: Query($) did not return the whole value", idx)
+			}
+		} else if result := strings.Join(strs, "|"); result != rec.result {
+			t.Errorf("%d: Query(%s)=%s, expected %s", idx, rec.query, result, rec.result)
+		}
+	}
+}
+
+func TestJSONPathDecimalDoc(t *testing.T) {
+	bjv, _ := BigDecodeOptions{Decimal: true}.DecodeJSONValue(new(BigJSONValue), storeDoc)
+	values, _ := bjv.Query(`$..book[?@.price == 8.950].title`)
+	if len(values) != 1 || values[0].String() != "Sayings of the Century" {
+		t.Errorf("Unexpected Query() of Decimal prices=%v", values)
+	}
+}
+
+// Comparison examples from RFC 9535 section 2.3.5.3.
+func TestJSONPathComparisons(t *testing.T) {
+	bjv, _ := new(BigJSONValue).DecodeJSONValue(`{"obj": {"x": "y"}, "arr": [2, 3]}`)
+	compareList := []struct {
+		expr   string
+		result bool
+	}{
+		{`$.absent1 == $.absent2`, true},
+		{`$.absent1 <= $.absent2`, true},
+		{`$.absent == 'g'`, false},
+		{`$.absent1 != $.absent2`, false},
+		{`$.absent != 'g'`, true},
+		{`1 <= 2`, true},
+		{`1 > 2`, false},
+		{`13 == '13'`, false},
+		{`'a' <= 'b'`, true},
+		{`'a' > 'b'`, false},
+		{`$.obj == $.arr`, false},
+		{`$.obj != $.arr`, true},
+		{`$.obj == $.obj`, true},
+		{`$.obj != $.obj`, false},
+		{`$.arr == $.arr`, true},
+		{`$.arr != $.arr`, false},
+		{`$.obj == 17`, false},
+		{`$.obj != 17`, true},
+		{`$.obj <= $.arr`, false},
+		{`$.obj < $.arr`, false},
+		{`$.obj <= $.obj`, true},
+		{`$.arr <= $.arr`, true},
+		{`1 <= $.arr`, false},
+		{`1 >= $.arr`, false},
+		{`1 > $.arr`, false},
+		{`1 < $.arr`, false},
+		{`true <= true`, true},
+		{`true > true`, false},
+		{`null == null`, true},
+		{`1 == 1.0`, true},
+		{`1e2 == 100`, true},
+		{`-0 == 0`, true},
+		{`$.arr[1] > $.arr[0]`, true},
+	}
+	for idx, rec := range compareList {
+		values, err := bjv.Query(`$[?` + rec.expr + `]`)
+		if err != nil {
+			t.Errorf("%d: %s err=%v", idx, rec.expr, err)
+		} else if (len(values) == 2) != rec.result {
+			t.Errorf("%d: %s selected %d values, expected %t", idx, rec.expr, len(values), rec.result)
+		}
+	}
+}
+
+func TestJSONPathSelectPaths(t *testing.T) {
+	bjv, _ := new(BigJSONValue).DecodeJSONValue(`{"a": [{"b'c\\d": 1, "e\n\u0001": 2}]}`)
+	paths := MustCompileJSONPath(`$..*`).SelectPaths(bjv)
+	expected := []string{`$['a']`, `$['a'][0]`, `$['a'][0]['b\'c\\d']`, `$['a'][0]['e\n\u0001']`}
+	if strings.Join(paths, ",") != strings.Join(expected, ",") {
+		t.Errorf("Unexpected SelectPaths()=%v", paths)
+	}
+}
+
+func TestJSONPathInvalid(t *testing.T) {
+	invalidList := []string{
+		``,
+		`a`,
+		`$ `,
+		` $`,
+		`$.`,
+		`$..`,
+		`$.[0]`,
+		`$[`,
+		`$[0`,
+		`$[0,]`,
+		`$[01]`,
+		`$[-0]`,
+		`$[9007199254740992]`,
+		`$[1:2:3:4]`,
+		`$['a\x']`,
+		`$['a`,
+		`$['\ud800']`,
+		`$[?]`,
+		`$[?true]`,
+		`$[?1 == 1 == 1]`,
+		`$[?@.a == @.*]`,
+		`$[?@.a == @..b]`,
+		`$[?@.a == @['a','b']]`,
+		`$[?!@.a == 1]`,
+		`$[?!!@.a]`,
+		`$[?(1)]`,
+		`$[?length(@.a)]`,
+		`$[?length(@.*) > 1]`,
+		`$[?count(1) > 0]`,
+		`$[?match(@.a)]`,
+		`$[?match(@.a, 'x') == true]`,
+		`$[?match(@.a, 'a(')]`,
+		`$[?search(@.a, '(?i)a')]`,
+		`$[?search(@.a, 'a\z')]`,
+		`$[?foo(@)]`,
+		`$[?@.a == 01]`,
+		`$[?@.a == 1.]`,
+		`$[?@.a = 1]`,
+		`$[?@.a == nul]`,
+		`$[?(@.a]`,
+	}
+	for _, query := range invalidList {
+		_, err := CompileJSONPath(query)
+		var pathErr *JSONPathError
+		if !errors.As(err, &pathErr) || !errors.Is(err, ErrInvalidJSONPath) {
+			t.Errorf("CompileJSONPath(%s) err=%v, expected a *JSONPathError", query, err)
+		}
+	}
+
+	_, err := CompileJSONPath(`$.a[?@.b == @.*]`)
+	if err.Error() != `cannot compile "$.a[?@.b == @.*]" at offset 12: expected literal, singular query or value function after "=="` {
+		t.Errorf("Unexpected Error()=%s", err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("MustCompileJSONPath() did not panic")
+		}
+	}()
+	MustCompileJSONPath(`$[`)
+}
+
+func BenchmarkJSONPathFilter(b *testing.B) {
+	bjv, _ := new(BigJSONValue).DecodeJSONValue(idsDoc)
+	jp := MustCompileJSONPath(`$.rows[?@.id > 9007199254740992 && @.name != 'x'].name`)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		jp.Select(bjv)
+	}
+}
@@ -14,6 +14,10 @@ const (
 	Float64
 	BigInt
 	BigFloat
+	Object
+	Array
+	Decimal
+	Number
 	lastKind
 	// insert new enums before lastKind, lastKind MUST ALWAYS BE LAST
 )
@@ -27,6 +31,10 @@ var kindNames = [...]string{
 	"Float64",
 	"BigInt",
 	"BigFloat",
+	"Object",
+	"Array",
+	"Decimal",
+	"Number",
 }
 
 // String implements fmt.Stringer interface for Kind
@@ -0,0 +1,115 @@
+package bigjsonvalue
+
+// MergePatch returns a copy of target with the JSON Merge Patch patch
+// applied, per RFC 7396.  If patch is an object, its members are merged
+// into target member by member, with null members removing the member
+// from target, and target replaced by an empty object first if it is not
+// one.  Any other patch, including an array, replaces target as a whole.
+// Numbers are copied as they are, so large integers such as 64-bit IDs
+// are never rounded.  Neither target nor patch is changed.
+func MergePatch(target *BigJSONValue, patch *BigJSONValue) *BigJSONValue {
+	result := mergePatch(target, patch)
+	return &result
+}
+
+// mergePatch returns a copy of target, which may be nil, with patch
+// applied.
+func mergePatch(target *BigJSONValue, patch *BigJSONValue) BigJSONValue {
+	patchObj, ok := patch.proxy.(*bigObject)
+	if !ok {
+		return patch.copyValue()
+	}
+	result := BigJSONValue{proxy: &bigObject{members: make(map[string]*BigJSONValue)}}
+	if _, ok := target.proxyOrNil().(*bigObject); ok {
+		result = target.copyValue()
+	}
+	obj := result.proxy.(*bigObject)
+	for _, key := range patchObj.keys {
+		member := patchObj.members[key]
+		if member.proxy != nil {
+			merged := mergePatch(obj.members[key], member)
+			obj.set(key, &merged)
+		} else if _, ok := obj.members[key]; ok {
+			obj.keys = removeKey(obj.keys, key)
+			delete(obj.members, key)
+		}
+	}
+	return result
+}
+
+// CreateMergePatch returns a JSON Merge Patch, per RFC 7396, that
+// MergePatch() applies to original to give a value equal to modified.
+// Members are only patched if not Equal(), so numbers of different kinds
+// with the same value, e.g. 1 and 1.0, are left as they are in original.
+// An empty object is returned if original and modified are Equal().
+//
+// Merge patches use null members to remove members, so cannot set an
+// object member to null.  Returns ErrMergePatchNull if modified has a
+// null member that is not null in original, or that is in an object
+// that is not an object in original.
+func CreateMergePatch(original *BigJSONValue, modified *BigJSONValue) (*BigJSONValue, error) {
+	if original.Equal(modified) {
+		return &BigJSONValue{proxy: &bigObject{members: make(map[string]*BigJSONValue)}}, nil
+	}
+	patch, err := createMergePatch(original, modified)
+	if err != nil {
+		return nil, err
+	}
+	return &patch, nil
+}
+
+// createMergePatch returns the patch for the unequal original and
+// modified values.
+func createMergePatch(original *BigJSONValue, modified *BigJSONValue) (BigJSONValue, error) {
+	modObj, ok := modified.proxy.(*bigObject)
+	if !ok {
+		return modified.copyValue(), nil
+	}
+	origObj, ok := original.proxy.(*bigObject)
+	if !ok {
+		if hasNullMember(modified) {
+			return BigJSONValue{}, ErrMergePatchNull
+		}
+		return modified.copyValue(), nil
+	}
+	patchObj := &bigObject{members: make(map[string]*BigJSONValue)}
+	for _, key := range origObj.keys {
+		if _, ok := modObj.members[key]; !ok {
+			patchObj.set(key, &BigJSONValue{})
+		}
+	}
+	for _, key := range modObj.keys {
+		origMember, modMember := origObj.members[key], modObj.members[key]
+		if origMember != nil && origMember.Equal(modMember) {
+			continue
+		} else if modMember.proxy == nil {
+			return BigJSONValue{}, ErrMergePatchNull
+		}
+		member := modMember.copyValue()
+		if origMember != nil {
+			var err error
+			if member, err = createMergePatch(origMember, modMember); err != nil {
+				return BigJSONValue{}, err
+			}
+		} else if hasNullMember(modMember) {
+			return BigJSONValue{}, ErrMergePatchNull
+		}
+		patchObj.set(key, &member)
+	}
+	return BigJSONValue{proxy: patchObj}, nil
+}
+
+// hasNullMember returns true if bjv is an object with a null member,
+// either directly or in a member that is itself an object.
+func hasNullMember(bjv *BigJSONValue) bool {
+	obj, ok := bjv.proxy.(*bigObject)
+	if !ok {
+		return false
+	}
+	for _, member := range obj.members {
+		if member.proxy == nil || hasNullMember(member) {
+			return true
+		}
+	}
+	return false
+}
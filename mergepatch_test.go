@@ -0,0 +1,93 @@
+package bigjsonvalue
+
+import (
+	"errors"
+	"testing"
+)
+
+// Examples from RFC 7396 appendix A, plus large numbers.
+var mergePatchList = []struct {
+	target string
+	patch  string
+	result string
+}{
+	{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+	{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+	{`{"a":"b"}`, `{"a":null}`, `{}`},
+	{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+	{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+	{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+	{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+	{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+	{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+	{`{"a":"b"}`, `["c"]`, `["c"]`},
+	{`{"a":"foo"}`, `null`, `null`},
+	{`{"a":"foo"}`, `"bar"`, `"bar"`},
+	{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+	{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+	{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	{`{"tenant":{"id":18446744073709551617,"limit":9007199254740993}}`, `{"tenant":{"limit":9007199254740995}}`,
+		`{"tenant":{"id":18446744073709551617,"limit":9007199254740995}}`},
+}
+
+func TestMergePatch(t *testing.T) {
+	for idx, rec := range mergePatchList {
+		target, _ := new(BigJSONValue).DecodeJSONValue(rec.target)
+		patch, _ := new(BigJSONValue).DecodeJSONValue(rec.patch)
+		result := MergePatch(target, patch)
+		if text, _ := result.MarshalJSON(); string(text) != rec.result {
+			t.Errorf("%d: MergePatch(%s, %s)=%s, expected %s", idx, rec.target, rec.patch, text, rec.result)
+		}
+		if orig, _ := new(BigJSONValue).DecodeJSONValue(rec.target); !target.Equal(orig) {
+			t.Errorf("%d: MergePatch(%s, %s) changed target to %s", idx, rec.target, rec.patch, target)
+		}
+	}
+
+	target, _ := new(BigJSONValue).DecodeJSONValue(`{"a":{"b":1}}`)
+	patch, _ := new(BigJSONValue).DecodeJSONValue(`{"c":{"d":2}}`)
+	result := MergePatch(target, patch)
+	target.Set("/a/b", NewBigNull())
+	patch.Set("/c/d", NewBigNull())
+	if str := result.String(); str != `{"a":{"b":1},"c":{"d":2}}` {
+		t.Errorf("Changing target or patch changed result to %s", str)
+	}
+}
+
+func TestCreateMergePatch(t *testing.T) {
+	createList := []struct {
+		original string
+		modified string
+		patch    string
+		err      error
+	}{
+		{`{"a":1}`, `{"a":1.0}`, `{}`, nil},
+		{`{"a":"b","c":{"d":"e","f":"g"}}`, `{"a":"z","c":{"d":"e"}}`, `{"a":"z","c":{"f":null}}`, nil},
+		{`{"a":[1,2]}`, `{"a":[1,2,3],"b":{"c":4}}`, `{"a":[1,2,3],"b":{"c":4}}`, nil},
+		{`{"id":9007199254740992}`, `{"id":9007199254740993}`, `{"id":9007199254740993}`, nil},
+		{`{"a":1}`, `[null]`, `[null]`, nil},
+		{`{"a":1}`, `null`, `null`, nil},
+		{`[1]`, `{"a":2}`, `{"a":2}`, nil},
+		{`{"a":null}`, `{"a":null,"b":1}`, `{"b":1}`, nil},
+		{`{"a":1}`, `{"a":null}`, "", ErrMergePatchNull},
+		{`{}`, `{"a":null}`, "", ErrMergePatchNull},
+		{`{"a":1}`, `{"a":{"b":null}}`, "", ErrMergePatchNull},
+		{`[1]`, `{"a":{"b":null}}`, "", ErrMergePatchNull},
+	}
+	for idx, rec := range createList {
+		original, _ := new(BigJSONValue).DecodeJSONValue(rec.original)
+		modified, _ := new(BigJSONValue).DecodeJSONValue(rec.modified)
+		patch, err := CreateMergePatch(original, modified)
+		if !errors.Is(err, rec.err) {
+			t.Errorf("%d: CreateMergePatch(%s, %s) unexpected err=%v", idx, rec.original, rec.modified, err)
+			continue
+		} else if err != nil {
+			continue
+		}
+		if text, _ := patch.MarshalJSON(); string(text) != rec.patch {
+			t.Errorf("%d: CreateMergePatch(%s, %s)=%s, expected %s", idx, rec.original, rec.modified, text, rec.patch)
+		}
+		if result := MergePatch(original, patch); !result.Equal(modified) {
+			t.Errorf("%d: MergePatch(CreateMergePatch(%s, %s))=%s", idx, rec.original, rec.modified, result)
+		}
+	}
+}
@@ -3,8 +3,10 @@ package bigjsonvalue
 import (
 	"encoding/json"
 	"fmt"
+	"hash"
+	"math"
+	"math/big"
 	"strconv"
-	"strings"
 )
 
 // NatJSONValue is wrapper around interface{} type to force
@@ -16,8 +18,27 @@ import (
 //
 // Compared to BigJSONValue, NatJSONValue uses native Golang number types
 // int64, uint64, and float64 to store numbers, so is faster than BigJSONValue.
+// Numbers that do not fit in them are handled according to the
+// OverflowPolicy of DefaultNatDecodeOptions.
 type NatJSONValue struct {
-	proxy interface{}
+	proxy   interface{}
+	literal string // original text of a number, if retained
+}
+
+// natObject holds the members of a JSON object held by NatJSONValue,
+// remembering the order in which keys were added.
+type natObject struct {
+	keys    []string
+	members map[string]*NatJSONValue
+}
+
+// set sets the member with the given key, appending key to keys if not
+// already present.
+func (obj *natObject) set(key string, member *NatJSONValue) {
+	if _, dup := obj.members[key]; !dup {
+		obj.keys = append(obj.keys, key)
+	}
+	obj.members[key] = member
 }
 
 // Kind returns the kind of NatJSONValue it is holding:
@@ -32,6 +53,14 @@ type NatJSONValue struct {
 //
 // Returns Float64 if value is a float64.
 //
+// Returns Object if value is a JSON object.
+//
+// Returns Array if value is a JSON array.
+//
+// Returns BigInt if value is a big.Int, BigFloat if value is a big.Float,
+// or Number if value is a json.Number, which are only decoded for out of
+// range numbers depending on OverflowPolicy.
+//
 // Otherwise returns Nil.
 func (njv *NatJSONValue) Kind() Kind {
 	switch njv.proxy.(type) {
@@ -45,6 +74,16 @@ func (njv *NatJSONValue) Kind() Kind {
 		return Uint64
 	case float64:
 		return Float64
+	case *natObject:
+		return Object
+	case []NatJSONValue:
+		return Array
+	case *big.Int:
+		return BigInt
+	case *big.Float:
+		return BigFloat
+	case json.Number:
+		return Number
 	default:
 		return Nil
 	}
@@ -80,9 +119,61 @@ func (njv *NatJSONValue) IsUint64() bool {
 	return (njv.Kind() == Uint64)
 }
 
+// IsBigInt returns true if value is a big.Int.
+func (njv *NatJSONValue) IsBigInt() bool {
+	return (njv.Kind() == BigInt)
+}
+
+// IsBigFloat returns true if value is a big.Float.
+func (njv *NatJSONValue) IsBigFloat() bool {
+	return (njv.Kind() == BigFloat)
+}
+
+// IsNumber returns true if value is a json.Number.
+func (njv *NatJSONValue) IsNumber() bool {
+	return (njv.Kind() == Number)
+}
+
+// IsObject returns true if value is a JSON object.
+func (njv *NatJSONValue) IsObject() bool {
+	return (njv.Kind() == Object)
+}
+
+// IsArray returns true if value is a JSON array.
+func (njv *NatJSONValue) IsArray() bool {
+	return (njv.Kind() == Array)
+}
+
 // Value returns the underlying interface{} value that is being wrapped.
+//
+// BigInt and BigFloat values return a copy as a big.Int or big.Float.
+//
+// Object values return a map[string]*NatJSONValue of their members.
+//
+// Array values return a []*NatJSONValue of their elements.
 func (njv *NatJSONValue) Value() interface{} {
-	return njv.proxy
+	switch njv.proxy.(type) {
+	case *big.Int:
+		return njv.BigInt()
+	case *big.Float:
+		return njv.BigFloat()
+	case *natObject:
+		obj := njv.proxy.(*natObject)
+		members := make(map[string]*NatJSONValue, len(obj.members))
+		for key, member := range obj.members {
+			members[key] = member
+		}
+		return members
+	case []NatJSONValue:
+		arr := njv.proxy.([]NatJSONValue)
+		elems := make([]*NatJSONValue, len(arr))
+		for idx := range arr {
+			elems[idx] = &arr[idx]
+		}
+		return elems
+	default:
+		return njv.proxy
+	}
 }
 
 // Bool returns the underlying bool value.
@@ -109,6 +200,397 @@ func (njv *NatJSONValue) Uint64() uint64 {
 	return njv.proxy.(uint64)
 }
 
+// BigInt returns a copy of the underlying big.Int value, which can be
+// changed without affecting the stored value.
+// Panics with runtime error if not a big.Int.
+func (njv *NatJSONValue) BigInt() big.Int {
+	var bigi big.Int
+	bigi.Set(njv.proxy.(*big.Int))
+	return bigi
+}
+
+// BigIntRef returns a read-only view of the underlying big.Int value,
+// without copying it.  The result must not be modified, since it is the
+// stored value itself.
+// Panics with runtime error if not a big.Int.
+func (njv *NatJSONValue) BigIntRef() *big.Int {
+	return njv.proxy.(*big.Int)
+}
+
+// BigFloat returns a copy of the underlying big.Float value, which can be
+// changed without affecting the stored value.
+// Panics with runtime error if not a big.Float.
+func (njv *NatJSONValue) BigFloat() big.Float {
+	var bigf big.Float
+	bigf.Copy(njv.proxy.(*big.Float))
+	return bigf
+}
+
+// BigFloatRef returns a read-only view of the underlying big.Float value,
+// without copying it.  The result must not be modified, since it is the
+// stored value itself.
+// Panics with runtime error if not a big.Float.
+func (njv *NatJSONValue) BigFloatRef() *big.Float {
+	return njv.proxy.(*big.Float)
+}
+
+// Number returns the underlying json.Number value.
+// Panics with runtime error if not a json.Number.
+func (njv *NatJSONValue) Number() json.Number {
+	return njv.proxy.(json.Number)
+}
+
+// AsBool returns the value as a bool.
+// Returns a *ConvertError wrapping ErrKindMismatch if not a bool.
+func (njv *NatJSONValue) AsBool() (bool, error) {
+	b, err := asBool(njv.proxy)
+	return b, newConvertError(njv.Kind(), Bool, err)
+}
+
+// AsString returns the value as a string.
+// Returns a *ConvertError wrapping ErrKindMismatch if not a string.
+func (njv *NatJSONValue) AsString() (string, error) {
+	str, err := asString(njv.proxy)
+	return str, newConvertError(njv.Kind(), String, err)
+}
+
+// AsInt64 returns a number value converted to an int64.
+// Returns a *ConvertError wrapping ErrKindMismatch if not a number,
+// ErrOverflow with the nearest int64 if out of range, or
+// ErrPrecisionLoss with the value truncated toward zero if fractional.
+func (njv *NatJSONValue) AsInt64() (int64, error) {
+	i64, err := asInt64(njv.proxy)
+	return i64, newConvertError(njv.Kind(), Int64, err)
+}
+
+// AsUint64 returns a number value converted to a uint64.
+// Returns a *ConvertError wrapping ErrKindMismatch if not a number,
+// ErrOverflow with the nearest uint64 if out of range, or
+// ErrPrecisionLoss with the value truncated toward zero if fractional.
+func (njv *NatJSONValue) AsUint64() (uint64, error) {
+	u64, err := asUint64(njv.proxy)
+	return u64, newConvertError(njv.Kind(), Uint64, err)
+}
+
+// AsFloat64 returns a number value converted to a float64.
+// Returns a *ConvertError wrapping ErrKindMismatch if not a number,
+// ErrOverflow with ±Inf if out of range, or ErrPrecisionLoss with the
+// nearest float64 if not exactly representable.
+func (njv *NatJSONValue) AsFloat64() (float64, error) {
+	f64, err := asFloat64(njv.proxy)
+	return f64, newConvertError(njv.Kind(), Float64, err)
+}
+
+// AsBigInt returns a number value converted to a new big.Int.
+// Returns a *ConvertError wrapping ErrKindMismatch if not a number,
+// ErrOverflow if infinite, or ErrPrecisionLoss with the value truncated
+// toward zero if fractional.  The big.Int is nil if there is no
+// nearest value.
+func (njv *NatJSONValue) AsBigInt() (*big.Int, error) {
+	bigi, err := asBigInt(njv.proxy)
+	return bigi, newConvertError(njv.Kind(), BigInt, err)
+}
+
+// AsBigFloat returns a number value converted to a new big.Float.
+// Integers are held exactly.  Returns a *ConvertError wrapping
+// ErrKindMismatch if not a number, ErrUnsupportedValue if NaN, or
+// ErrPrecisionLoss with the nearest value if a decimal cannot be held
+// exactly at the precision of DefaultBigDecodeOptions.  The big.Float
+// is nil if there is no nearest value.
+func (njv *NatJSONValue) AsBigFloat() (*big.Float, error) {
+	bigf, err := asBigFloat(njv.proxy)
+	return bigf, newConvertError(njv.Kind(), BigFloat, err)
+}
+
+// ToBig returns the value converted exactly to a new BigJSONValue,
+// including all members and elements.  Int64 and Uint64 values are
+// converted to big.Int values, Float64 values to big.Float values with
+// precision 53, and Number values to big.Int or exact Decimal values.
+// Retained literals are kept.
+//
+// The only values that cannot be converted exactly are NaN, which is
+// converted to nil, and Number values with a power of ten beyond the
+// range of an int32, which are rounded to 512 bits.  The rest of the
+// value is still converted, and a *ConvertError is returned for the
+// first of them, wrapping ErrUnsupportedValue for NaN or
+// ErrPrecisionLoss for a rounded Number.
+func (njv *NatJSONValue) ToBig() (*BigJSONValue, error) {
+	var conv bigConverter
+	bjv := conv.value(njv)
+	return &bjv, conv.err
+}
+
+// Member returns the member of an object value with the given key.
+// Returns nil and false if not an object or key is not present.
+func (njv *NatJSONValue) Member(key string) (*NatJSONValue, bool) {
+	obj, ok := njv.proxy.(*natObject)
+	if !ok {
+		return nil, false
+	}
+	member, ok := obj.members[key]
+	return member, ok
+}
+
+// Keys returns the keys of an object value in order.
+// Returns nil if not an object.
+func (njv *NatJSONValue) Keys() []string {
+	obj, ok := njv.proxy.(*natObject)
+	if !ok {
+		return nil
+	}
+	return append([]string(nil), obj.keys...)
+}
+
+// Len returns the number of members of an object value, or the number
+// of elements of an array value.
+// Returns 0 if neither an object nor an array.
+func (njv *NatJSONValue) Len() int {
+	switch njv.proxy.(type) {
+	case *natObject:
+		return len(njv.proxy.(*natObject).keys)
+	case []NatJSONValue:
+		return len(njv.proxy.([]NatJSONValue))
+	default:
+		return 0
+	}
+}
+
+// Index returns the element of an array value at index idx.
+// Returns nil if not an array or idx is out of range.
+func (njv *NatJSONValue) Index(idx int) *NatJSONValue {
+	arr, _ := njv.proxy.([]NatJSONValue)
+	if idx < 0 || idx >= len(arr) {
+		return nil
+	}
+	return &arr[idx]
+}
+
+// Range calls fn for each element of an array value in order,
+// stopping early if fn returns false.  Does nothing if not an array.
+func (njv *NatJSONValue) Range(fn func(idx int, elem *NatJSONValue) bool) {
+	arr, _ := njv.proxy.([]NatJSONValue)
+	for idx := range arr {
+		if !fn(idx, &arr[idx]) {
+			return
+		}
+	}
+}
+
+// Get returns the value referenced by the JSON Pointer pointer, per
+// RFC 6901, e.g. "/tags/0" for the first element of member "tags", or
+// "/a~1b" for member "a/b".  "" references the whole value.  The value
+// returned is not a copy, so changing it changes this value.
+// Returns a *PointerError if pointer is not well-formed, or references a
+// member or element that does not exist.
+func (njv *NatJSONValue) Get(pointer string) (*NatJSONValue, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	return njv.resolve(pointer, tokens)
+}
+
+// Set sets the value referenced by pointer to a copy of value, adding an
+// object member if not present, or replacing the whole value for "".
+// Unlike Add(), array elements are replaced rather than inserted, so must
+// exist.  A nil value sets null.
+// Returns a *PointerError if pointer is not well-formed, or references a
+// value whose parent does not exist or is not an object or array.
+func (njv *NatJSONValue) Set(pointer string, value *NatJSONValue) error {
+	parent, tokens, err := njv.resolveParent(pointer)
+	if err != nil {
+		return err
+	} else if parent == nil {
+		*njv = value.copyValue()
+		return nil
+	}
+	token := tokens[len(tokens)-1]
+	switch parent.proxy.(type) {
+	case *natObject:
+		member := value.copyValue()
+		parent.proxy.(*natObject).set(token, &member)
+	case []NatJSONValue:
+		arr := parent.proxy.([]NatJSONValue)
+		idx, err := arrayIndex(token, len(arr))
+		if err == nil && idx == len(arr) {
+			err = ErrPointerNotFound
+		}
+		if err != nil {
+			return newPointerError(pointer, tokens, parent.Kind(), err)
+		}
+		arr[idx] = value.copyValue()
+	default:
+		return newPointerError(pointer, tokens, parent.Kind(), ErrKindMismatch)
+	}
+	return nil
+}
+
+// Add adds a copy of value at pointer, the same way as the "add"
+// operation of RFC 6902: an object member is added or replaced, an array
+// element is inserted at the index, or appended for "-", and "" replaces
+// the whole value.  A nil value adds null.  Elements returned by Get(),
+// Index() or Range() before an insert no longer belong to the array.
+// Returns a *PointerError if pointer is not well-formed, or references a
+// value whose parent does not exist or is not an object or array.
+func (njv *NatJSONValue) Add(pointer string, value *NatJSONValue) error {
+	parent, tokens, err := njv.resolveParent(pointer)
+	if err != nil {
+		return err
+	} else if parent == nil {
+		*njv = value.copyValue()
+		return nil
+	}
+	token := tokens[len(tokens)-1]
+	switch parent.proxy.(type) {
+	case *natObject:
+		member := value.copyValue()
+		parent.proxy.(*natObject).set(token, &member)
+	case []NatJSONValue:
+		arr := parent.proxy.([]NatJSONValue)
+		idx, err := arrayIndex(token, len(arr))
+		if err != nil {
+			return newPointerError(pointer, tokens, parent.Kind(), err)
+		}
+		newArr := make([]NatJSONValue, 0, len(arr)+1)
+		newArr = append(newArr, arr[:idx]...)
+		newArr = append(newArr, value.copyValue())
+		parent.proxy = append(newArr, arr[idx:]...)
+	default:
+		return newPointerError(pointer, tokens, parent.Kind(), ErrKindMismatch)
+	}
+	return nil
+}
+
+// Delete removes the object member or array element referenced by
+// pointer, keeping the order of the remaining members or elements.
+// "" sets the whole value to null, as it has no parent to be removed
+// from.  Elements returned by Get(), Index() or Range() before a removal
+// no longer belong to the array.
+// Returns a *PointerError if pointer is not well-formed, or references a
+// member or element that does not exist.
+func (njv *NatJSONValue) Delete(pointer string) error {
+	parent, tokens, err := njv.resolveParent(pointer)
+	if err != nil {
+		return err
+	} else if parent == nil {
+		*njv = NatJSONValue{}
+		return nil
+	}
+	token := tokens[len(tokens)-1]
+	switch parent.proxy.(type) {
+	case *natObject:
+		obj := parent.proxy.(*natObject)
+		if _, ok := obj.members[token]; !ok {
+			return newPointerError(pointer, tokens, parent.Kind(), ErrPointerNotFound)
+		}
+		obj.keys = removeKey(obj.keys, token)
+		delete(obj.members, token)
+	case []NatJSONValue:
+		arr := parent.proxy.([]NatJSONValue)
+		idx, err := arrayIndex(token, len(arr))
+		if err == nil && idx == len(arr) {
+			err = ErrPointerNotFound
+		}
+		if err != nil {
+			return newPointerError(pointer, tokens, parent.Kind(), err)
+		}
+		newArr := make([]NatJSONValue, 0, len(arr)-1)
+		newArr = append(newArr, arr[:idx]...)
+		parent.proxy = append(newArr, arr[idx+1:]...)
+	default:
+		return newPointerError(pointer, tokens, parent.Kind(), ErrKindMismatch)
+	}
+	return nil
+}
+
+// resolve returns the value referenced by tokens, the reference tokens
+// parsed from pointer.
+func (njv *NatJSONValue) resolve(pointer string, tokens []string) (*NatJSONValue, error) {
+	cur := njv
+	for idx, token := range tokens {
+		var err error
+		switch cur.proxy.(type) {
+		case *natObject:
+			member, ok := cur.proxy.(*natObject).members[token]
+			if ok {
+				cur = member
+			} else {
+				err = ErrPointerNotFound
+			}
+		case []NatJSONValue:
+			arr := cur.proxy.([]NatJSONValue)
+			var elemIdx int
+			if elemIdx, err = arrayIndex(token, len(arr)); err == nil && elemIdx == len(arr) {
+				err = ErrPointerNotFound
+			} else if err == nil {
+				cur = &arr[elemIdx]
+			}
+		default:
+			err = ErrKindMismatch
+		}
+		if err != nil {
+			return nil, newPointerError(pointer, tokens[:idx+1], cur.Kind(), err)
+		}
+	}
+	return cur, nil
+}
+
+// resolveParent returns the parent of the value referenced by pointer,
+// and the reference tokens parsed from pointer.  Returns a nil parent for
+// "", which references the whole value.
+func (njv *NatJSONValue) resolveParent(pointer string) (*NatJSONValue, []string, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil || len(tokens) == 0 {
+		return nil, tokens, err
+	}
+	parent, err := njv.resolve(pointer, tokens[:len(tokens)-1])
+	return parent, tokens, err
+}
+
+// Equal returns true if the value is equal to other, which is the same
+// as Compare(other) == 0.  Numbers of different kinds are equal if they
+// have exactly the same value, e.g. Int64 1, Uint64 1 and Float64 1.0.
+// Objects are equal if they have the same keys with equal members,
+// in any order.
+func (njv *NatJSONValue) Equal(other *NatJSONValue) bool {
+	return equalProxies(njv.proxy, other.proxy)
+}
+
+// Compare returns -1, 0 or +1 if the value is less than, equal to, or
+// greater than other.  Values of different kinds are ordered
+// nil < bool < number < string < array < object, and within a kind:
+//
+// Bool values order false before true.
+//
+// Number values order by exact value, whatever their kind.  NaN is
+// equal to itself and less than all other numbers.  Numbers larger or
+// smaller than about 1e±100000 are compared rounded to 512 bits.
+//
+// String values order by their bytes.
+//
+// Array values order by their first unequal element, or by length if
+// one is a prefix of the other.
+//
+// Object values order by their members sorted by key, as if they were
+// arrays of key and member pairs.
+func (njv *NatJSONValue) Compare(other *NatJSONValue) int {
+	return compareProxies(njv.proxy, other.proxy)
+}
+
+// Hash64 returns a 64-bit FNV-1a hash of the value, which is the same for
+// values that are Equal(), so can be used as a map key standing in for
+// the value.  Values that are not Equal() may still hash the same.
+func (njv *NatJSONValue) Hash64() uint64 {
+	return hash64Proxy(njv.proxy)
+}
+
+// HashTo writes the value to h in a canonical form, which is the same
+// for values that are Equal(), including members of objects in any order
+// and numbers of different kinds with the same exact value.
+func (njv *NatJSONValue) HashTo(h hash.Hash) {
+	hashProxy(h, njv.proxy)
+}
+
 // String implements fmt.Stringer interface for NatJSONValue.
 //
 // Bool values return "true" or "false".
@@ -117,6 +599,8 @@ func (njv *NatJSONValue) Uint64() uint64 {
 //
 // Number values return with as much precision as possible.
 //
+// Object and Array values return as compact JSON text.
+//
 // Nil values return "nil".
 func (njv *NatJSONValue) String() string {
 	switch njv.proxy.(type) {
@@ -130,13 +614,140 @@ func (njv *NatJSONValue) String() string {
 		return strconv.FormatUint(njv.proxy.(uint64), 10)
 	case float64:
 		return strconv.FormatFloat(njv.proxy.(float64), 'g', -1, 64)
+	case *big.Int:
+		return njv.proxy.(*big.Int).String()
+	case *big.Float:
+		return njv.proxy.(*big.Float).Text('g', -1)
+	case json.Number:
+		return njv.proxy.(json.Number).String()
+	case *natObject, []NatJSONValue:
+		text, _ := njv.appendJSON(nil)
+		return string(text)
 	default:
 		return "nil"
 	}
 }
 
+// Literal returns the original text of a number value decoded with
+// NatDecodeOptions.RetainLiterals, e.g. "1.50", "1E3" or "-0", or "" if
+// the value is not a number or its text was not retained.
+func (njv *NatJSONValue) Literal() string {
+	return njv.literal
+}
+
+// MarshalJSON implements the json.Marshaler interface for NatJSONValue.
+// It has a value receiver so that NatJSONValue struct fields marshal
+// correctly even when the enclosing struct is not addressable.
+//
+// Nil values encode as null.
+//
+// String values encode as double-quoted JSON strings.
+//
+// Int64 and Uint64 values encode as integers.
+//
+// Float64 values encode with the fewest digits that decode to the same
+// value, with a trailing ".0" added if needed so they decode as Float64
+// values again.  Infinite and NaN values cannot be encoded,
+// and return ErrUnsupportedValue.
+//
+// BigInt, BigFloat and Number values encode as they would for
+// BigJSONValue, with Number values encoding as their original text.
+//
+// Numbers decoded with NatDecodeOptions.RetainLiterals encode as their
+// original text instead.
+func (njv NatJSONValue) MarshalJSON() ([]byte, error) {
+	return njv.appendJSON(nil)
+}
+
+// appendJSON appends the compact JSON text of the value to buf.
+// Values that cannot be encoded are appended in their String() form,
+// and ErrUnsupportedValue is returned after the rest is appended.
+func (njv *NatJSONValue) appendJSON(buf []byte) ([]byte, error) {
+	var err error
+	if njv.literal != "" {
+		return append(buf, njv.literal...), nil
+	}
+	switch njv.proxy.(type) {
+	case nil:
+		buf = append(buf, "null"...)
+	case string:
+		buf = appendJSONString(buf, njv.proxy.(string))
+	case float64:
+		f64 := njv.proxy.(float64)
+		if math.IsInf(f64, 0) || math.IsNaN(f64) {
+			err = ErrUnsupportedValue
+		}
+		buf = appendJSONFloat(buf, strconv.FormatFloat(f64, 'g', -1, 64))
+	case *big.Float:
+		bigf := njv.proxy.(*big.Float)
+		buf = appendJSONFloat(buf, bigf.Text('g', -1))
+	case *natObject:
+		obj := njv.proxy.(*natObject)
+		buf = append(buf, '{')
+		for idx, key := range obj.keys {
+			if idx > 0 {
+				buf = append(buf, ',')
+			}
+			buf = appendJSONString(buf, key)
+			buf = append(buf, ':')
+			var memberErr error
+			buf, memberErr = obj.members[key].appendJSON(buf)
+			if err == nil {
+				err = memberErr
+			}
+		}
+		buf = append(buf, '}')
+	case []NatJSONValue:
+		arr := njv.proxy.([]NatJSONValue)
+		buf = append(buf, '[')
+		for idx := range arr {
+			if idx > 0 {
+				buf = append(buf, ',')
+			}
+			var elemErr error
+			buf, elemErr = arr[idx].appendJSON(buf)
+			if err == nil {
+				err = elemErr
+			}
+		}
+		buf = append(buf, ']')
+	default:
+		buf = append(buf, njv.String()...)
+	}
+	return buf, err
+}
+
+// Canonicalize returns the canonical JSON text of the value, per the
+// JSON Canonicalization Scheme of RFC 8785, suitable for hashing or
+// signing: object members sorted by the UTF-16 code units of their keys,
+// no whitespace, strings with only the required escapes, and numbers
+// formatted as ECMAScript formats the nearest float64.  Retained literals
+// are ignored.
+//
+// RFC 8785 numbers are IEEE 754 doubles, so numbers are canonicalized as
+// if parsed by JSON.parse().  Float64 values are written exactly, and
+// BigFloat values and Number values with a fraction or exponent round to
+// the nearest float64.  Int64, Uint64 and BigInt values, and Number
+// values without, are never rounded: integers beyond 2^53 that are
+// exactly a float64 are written as ECMAScript does, e.g. 2^60 as
+// "1152921504606847000", but any other, e.g. the 64-bit ID
+// 9007199254740993, returns ErrPrecisionLoss, and should be stored as a
+// string instead.
+//
+// Returns nil and ErrPrecisionLoss for such integers, ErrOverflow for
+// numbers beyond the range of float64, and ErrUnsupportedValue for
+// infinite and NaN values and strings or keys of invalid UTF-8.
+func (njv *NatJSONValue) Canonicalize() ([]byte, error) {
+	buf, err := appendCanonical(nil, njv.proxy)
+	if err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
 // DecodeJSONValue decodes a JSON value, and returns itself.
-// Results are undefined if error is returned.
+// Results are undefined if error is returned, which is always
+// a *DecodeError.
 //
 // The text "null" is decoded as a nil value.
 //
@@ -144,54 +755,170 @@ func (njv *NatJSONValue) String() string {
 //
 // Text surrounded by double-quotes are decoded as string values.
 //
+// Text surrounded by curly braces are decoded as object values,
+// with each member decoded as a NatJSONValue.  If a key appears more
+// than once, the last member value wins.
+//
+// Text surrounded by square brackets are decoded as array values,
+// with each element decoded as a NatJSONValue.
+//
 // Number text containing period "." or the letters "e" or "E"
 // are decoded as float64 values.
 //
 // Otherwise, number text is decoded as int64 for negative values
-// or uint64 for positive values.
-// Whether text is considered a number is based on http://json.org
+// or uint64 for positive values and zero, including "-0".
+// Whether text is considered a number is based on RFC 8259.
+//
+// Numbers that do not fit are decoded according to the OverflowPolicy
+// of DefaultNatDecodeOptions, which by default saturates them to the
+// nearest int64, uint64 or float64 value and returns strconv.ErrRange.
 func (njv *NatJSONValue) DecodeJSONValue(text string) (*NatJSONValue, error) {
-	var err error
-	if text == "null" {
-		njv.proxy = nil
-	} else if text == "true" {
-		njv.proxy = true
-	} else if text == "false" {
-		njv.proxy = false
-	} else if strings.HasPrefix(text, `{`) && strings.HasSuffix(text, `}`) {
-		err = ErrNotImplemented
-	} else if strings.HasPrefix(text, `[`) && strings.HasSuffix(text, `]`) {
-		err = ErrNotImplemented
-	} else if strings.HasPrefix(text, `"`) && strings.HasSuffix(text, `"`) {
-		err = json.Unmarshal([]byte(text), &njv.proxy)
-	} else if jsonNumRegexp.MatchString(text) {
-		if strings.ContainsAny(text, ".eE") {
-			var f64 float64
-			f64, err = strconv.ParseFloat(text, 64)
-			njv.proxy = f64
-		} else if strings.HasPrefix(text, "0") || strings.HasPrefix(text, "-0") {
-			err = ErrInvalidJSON
-		} else if strings.HasPrefix(text, "-") {
-			var i64 int64
-			i64, err = strconv.ParseInt(text, 10, 64)
-			njv.proxy = i64
-		} else {
-			var u64 uint64
-			u64, err = strconv.ParseUint(text, 10, 64)
-			njv.proxy = u64
+	opts := DefaultNatDecodeOptions
+	return njv, njv.decodeJSONValue([]byte(text), &opts)
+}
+
+// decodeJSONValue decodes a JSON value using opts.
+func (njv *NatJSONValue) decodeJSONValue(text []byte, opts *NatDecodeOptions) error {
+	if ns, ok := scanNumber(text); ok {
+		njv.literal = ""
+		err := njv.decodeJSONNumber(text, &ns, opts)
+		if err == nil && opts.RetainLiterals {
+			njv.literal = string(text)
 		}
+		return newDecodeError(text, 0, njv.Kind(), err)
+	}
+	builder := natBuilder{opts: *opts}
+	if err := decodeJSONText(text, &builder); err != nil {
+		*njv = NatJSONValue{}
+		return err
+	}
+	*njv = builder.value
+	return nil
+}
 
+// decodeJSONNumber decodes the JSON number in text, already scanned as ns.
+// Out of range values are decoded according to opts.Overflow.
+func (njv *NatJSONValue) decodeJSONNumber(text []byte, ns *numScan, opts *NatDecodeOptions) error {
+	var err error
+	if ns.isFloat() {
+		var f64 float64
+		f64, err = strconv.ParseFloat(string(text), 64)
+		njv.proxy = f64
 		if numErr, ok := err.(*strconv.NumError); ok {
 			err = numErr.Err
 		}
+		if err == strconv.ErrRange && opts.Overflow == OverflowSaturate {
+			njv.proxy = math.Copysign(math.MaxFloat64, f64)
+		}
+	} else if ns.neg && (!ns.fits || ns.mag != 0) {
+		if ns.fits && ns.mag <= -math.MinInt64 {
+			njv.proxy = int64(-ns.mag)
+		} else {
+			njv.proxy = int64(math.MinInt64)
+			err = strconv.ErrRange
+		}
 	} else {
-		err = ErrInvalidJSON
+		if ns.fits {
+			njv.proxy = ns.mag
+		} else {
+			njv.proxy = uint64(math.MaxUint64)
+			err = strconv.ErrRange
+		}
+	}
+	if err != strconv.ErrRange {
+		return err
 	}
-	return njv, err
+
+	switch opts.Overflow {
+	case OverflowSaturate:
+		err = nil
+	case OverflowPromote:
+		bjv := BigJSONValue{}
+		bigOpts := DefaultBigDecodeOptions
+		bigOpts.Decimal = false
+		err = bjv.decodeJSONNumber(text, ns, &bigOpts)
+		njv.proxy = bjv.proxy
+	case OverflowKeepLiteral:
+		njv.proxy = json.Number(text)
+		err = nil
+	}
+	return err
 }
 
-// UnmarshalJSON implements the json.Unmarshaler interface for NatJSONValue
+// natBuilder builds a NatJSONValue from the parts a valueScanner finds,
+// decoding numbers using opts.
+type natBuilder struct {
+	opts   NatDecodeOptions
+	value  NatJSONValue    // the top-level value
+	frames []natBuildFrame // objects and arrays begun and not yet ended
+}
+
+// natBuildFrame is an object or array being built by a natBuilder, with
+// the key of its next member if an object.
+type natBuildFrame struct {
+	obj *natObject
+	arr []NatJSONValue
+	key string
+}
+
+// add adds value to the innermost object or array, or sets the top-level
+// value if there is none.
+func (nb *natBuilder) add(value NatJSONValue) {
+	if len(nb.frames) == 0 {
+		nb.value = value
+		return
+	}
+	frame := &nb.frames[len(nb.frames)-1]
+	if frame.obj != nil {
+		frame.obj.set(frame.key, &value)
+	} else {
+		frame.arr = append(frame.arr, value)
+	}
+}
+
+func (nb *natBuilder) beginObject() {
+	obj := &natObject{members: make(map[string]*NatJSONValue)}
+	nb.frames = append(nb.frames, natBuildFrame{obj: obj})
+}
+
+func (nb *natBuilder) beginArray() {
+	nb.frames = append(nb.frames, natBuildFrame{arr: []NatJSONValue{}})
+}
+
+func (nb *natBuilder) key(key string) {
+	nb.frames[len(nb.frames)-1].key = key
+}
+
+func (nb *natBuilder) end() {
+	frame := nb.frames[len(nb.frames)-1]
+	nb.frames = nb.frames[:len(nb.frames)-1]
+	if frame.obj != nil {
+		nb.add(NatJSONValue{proxy: frame.obj})
+	} else {
+		nb.add(NatJSONValue{proxy: frame.arr})
+	}
+}
+
+func (nb *natBuilder) scalar(proxy interface{}) {
+	nb.add(NatJSONValue{proxy: proxy})
+}
+
+func (nb *natBuilder) number(text []byte, ns *numScan) (Kind, error) {
+	var njv NatJSONValue
+	err := njv.decodeJSONNumber(text, ns, &nb.opts)
+	if err == nil && nb.opts.RetainLiterals {
+		njv.literal = string(text)
+	}
+	nb.add(njv)
+	return njv.Kind(), err
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for NatJSONValue,
+// decoding with DefaultNatDecodeOptions.
+//
+// As with BigJSONValue.UnmarshalJSON(), a *DecodeError it returns has
+// Offset and Path relative to the text of the value, not the document.
 func (njv *NatJSONValue) UnmarshalJSON(text []byte) error {
-	_, err := njv.DecodeJSONValue(string(text))
-	return err
+	opts := DefaultNatDecodeOptions
+	return njv.decodeJSONValue(text, &opts)
 }
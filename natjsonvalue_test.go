@@ -2,6 +2,9 @@ package bigjsonvalue
 
 import (
 	"encoding/json"
+	"errors"
+	"math"
+	"strings"
 	"testing"
 )
 
@@ -27,7 +30,7 @@ func TestNatDecodeJSONValue(t *testing.T) {
 	for idx, rec := range testList {
 		njv := NatJSONValue{}
 		_, err := njv.DecodeJSONValue(rec.jsonStr)
-		if err != rec.natErr {
+		if !errors.Is(err, rec.natErr) {
 			t.Errorf("%d: Unexpected err=%+v, natErr=%s, testRec=%+v\n", idx, err, rec.natErr, rec)
 		}
 		if njv.Kind() != rec.natKind {
@@ -51,6 +54,9 @@ func TestNatDecodeJSONValue(t *testing.T) {
 		if njv.Kind() == Float64 && !njv.IsFloat64() {
 			t.Errorf("%d: Unexpected IsFloat64()=%t, testRec=%+v\n", idx, njv.IsFloat64(), rec)
 		}
+		if njv.Kind() == Array && !njv.IsArray() {
+			t.Errorf("%d: Unexpected IsArray()=%t, testRec=%+v\n", idx, njv.IsArray(), rec)
+		}
 		if njv.IsNil() != rec.natIsNil {
 			t.Errorf("%d: Unexpected IsNil()=%t, testRec=%+v\n", idx, njv.IsNil(), rec)
 		}
@@ -78,6 +84,121 @@ func TestNatDecodeJSONValue(t *testing.T) {
 	}
 }
 
+func TestNatDecodeArray(t *testing.T) {
+	jsonStr := `[ 987654321987654321, -987654321987654321, -3.14, "foo", null,
+		[ true, [] ], false ]`
+	expectedKinds := []Kind{Uint64, Int64, Float64, String, Nil, Array, Bool}
+
+	njv, err := new(NatJSONValue).DecodeJSONValue(jsonStr)
+	if err != nil {
+		t.Fatalf("DecodeJSONValue err=%s", err)
+	}
+	if njv.Kind() != Array || njv.Len() != len(expectedKinds) {
+		t.Fatalf("Unexpected Kind()=%s, Len()=%d", njv.Kind(), njv.Len())
+	}
+	njv.Range(func(idx int, elem *NatJSONValue) bool {
+		if elem != njv.Index(idx) {
+			t.Errorf("Range elem %d does not match Index(%d)", idx, idx)
+		}
+		if elem.Kind() != expectedKinds[idx] {
+			t.Errorf("Unexpected elem %d Kind()=%s", idx, elem.Kind())
+		}
+		return true
+	})
+	if u64 := njv.Index(0).Uint64(); u64 != 987654321987654321 {
+		t.Errorf("Unexpected Index(0).Uint64()=%d", u64)
+	}
+	if nested := njv.Index(5); nested.Len() != 2 || nested.Index(1).Len() != 0 || !nested.Index(1).IsArray() {
+		t.Errorf("Unexpected nested Index(5)=%s", nested)
+	}
+	if njv.Index(-1) != nil || njv.Index(njv.Len()) != nil {
+		t.Errorf("Unexpected non-nil Index() out of range")
+	}
+	if str := njv.String(); str != `[987654321987654321,-987654321987654321,-3.14,"foo",null,[true,[]],false]` {
+		t.Errorf("Unexpected String()=%s", str)
+	}
+
+	count := 0
+	njv.Range(func(idx int, elem *NatJSONValue) bool {
+		count++
+		return idx < 2
+	})
+	if count != 3 {
+		t.Errorf("Range did not stop early, count=%d", count)
+	}
+
+	str := NatJSONValue{}
+	str.DecodeJSONValue(`"foo"`)
+	if str.Len() != 0 || str.Index(0) != nil {
+		t.Errorf("Unexpected array accessors on string value")
+	}
+	str.Range(func(idx int, elem *NatJSONValue) bool {
+		t.Errorf("Unexpected Range call on string value")
+		return true
+	})
+
+	invalidList := []string{
+		`[ 1, ]`,
+		`[ , 1 ]`,
+		`[ 1 2 ]`,
+		`[ 0123 ]`,
+		`[ 1 ] [ 2 ]`,
+		`[ 1 ]]`,
+	}
+	for _, text := range invalidList {
+		njv := NatJSONValue{}
+		if _, err := njv.DecodeJSONValue(text); !errors.Is(err, ErrInvalidJSON) {
+			t.Errorf("Unexpected decode of %s: err=%v", text, err)
+		}
+		if !njv.IsNil() {
+			t.Errorf("Unexpected decode of %s: Kind()=%s", text, njv.Kind())
+		}
+	}
+}
+
+func TestNatDecodeObject(t *testing.T) {
+	jsonStr := `{ "id": 987654321987654321, "name": "foo",
+		"tags": { "z": null, "m": -3.14 }, "name": "bar" }`
+
+	njv, err := new(NatJSONValue).DecodeJSONValue(jsonStr)
+	if err != nil {
+		t.Fatalf("DecodeJSONValue err=%s", err)
+	}
+	if njv.Kind() != Object || njv.Len() != 3 {
+		t.Fatalf("Unexpected Kind()=%s, Len()=%d", njv.Kind(), njv.Len())
+	}
+	if keys := strings.Join(njv.Keys(), ","); keys != "id,name,tags" {
+		t.Errorf("Unexpected Keys()=%s", keys)
+	}
+	if id, ok := njv.Member("id"); !ok || id.Kind() != Uint64 || id.Uint64() != 987654321987654321 {
+		t.Errorf("Unexpected Member(id)=%s, ok=%t", id, ok)
+	}
+	if tags, _ := njv.Member("tags"); tags.Kind() != Object {
+		t.Errorf("Unexpected Member(tags) Kind()=%s", tags.Kind())
+	} else if m, _ := tags.Member("m"); m.Kind() != Float64 {
+		t.Errorf("Unexpected tags Member(m) Kind()=%s", m.Kind())
+	}
+	if str := njv.String(); str != `{"id":987654321987654321,"name":"bar","tags":{"z":null,"m":-3.14}}` {
+		t.Errorf("Unexpected String()=%s", str)
+	}
+
+	invalidList := []string{
+		`{ "a": }`,
+		`{ "a": 1, }`,
+		`{ a: 1 }`,
+		`{ "a": 1 }}`,
+	}
+	for _, text := range invalidList {
+		njv := NatJSONValue{}
+		if _, err := njv.DecodeJSONValue(text); !errors.Is(err, ErrInvalidJSON) {
+			t.Errorf("Unexpected decode of %s: err=%v", text, err)
+		}
+		if !njv.IsNil() {
+			t.Errorf("Unexpected decode of %s: Kind()=%s", text, njv.Kind())
+		}
+	}
+}
+
 type natWalChangeRec struct {
 	ColumnValues []NatJSONValue `json:"columnvalues"`
 }
@@ -91,7 +212,8 @@ func TestNatUnmarshalJSON(t *testing.T) {
 		987654321987654321,
 		3.14,
 		-987654321987654321,
-		-987654321.987654321
+		-987654321.987654321,
+		[ 987654321987654321, -1 ]
 	] }`
 
 	expectedKinds := []Kind{
@@ -103,6 +225,7 @@ func TestNatUnmarshalJSON(t *testing.T) {
 		Float64,
 		Int64,
 		Float64,
+		Array,
 	}
 
 	var bigRec natWalChangeRec
@@ -119,6 +242,106 @@ func TestNatUnmarshalJSON(t *testing.T) {
 	}
 }
 
+func TestNatMarshalJSON(t *testing.T) {
+	for idx, rec := range testList {
+		if rec.natErr != nil {
+			continue
+		}
+		njv := NatJSONValue{}
+		njv.DecodeJSONValue(rec.jsonStr)
+		text, err := njv.MarshalJSON()
+		if err != nil {
+			t.Errorf("%d: MarshalJSON err=%s, testRec=%+v\n", idx, err, rec)
+			continue
+		}
+		njv2 := NatJSONValue{}
+		if _, err = njv2.DecodeJSONValue(string(text)); err != nil {
+			t.Errorf("%d: DecodeJSONValue(%s) err=%s, testRec=%+v\n", idx, text, err, rec)
+		}
+		if njv2.Kind() != njv.Kind() || njv2.String() != njv.String() {
+			t.Errorf("%d: Round-trip of %s gives %s=%s, testRec=%+v\n", idx, text, njv2.Kind(), njv2.String(), rec)
+		}
+	}
+
+	testList := []struct {
+		jsonStr  string
+		expected string
+	}{
+		{`null`, `null`},
+		{`"tab\there \u00e9 \"q\""`, `"tab\there é \"q\""`},
+		{`18446744073709551615`, `18446744073709551615`},
+		{`-9223372036854775808`, `-9223372036854775808`},
+		{`3e0`, `3.0`},
+		{`1E21`, `1e+21`},
+		{`[ [ ], [ 1, "a" ], 0.5 ]`, `[[],[1,"a"],0.5]`},
+	}
+	for idx, rec := range testList {
+		njv := NatJSONValue{}
+		if _, err := njv.DecodeJSONValue(rec.jsonStr); err != nil {
+			t.Errorf("%d: DecodeJSONValue(%s) err=%s", idx, rec.jsonStr, err)
+		}
+		if text, err := json.Marshal(njv); err != nil || string(text) != rec.expected {
+			t.Errorf("%d: json.Marshal(%s)=%s, err=%v, expected %s", idx, rec.jsonStr, text, err, rec.expected)
+		}
+	}
+
+	var natRec natWalChangeRec
+	jsonStr := `{"columnvalues":[null,false,"x",18446744073709551615,-9223372036854775808,-1.25,[1,[2]]]}`
+	if err := json.Unmarshal([]byte(jsonStr), &natRec); err != nil {
+		t.Fatalf("json.Unmarshal err=%s", err)
+	}
+	if text, err := json.Marshal(natRec); err != nil || string(text) != jsonStr {
+		t.Errorf("json.Marshal=%s, err=%v", text, err)
+	}
+
+	for _, f64 := range []float64{math.Inf(1), math.Inf(-1), math.NaN()} {
+		arr := NatJSONValue{proxy: []NatJSONValue{{proxy: f64}}}
+		if _, err := arr.MarshalJSON(); err != ErrUnsupportedValue {
+			t.Errorf("MarshalJSON of %g err=%v", f64, err)
+		}
+	}
+}
+
+func TestNatAccessorCopies(t *testing.T) {
+	opts := NatDecodeOptions{Overflow: OverflowPromote}
+	njv, _ := opts.DecodeJSONValue(new(NatJSONValue), `[ 123456789012345678901234567890, 1e400 ]`)
+	bigi := njv.Index(0).BigInt()
+	bigi.SetInt64(0)
+	bigf := njv.Index(1).BigFloat()
+	bigf.SetInt64(0)
+	if str := njv.String(); str != "[123456789012345678901234567890,1e+400]" {
+		t.Errorf("Changing BigInt() or BigFloat() copy changed value to %s", str)
+	}
+	if njv.Index(0).BigIntRef() != njv.Index(0).BigIntRef() || njv.Index(1).BigFloatRef() != njv.Index(1).BigFloatRef() {
+		t.Errorf("BigIntRef() or BigFloatRef() does not return the stored value")
+	}
+}
+
+func TestNatRetainLiterals(t *testing.T) {
+	text := `[1.50,-0,1E+2,0.0e0,1e400,18446744073709551616,[2.0]]`
+	opts := DefaultNatDecodeOptions
+	opts.Overflow = OverflowSaturate
+	opts.RetainLiterals = true
+	njv, err := opts.DecodeJSONValue(new(NatJSONValue), text)
+	if err != nil {
+		t.Fatalf("Unexpected err=%v", err)
+	}
+	if out, _ := njv.MarshalJSON(); string(out) != text {
+		t.Errorf("Unexpected MarshalJSON()=%s", out)
+	}
+	if elem := njv.Index(2); elem.Literal() != "1E+2" || elem.Kind() != Float64 {
+		t.Errorf("Unexpected Literal()=%s, Kind()=%s", elem.Literal(), elem.Kind())
+	}
+	if bjv, _ := njv.ToBig(); bjv.String() != text {
+		t.Errorf("Unexpected ToBig()=%s", bjv.String())
+	}
+
+	njv, _ = new(NatJSONValue).DecodeJSONValue(`[1.50]`)
+	if elem := njv.Index(0); elem.Literal() != "" {
+		t.Errorf("Unexpected Literal()=%s without RetainLiterals", elem.Literal())
+	}
+}
+
 func BenchmarkNatDecodeJSONNumbers(b *testing.B) {
 	njv := NatJSONValue{}
 	for n := 0; n < b.N; n++ {
@@ -0,0 +1,141 @@
+package bigjsonvalue
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+)
+
+// numScan describes a JSON number literal scanned by scanNumber().
+type numScan struct {
+	neg  bool   // literal starts with "-"
+	frac bool   // literal has a fraction part
+	exp  bool   // literal has an exponent part
+	fits bool   // integer part magnitude fits in uint64
+	mag  uint64 // integer part magnitude, valid only if fits is true
+}
+
+// isFloat returns true if the literal has a fraction or exponent part.
+func (ns *numScan) isFloat() bool {
+	return ns.frac || ns.exp
+}
+
+// scanNumber validates and classifies text as exactly one JSON number
+// in a single pass without allocating, per the number grammar of
+// RFC 8259 section 6:
+//
+//	number = [ minus ] int [ frac ] [ exp ]
+//	int    = zero / ( digit1-9 *DIGIT )
+//	frac   = decimal-point 1*DIGIT
+//	exp    = e [ minus / plus ] 1*DIGIT
+//
+// The magnitude of the integer part is accumulated along the way,
+// so integer literals that fit in a uint64 need no further parsing.
+// BigJSONValue and NatJSONValue both use it to decide whether
+// text is a number.  Returns false if text is not a JSON number.
+func scanNumber(text []byte) (numScan, bool) {
+	var ns numScan
+	idx, n := 0, len(text)
+	if idx < n && text[idx] == '-' {
+		ns.neg = true
+		idx++
+	}
+	if idx >= n {
+		return ns, false
+	}
+	ns.fits = true
+	if c := text[idx]; c == '0' {
+		idx++
+	} else if c >= '1' && c <= '9' {
+		for ; idx < n && isDigit(text[idx]); idx++ {
+			d := uint64(text[idx] - '0')
+			if ns.mag > (math.MaxUint64-d)/10 {
+				ns.fits = false
+			} else if ns.fits {
+				ns.mag = ns.mag*10 + d
+			}
+		}
+	} else {
+		return ns, false
+	}
+	if idx < n && text[idx] == '.' {
+		ns.frac = true
+		idx++
+		start := idx
+		for idx < n && isDigit(text[idx]) {
+			idx++
+		}
+		if idx == start {
+			return ns, false
+		}
+	}
+	if idx < n && (text[idx] == 'e' || text[idx] == 'E') {
+		ns.exp = true
+		idx++
+		if idx < n && (text[idx] == '-' || text[idx] == '+') {
+			idx++
+		}
+		start := idx
+		for idx < n && isDigit(text[idx]) {
+			idx++
+		}
+		if idx == start {
+			return ns, false
+		}
+	}
+	return ns, idx == n
+}
+
+// isDigit returns true if c is an ASCII decimal digit.
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// floatRoundTrips returns true if f64, parsed from the JSON number text,
+// has the same value as text to the fewest digits that identify it, so
+// the float64 keeps the number exactly as written, e.g. for 0.1 but not
+// for 987654321.987654321, nor for 1e-400, which underflows to zero.
+func floatRoundTrips(text []byte, f64 float64) bool {
+	mant, exp := text, []byte(nil)
+	if split := bytes.IndexAny(text, "eE"); split >= 0 {
+		mant, exp = text[:split], text[split+1:]
+	}
+	var digitBuf [32]byte
+	digits, scale := digitBuf[:0], 0
+	for idx, c := range mant {
+		switch {
+		case c == '.':
+			scale = len(mant) - 1 - idx
+		case c == '-':
+		case c != '0' || len(digits) > 0:
+			digits = append(digits, c)
+		}
+	}
+	for len(digits) > 0 && digits[len(digits)-1] == '0' {
+		digits = digits[:len(digits)-1]
+		scale--
+	}
+	if len(digits) == 0 || f64 == 0 {
+		return len(digits) == 0 && f64 == 0
+	} else if len(digits) <= 15 && math.Abs(f64) >= 0x1p-1022 {
+		// Any 15 significant digits round trip through a normal float64.
+		return true
+	}
+	exp10 := 0
+	if exp != nil {
+		var err error
+		if exp10, err = strconv.Atoi(string(exp)); err != nil {
+			return false
+		}
+	}
+	var floatBuf [32]byte
+	ftext := strconv.AppendFloat(floatBuf[:0], math.Abs(f64), 'e', -1, 64)
+	split := bytes.IndexByte(ftext, 'e')
+	fexp, _ := strconv.Atoi(string(ftext[split+1:]))
+	fdigits := ftext[:split]
+	if split > 1 {
+		// Drop the decimal point after the first digit.
+		fdigits = append(ftext[:1], ftext[2:split]...)
+	}
+	return bytes.Equal(fdigits, digits) && fexp-(len(fdigits)-1) == exp10-scale
+}
@@ -0,0 +1,301 @@
+package bigjsonvalue
+
+import (
+	"errors"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// validNumberList is a conformance corpus of number text that
+// RFC 8259 section 6 allows
+var validNumberList = []string{
+	`0`,
+	`-0`,
+	`0.0`,
+	`-0.0`,
+	`0.5`,
+	`-0.5`,
+	`0e0`,
+	`0E+1`,
+	`-0e-1`,
+	`1`,
+	`-1`,
+	`9`,
+	`10`,
+	`1234567890`,
+	`1.0`,
+	`1.25`,
+	`100.001`,
+	`1e5`,
+	`1E5`,
+	`1e+5`,
+	`1e-5`,
+	`1e05`,
+	`1e-0`,
+	`1.5e10`,
+	`-1.5E-10`,
+	`123456789012345678901234567890`,
+	`0.000000000000000000000000000001`,
+	`1e999`,
+	`-1e-999`,
+}
+
+// invalidNumberList is a conformance corpus of number-like text that
+// RFC 8259 section 6 does not allow
+var invalidNumberList = []string{
+	``,
+	`-`,
+	`+`,
+	`+1`,
+	`+0`,
+	`--1`,
+	`-+1`,
+	`00`,
+	`01`,
+	`-01`,
+	`00.5`,
+	`-00.5`,
+	`01e5`,
+	`1.`,
+	`-1.`,
+	`.1`,
+	`-.1`,
+	`1.e5`,
+	`1..2`,
+	`1.2.3`,
+	`1e`,
+	`1E`,
+	`1e+`,
+	`1e-`,
+	`1e+-2`,
+	`1ee2`,
+	`1e2.5`,
+	`1e2e3`,
+	`e5`,
+	`0x1F`,
+	`1_000`,
+	`1,000`,
+	` 1`,
+	`1 `,
+	"1\n",
+	"\t1",
+	`Infinity`,
+	`-Infinity`,
+	`NaN`,
+	"１",
+	"٣",
+	`1f`,
+	`1.0f`,
+	`0b1`,
+}
+
+var jsonNumRegexp = regexp.MustCompile(JSONNumRegexpPat)
+
+func TestScanNumber(t *testing.T) {
+	for _, text := range validNumberList {
+		if _, ok := scanNumber([]byte(text)); !ok {
+			t.Errorf("scanNumber(%q) unexpectedly returns false", text)
+		}
+		if !jsonNumRegexp.MatchString(text) {
+			t.Errorf("JSONNumRegexpPat unexpectedly does not match %q", text)
+		}
+	}
+	for _, text := range invalidNumberList {
+		if _, ok := scanNumber([]byte(text)); ok {
+			t.Errorf("scanNumber(%q) unexpectedly returns true", text)
+		}
+		if jsonNumRegexp.MatchString(text) {
+			t.Errorf("JSONNumRegexpPat unexpectedly matches %q", text)
+		}
+	}
+
+	testList := []struct {
+		text     string
+		expected numScan
+	}{
+		{`0`, numScan{fits: true}},
+		{`-0`, numScan{neg: true, fits: true}},
+		{`-12`, numScan{neg: true, fits: true, mag: 12}},
+		{`18446744073709551615`, numScan{fits: true, mag: 18446744073709551615}},
+		{`18446744073709551616`, numScan{fits: false}},
+		{`-99999999999999999999`, numScan{neg: true, fits: false}},
+		{`1.5`, numScan{frac: true, fits: true, mag: 1}},
+		{`-2e5`, numScan{neg: true, exp: true, fits: true, mag: 2}},
+		{`3.0E-1`, numScan{frac: true, exp: true, fits: true, mag: 3}},
+	}
+	for _, rec := range testList {
+		ns, ok := scanNumber([]byte(rec.text))
+		if !ns.fits {
+			ns.mag = 0 // magnitude is only valid if fits
+		}
+		if !ok || ns != rec.expected {
+			t.Errorf("scanNumber(%q)=%+v, %t, expected %+v", rec.text, ns, ok, rec.expected)
+		}
+		if ns.isFloat() != strings.ContainsAny(rec.text, ".eE") {
+			t.Errorf("scanNumber(%q).isFloat()=%t", rec.text, ns.isFloat())
+		}
+	}
+}
+
+func TestScanNumberAllocs(t *testing.T) {
+	text := []byte(`-987654321.987654321e-12`)
+	if allocs := testing.AllocsPerRun(100, func() { scanNumber(text) }); allocs != 0 {
+		t.Errorf("scanNumber allocates %v times per run", allocs)
+	}
+}
+
+func TestDecodeJSONNumberConformance(t *testing.T) {
+	for _, text := range validNumberList {
+		bjv := BigJSONValue{}
+		if _, err := bjv.DecodeJSONValue(text); err != nil {
+			t.Errorf("BigJSONValue.DecodeJSONValue(%q) err=%s", text, err)
+		}
+		if bjv.Kind() != BigInt && bjv.Kind() != BigFloat {
+			t.Errorf("BigJSONValue.DecodeJSONValue(%q) Kind()=%s", text, bjv.Kind())
+		}
+		njv := NatJSONValue{}
+		njv.DecodeJSONValue(text) // out-of-range values may return strconv.ErrRange
+		if njv.Kind() != Int64 && njv.Kind() != Uint64 && njv.Kind() != Float64 {
+			t.Errorf("NatJSONValue.DecodeJSONValue(%q) Kind()=%s", text, njv.Kind())
+		}
+	}
+	for _, text := range invalidNumberList {
+		bjv := BigJSONValue{}
+		if _, err := bjv.DecodeJSONValue(text); !errors.Is(err, ErrInvalidJSON) {
+			t.Errorf("BigJSONValue.DecodeJSONValue(%q) err=%v", text, err)
+		}
+		njv := NatJSONValue{}
+		if _, err := njv.DecodeJSONValue(text); !errors.Is(err, ErrInvalidJSON) {
+			t.Errorf("NatJSONValue.DecodeJSONValue(%q) err=%v", text, err)
+		}
+	}
+}
+
+var benchNumberList = [][]byte{
+	[]byte(`0`),
+	[]byte(`42`),
+	[]byte(`-987654321987654321`),
+	[]byte(`987654321.987654321`),
+	[]byte(`-1.5e-10`),
+}
+
+// legacyNumRegexp is the number pattern DecodeJSONValue used before
+// scanNumber replaced it.
+var legacyNumRegexp = regexp.MustCompile(`^-?\d+(\.\d+)?([eE][-+]?\d+)?$`)
+
+// legacyDecodeNumber decodes a number the way UnmarshalJSON did before
+// scanNumber replaced the regexp, from the string copy of text through
+// the checks for other kinds to the parse, so benchmarks can compare the
+// two code paths.
+func legacyDecodeNumber(text []byte, useBig bool) (interface{}, error) {
+	str := string(text)
+	if str == "null" || str == "true" || str == "false" ||
+		strings.HasPrefix(str, `{`) && strings.HasSuffix(str, `}`) ||
+		strings.HasPrefix(str, `[`) && strings.HasSuffix(str, `]`) ||
+		strings.HasPrefix(str, `"`) && strings.HasSuffix(str, `"`) ||
+		!legacyNumRegexp.MatchString(str) {
+		return nil, ErrInvalidJSON
+	}
+	if strings.ContainsAny(str, ".eE") && useBig {
+		bigf := new(big.Float).SetPrec(128)
+		_, _, err := bigf.Parse(str, 10)
+		return bigf, err
+	} else if strings.ContainsAny(str, ".eE") {
+		return strconv.ParseFloat(str, 64)
+	} else if strings.HasPrefix(str, "0") || strings.HasPrefix(str, "-0") {
+		return nil, ErrInvalidJSON
+	} else if useBig {
+		bigi := new(big.Int)
+		err := bigi.UnmarshalJSON([]byte(str))
+		return bigi, err
+	} else if strings.HasPrefix(str, "-") {
+		return strconv.ParseInt(str, 10, 64)
+	}
+	return strconv.ParseUint(str, 10, 64)
+}
+
+func TestDecodeNumberAllocs(t *testing.T) {
+	// Only storing a float64 or int64 of 256 or more in the value
+	// allocates, as the text is never copied.
+	testList := []struct {
+		text   string
+		allocs float64
+	}{
+		{`42`, 0},
+		{`-987654321987654321`, 1},
+		{`-1.5e-10`, 1},
+		{`0.30000000000000004`, 1},
+	}
+	for _, rec := range testList {
+		text := []byte(rec.text)
+		njv, hjv := NatJSONValue{}, HybJSONValue{}
+		if allocs := testing.AllocsPerRun(100, func() { njv.UnmarshalJSON(text) }); allocs != rec.allocs {
+			t.Errorf("NatJSONValue.UnmarshalJSON(%s) allocates %v times per run", rec.text, allocs)
+		}
+		if allocs := testing.AllocsPerRun(100, func() { hjv.UnmarshalJSON(text) }); allocs != rec.allocs {
+			t.Errorf("HybJSONValue.UnmarshalJSON(%s) allocates %v times per run", rec.text, allocs)
+		}
+	}
+}
+
+func BenchmarkScanNumber(b *testing.B) {
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		for _, text := range benchNumberList {
+			scanNumber(text)
+		}
+	}
+}
+
+func BenchmarkRegexpNumber(b *testing.B) {
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		for _, text := range benchNumberList {
+			str := string(text)
+			if legacyNumRegexp.MatchString(str) {
+				strings.ContainsAny(str, ".eE")
+			}
+		}
+	}
+}
+
+func BenchmarkNatUnmarshalJSONNumbers(b *testing.B) {
+	b.ReportAllocs()
+	njv := NatJSONValue{}
+	for n := 0; n < b.N; n++ {
+		for _, text := range benchNumberList {
+			njv.UnmarshalJSON(text)
+		}
+	}
+}
+
+func BenchmarkNatLegacyDecodeNumbers(b *testing.B) {
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		for _, text := range benchNumberList {
+			legacyDecodeNumber(text, false)
+		}
+	}
+}
+
+func BenchmarkBigUnmarshalJSONNumbers(b *testing.B) {
+	b.ReportAllocs()
+	bjv := BigJSONValue{}
+	for n := 0; n < b.N; n++ {
+		for _, text := range benchNumberList {
+			bjv.UnmarshalJSON(text)
+		}
+	}
+}
+
+func BenchmarkBigLegacyDecodeNumbers(b *testing.B) {
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		for _, text := range benchNumberList {
+			legacyDecodeNumber(text, true)
+		}
+	}
+}
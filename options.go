@@ -0,0 +1,173 @@
+package bigjsonvalue
+
+import (
+	"math"
+	"math/big"
+)
+
+// BigDecodeOptions controls how BigJSONValue decodes JSON numbers.
+type BigDecodeOptions struct {
+	// Prec is the mantissa precision in bits of decoded big.Float values.
+	// A Prec of 0 is treated as 64, same as big.Float.Parse().
+	// Ignored if AutoPrec is true.
+	Prec uint
+
+	// Mode is the rounding mode of decoded big.Float values.
+	Mode big.RoundingMode
+
+	// AutoPrec picks the precision of each decoded big.Float value so
+	// that every significant digit of its literal is kept, and formatting
+	// the value with Text('g', -1) gives back the same digits.
+	AutoPrec bool
+
+	// Decimal decodes fractional and exponent number text as exact
+	// Decimal values instead of big.Float values, so that numbers such as
+	// 19.99 are held exactly.  Prec, Mode and AutoPrec are then unused.
+	Decimal bool
+
+	// RetainLiterals keeps the original text of each decoded number,
+	// which Literal() returns and MarshalJSON() encodes the number as,
+	// so that re-encoded numbers are byte-for-byte identical to the
+	// decoded ones, e.g. 1.50, 1E3 or -0.
+	RetainLiterals bool
+}
+
+// DefaultBigDecodeOptions are the options used by
+// BigJSONValue.DecodeJSONValue() and BigJSONValue.UnmarshalJSON(),
+// which is what json.Unmarshal() calls.
+//
+// To change how json.Unmarshal() decodes big.Float values for the whole
+// program, set DefaultBigDecodeOptions before any decoding starts.
+// Use BigDecodeOptions.DecodeJSONValue() to override them per decode.
+var DefaultBigDecodeOptions = BigDecodeOptions{
+	Prec: 128,
+	Mode: big.ToNearestEven,
+}
+
+// DecodeJSONValue decodes a JSON value into bjv using these options,
+// and returns bjv.  Options apply to nested values as well.
+// Results are undefined if error is returned.
+// See BigJSONValue.DecodeJSONValue() for how values are decoded.
+func (opts BigDecodeOptions) DecodeJSONValue(bjv *BigJSONValue, text string) (*BigJSONValue, error) {
+	return bjv, bjv.decodeJSONValue([]byte(text), &opts)
+}
+
+// DecodeToken decodes a scalar or key token from a Tokenizer as a new
+// BigJSONValue using these options, the same as DecodeJSONValue() of its
+// Text().  Results are undefined if error is returned, which is a
+// *DecodeError with Offset relative to the stream the token is from, or
+// ErrKindMismatch for tokens starting or ending an object or array, whose
+// values span many tokens.
+func (opts BigDecodeOptions) DecodeToken(tok *Token) (*BigJSONValue, error) {
+	bjv := new(BigJSONValue)
+	if tok.isContainer() {
+		return bjv, ErrKindMismatch
+	}
+	return bjv, offsetDecodeError(bjv.decodeJSONValue([]byte(tok.text), &opts), tok.Offset)
+}
+
+// precFor returns the big.Float precision to decode number text with.
+func (opts *BigDecodeOptions) precFor(text []byte) uint {
+	if !opts.AutoPrec {
+		return opts.Prec
+	}
+	return autoPrec(text)
+}
+
+// autoPrec returns a big.Float precision large enough for every
+// significant digit of number text to survive a round-trip,
+// i.e. at least 1 bit more than digits * log2(10).
+func autoPrec(text []byte) uint {
+	digits, leading := 0, true
+	for idx := 0; idx < len(text); idx++ {
+		c := text[idx]
+		if c == 'e' || c == 'E' {
+			break
+		}
+		if c < '0' || c > '9' || (leading && c == '0') {
+			continue
+		}
+		leading = false
+		digits++
+	}
+	if digits == 0 {
+		digits = 1
+	}
+	prec := uint(math.Ceil(float64(digits)*math.Log2(10))) + 2
+	if prec > big.MaxPrec {
+		prec = big.MaxPrec
+	}
+	return prec
+}
+
+// OverflowPolicy selects what NatJSONValue does with numbers that do not
+// fit in an int64, uint64 or float64.
+type OverflowPolicy uint
+
+// OverflowPolicy enumeration constants
+const (
+	// OverflowError saturates out of range numbers to the nearest int64,
+	// uint64 or float64 value (±Inf for floats), and returns
+	// strconv.ErrRange
+	OverflowError OverflowPolicy = iota
+
+	// OverflowSaturate saturates out of range numbers to the nearest
+	// int64, uint64 or finite float64 value, without returning an error
+	OverflowSaturate
+
+	// OverflowPromote decodes out of range numbers as big.Int or big.Float
+	// values the same way BigJSONValue does, so Kind() returns BigInt or
+	// BigFloat
+	OverflowPromote
+
+	// OverflowKeepLiteral keeps out of range numbers as their original
+	// text in a json.Number, so Kind() returns Number
+	OverflowKeepLiteral
+)
+
+// NatDecodeOptions controls how NatJSONValue decodes JSON numbers.
+type NatDecodeOptions struct {
+	// Overflow is the policy for numbers that do not fit in an int64,
+	// uint64 or float64.
+	Overflow OverflowPolicy
+
+	// RetainLiterals keeps the original text of each decoded number,
+	// which Literal() returns and MarshalJSON() encodes the number as,
+	// so that re-encoded numbers are byte-for-byte identical to the
+	// decoded ones, e.g. 1.50, 1E3 or -0.  The text is kept even for
+	// numbers that are rounded or out of range.
+	RetainLiterals bool
+}
+
+// DefaultNatDecodeOptions are the options used by
+// NatJSONValue.DecodeJSONValue() and NatJSONValue.UnmarshalJSON(),
+// which is what json.Unmarshal() calls.
+//
+// To change how json.Unmarshal() handles out of range numbers for the
+// whole program, set DefaultNatDecodeOptions before any decoding starts.
+// Use NatDecodeOptions.DecodeJSONValue() to override them per decode.
+var DefaultNatDecodeOptions = NatDecodeOptions{
+	Overflow: OverflowError,
+}
+
+// DecodeJSONValue decodes a JSON value into njv using these options,
+// and returns njv.  Options apply to nested values as well.
+// Results are undefined if error is returned.
+// See NatJSONValue.DecodeJSONValue() for how values are decoded.
+func (opts NatDecodeOptions) DecodeJSONValue(njv *NatJSONValue, text string) (*NatJSONValue, error) {
+	return njv, njv.decodeJSONValue([]byte(text), &opts)
+}
+
+// DecodeToken decodes a scalar or key token from a Tokenizer as a new
+// NatJSONValue using these options, the same as DecodeJSONValue() of its
+// Text().  Results are undefined if error is returned, which is a
+// *DecodeError with Offset relative to the stream the token is from, or
+// ErrKindMismatch for tokens starting or ending an object or array, whose
+// values span many tokens.
+func (opts NatDecodeOptions) DecodeToken(tok *Token) (*NatJSONValue, error) {
+	njv := new(NatJSONValue)
+	if tok.isContainer() {
+		return njv, ErrKindMismatch
+	}
+	return njv, offsetDecodeError(njv.decodeJSONValue([]byte(tok.text), &opts), tok.Offset)
+}
@@ -0,0 +1,161 @@
+package bigjsonvalue
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strconv"
+	"testing"
+)
+
+func TestBigDecodeOptions(t *testing.T) {
+	testList := []struct {
+		jsonStr  string
+		opts     BigDecodeOptions
+		expected string
+		prec     uint
+	}{
+		{`4.940656458412465441765687928682213723651e-324`, DefaultBigDecodeOptions,
+			"4.94065645841246544176568792868221372365e-324", 128},
+		{`4.940656458412465441765687928682213723651e-324`, BigDecodeOptions{AutoPrec: true},
+			"4.940656458412465441765687928682213723651e-324", 135},
+		{`0.000123456789012345678901234567890123456789012345678901234567890`, BigDecodeOptions{AutoPrec: true},
+			"0.00012345678901234567890123456789012345678901234567890123456789", 202},
+		{`-1.5`, BigDecodeOptions{AutoPrec: true},
+			"-1.5", 9},
+		{`0.0`, BigDecodeOptions{AutoPrec: true},
+			"0", 6},
+		{`0.1`, BigDecodeOptions{Prec: 8, Mode: big.ToZero},
+			"0.0996", 8},
+		{`-0.1`, BigDecodeOptions{Prec: 8, Mode: big.ToPositiveInf},
+			"-0.0996", 8},
+		{`0.1`, BigDecodeOptions{},
+			"0.1", 64},
+	}
+	for idx, rec := range testList {
+		bjv, err := rec.opts.DecodeJSONValue(new(BigJSONValue), rec.jsonStr)
+		if err != nil {
+			t.Errorf("%d: DecodeJSONValue(%s) err=%s", idx, rec.jsonStr, err)
+			continue
+		}
+		bigf := bjv.BigFloat()
+		if bjv.String() != rec.expected || bigf.Prec() != rec.prec {
+			t.Errorf("%d: DecodeJSONValue(%s)=%s with Prec()=%d, expected %s with %d",
+				idx, rec.jsonStr, bjv.String(), bigf.Prec(), rec.expected, rec.prec)
+		}
+		if bigf.Mode() != rec.opts.Mode {
+			t.Errorf("%d: DecodeJSONValue(%s) Mode()=%s, expected %s",
+				idx, rec.jsonStr, bigf.Mode(), rec.opts.Mode)
+		}
+	}
+
+	opts := BigDecodeOptions{Prec: 16, Mode: big.ToZero}
+	obj, err := opts.DecodeJSONValue(new(BigJSONValue), `{ "pi": 3.14159265358979, "n": 12345678901234567890 }`)
+	if err != nil {
+		t.Fatalf("DecodeJSONValue err=%s", err)
+	}
+	pi, _ := obj.Member("pi")
+	if bigf := pi.BigFloat(); bigf.Prec() != 16 || bigf.Mode() != big.ToZero {
+		t.Errorf("Unexpected member Prec()=%d, Mode()=%s", bigf.Prec(), bigf.Mode())
+	}
+	if n, _ := obj.Member("n"); n.String() != "12345678901234567890" {
+		t.Errorf("Unexpected member n=%s", n)
+	}
+}
+
+func TestBigDecodeOptionsUnmarshalJSON(t *testing.T) {
+	saved := DefaultBigDecodeOptions
+	defer func() { DefaultBigDecodeOptions = saved }()
+
+	DefaultBigDecodeOptions = BigDecodeOptions{AutoPrec: true, Mode: big.ToNearestAway}
+	var bigRec bigWalChangeRec
+	jsonStr := `{ "columnvalues": [ 987654321987654321.987654321987654321987654321 ] }`
+	if err := json.Unmarshal([]byte(jsonStr), &bigRec); err != nil {
+		t.Fatalf("json.Unmarshal err=%s", err)
+	}
+	bigf := bigRec.ColumnValues[0].BigFloat()
+	if str := bigf.Text('f', 27); str != "987654321987654321.987654321987654321987654321" {
+		t.Errorf("Unexpected json.Unmarshal value=%s", str)
+	}
+	if bigf.Mode() != big.ToNearestAway {
+		t.Errorf("Unexpected json.Unmarshal Mode()=%s", bigf.Mode())
+	}
+}
+
+func TestNatDecodeOptions(t *testing.T) {
+	testList := []struct {
+		jsonStr  string
+		overflow OverflowPolicy
+		kind     Kind
+		str      string
+		err      error
+	}{
+		{`18446744073709551616`, OverflowError, Uint64, "18446744073709551615", strconv.ErrRange},
+		{`18446744073709551616`, OverflowSaturate, Uint64, "18446744073709551615", nil},
+		{`18446744073709551616`, OverflowPromote, BigInt, "18446744073709551616", nil},
+		{`18446744073709551616`, OverflowKeepLiteral, Number, "18446744073709551616", nil},
+		{`-9223372036854775809`, OverflowError, Int64, "-9223372036854775808", strconv.ErrRange},
+		{`-9223372036854775809`, OverflowSaturate, Int64, "-9223372036854775808", nil},
+		{`-9223372036854775809`, OverflowPromote, BigInt, "-9223372036854775809", nil},
+		{`-9223372036854775809`, OverflowKeepLiteral, Number, "-9223372036854775809", nil},
+		{`-1.7976931348623159e+308`, OverflowError, Float64, "-Inf", strconv.ErrRange},
+		{`-1.7976931348623159e+308`, OverflowSaturate, Float64, "-1.7976931348623157e+308", nil},
+		{`-1.7976931348623159e+308`, OverflowPromote, BigFloat, "-1.7976931348623159e+308", nil},
+		{`-1.7976931348623159e+308`, OverflowKeepLiteral, Number, "-1.7976931348623159e+308", nil},
+		{`1e400`, OverflowSaturate, Float64, "1.7976931348623157e+308", nil},
+		{`18446744073709551615`, OverflowPromote, Uint64, "18446744073709551615", nil},
+		{`-9223372036854775808`, OverflowKeepLiteral, Int64, "-9223372036854775808", nil},
+		{`1.5`, OverflowPromote, Float64, "1.5", nil},
+	}
+	for idx, rec := range testList {
+		opts := NatDecodeOptions{Overflow: rec.overflow}
+		njv, err := opts.DecodeJSONValue(new(NatJSONValue), rec.jsonStr)
+		if !errors.Is(err, rec.err) {
+			t.Errorf("%d: DecodeJSONValue(%s) err=%v, expected %v", idx, rec.jsonStr, err, rec.err)
+		}
+		if njv.Kind() != rec.kind || njv.String() != rec.str {
+			t.Errorf("%d: DecodeJSONValue(%s)=%s of kind %s, expected %s of kind %s",
+				idx, rec.jsonStr, njv.String(), njv.Kind(), rec.str, rec.kind)
+		}
+		if njv.IsBigInt() {
+			njv.BigInt() // panics with runtime error if not a big.Int
+		}
+		if njv.IsBigFloat() {
+			njv.BigFloat() // panics with runtime error if not a big.Float
+		}
+		if njv.IsNumber() && njv.Number().String() != rec.jsonStr {
+			t.Errorf("%d: DecodeJSONValue(%s) Number()=%s", idx, rec.jsonStr, njv.Number())
+		}
+		if err == nil {
+			if text, err := njv.MarshalJSON(); err != nil || string(text) != rec.str {
+				t.Errorf("%d: MarshalJSON(%s)=%s, err=%v", idx, rec.jsonStr, text, err)
+			}
+		}
+	}
+
+	opts := NatDecodeOptions{Overflow: OverflowPromote}
+	arr, err := opts.DecodeJSONValue(new(NatJSONValue), `[ 1, 99999999999999999999, -2 ]`)
+	if err != nil || arr.Index(1).Kind() != BigInt || arr.Index(0).Kind() != Uint64 || arr.Index(2).Kind() != Int64 {
+		t.Errorf("Unexpected DecodeJSONValue of array=%s, err=%v", arr, err)
+	}
+}
+
+func TestNatDecodeOptionsUnmarshalJSON(t *testing.T) {
+	saved := DefaultNatDecodeOptions
+	defer func() { DefaultNatDecodeOptions = saved }()
+
+	var natRec natWalChangeRec
+	jsonStr := `{ "columnvalues": [ 1, 18446744073709551616 ] }`
+	if err := json.Unmarshal([]byte(jsonStr), &natRec); !errors.Is(err, strconv.ErrRange) {
+		t.Errorf("json.Unmarshal err=%v", err)
+	}
+
+	DefaultNatDecodeOptions.Overflow = OverflowPromote
+	natRec = natWalChangeRec{}
+	if err := json.Unmarshal([]byte(jsonStr), &natRec); err != nil {
+		t.Fatalf("json.Unmarshal err=%s", err)
+	}
+	if njv := natRec.ColumnValues[1]; njv.Kind() != BigInt || njv.String() != "18446744073709551616" {
+		t.Errorf("Unexpected json.Unmarshal value=%s of kind %s", njv.String(), njv.Kind())
+	}
+}
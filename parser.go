@@ -0,0 +1,112 @@
+package bigjsonvalue
+
+// BigParser is an incremental parser that decodes JSON text written to it
+// in chunks, e.g. as it arrives from a network connection, and passes
+// each top-level value to a function as a BigJSONValue as soon as it is
+// complete.  Values may be split across chunks anywhere, even within a
+// number.  The text may hold any number of top-level values separated by
+// whitespace, such as newline-delimited JSON.  Values with no whitespace
+// between them, such as "1true" or "{}[]", are malformed.
+//
+// Each value is built as its text is written, in a single pass, keeping
+// only the part built so far and the text of a string, number or literal
+// split across chunks.  So parsing takes time proportional to the size
+// of the text, however deeply values are nested, the same as
+// BigJSONValue.UnmarshalJSON().  Values nested in more than MaxDepth
+// objects and arrays are rejected with ErrTooDeep.  A top-level number or
+// literal is only known to be complete at the next whitespace, or at
+// Close().
+type BigParser struct {
+	fn      func(value *BigJSONValue) error
+	builder bigBuilder
+	vs      valueScanner
+}
+
+// NewBigParser returns a BigParser decoding values with
+// DefaultBigDecodeOptions and passing them to fn.
+// See BigDecodeOptions.NewParser().
+func NewBigParser(fn func(value *BigJSONValue) error) *BigParser {
+	return DefaultBigDecodeOptions.NewParser(fn)
+}
+
+// NewParser returns a BigParser decoding values using these options and
+// passing them to fn.  The value is not used by the parser after fn
+// returns.  If fn returns an error, Write() or Close() returns it.
+func (opts BigDecodeOptions) NewParser(fn func(value *BigJSONValue) error) *BigParser {
+	parser := &BigParser{fn: fn, builder: bigBuilder{opts: opts}}
+	parser.vs.builder, parser.vs.emit = &parser.builder, parser.emit
+	return parser
+}
+
+// Write implements the io.Writer interface for BigParser, parsing chunk
+// and passing each top-level value it completes to the function given to
+// NewParser(), so io.Copy() can parse from an io.Reader.
+//
+// Returns the number of bytes of chunk parsed, and the first error from
+// the function, or a *DecodeError for a malformed or too deeply nested
+// value, with Offset relative to all the text written.  Once an error is returned, every
+// later call returns it again.
+func (parser *BigParser) Write(chunk []byte) (int, error) {
+	return parser.vs.write(chunk)
+}
+
+// Close ends the text, passing a top-level number or literal at the end
+// of it to the function given to NewParser().  Returns a *DecodeError
+// wrapping ErrInvalidJSON if the text ends within a value, otherwise the
+// same as Write().  Write() must not be called after Close().
+func (parser *BigParser) Close() error {
+	return parser.vs.close()
+}
+
+// emit passes the complete top-level value to fn.
+func (parser *BigParser) emit() error {
+	value := parser.builder.value
+	parser.builder.value = BigJSONValue{}
+	return parser.fn(&value)
+}
+
+// NatParser is an incremental parser like BigParser that passes each
+// top-level value to a function as a NatJSONValue.
+type NatParser struct {
+	fn      func(value *NatJSONValue) error
+	builder natBuilder
+	vs      valueScanner
+}
+
+// NewNatParser returns a NatParser decoding values with
+// DefaultNatDecodeOptions and passing them to fn.
+// See NatDecodeOptions.NewParser().
+func NewNatParser(fn func(value *NatJSONValue) error) *NatParser {
+	return DefaultNatDecodeOptions.NewParser(fn)
+}
+
+// NewParser returns a NatParser decoding values using these options and
+// passing them to fn.  The value is not used by the parser after fn
+// returns.  If fn returns an error, Write() or Close() returns it.
+//
+// Numbers that do not fit are decoded according to the OverflowPolicy,
+// and with OverflowError, the strconv.ErrRange is returned the same as
+// any other *DecodeError, without passing the value to fn.
+func (opts NatDecodeOptions) NewParser(fn func(value *NatJSONValue) error) *NatParser {
+	parser := &NatParser{fn: fn, builder: natBuilder{opts: opts}}
+	parser.vs.builder, parser.vs.emit = &parser.builder, parser.emit
+	return parser
+}
+
+// Write implements the io.Writer interface for NatParser.
+// See BigParser.Write().
+func (parser *NatParser) Write(chunk []byte) (int, error) {
+	return parser.vs.write(chunk)
+}
+
+// Close ends the text.  See BigParser.Close().
+func (parser *NatParser) Close() error {
+	return parser.vs.close()
+}
+
+// emit passes the complete top-level value to fn.
+func (parser *NatParser) emit() error {
+	value := parser.builder.value
+	parser.builder.value = NatJSONValue{}
+	return parser.fn(&value)
+}
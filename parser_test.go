@@ -0,0 +1,209 @@
+package bigjsonvalue
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+const parserInput = ` {"id": 18446744073709551617, "s": "a\"}\\", "x": [1.5, {"y": null}]}
+[] "str" 123456789012345678901234567890 true
+-0.25e+2 {} null`
+
+var parserOutput = []string{
+	`{"id":18446744073709551617,"s":"a\"}\\","x":[1.5,{"y":null}]}`,
+	`[]`,
+	`"str"`,
+	`123456789012345678901234567890`,
+	`true`,
+	`-25.0`,
+	`{}`,
+	`null`,
+}
+
+func TestBigParser(t *testing.T) {
+	for size := 1; size <= len(parserInput); size++ {
+		var texts []string
+		parser := NewBigParser(func(value *BigJSONValue) error {
+			text, err := value.MarshalJSON()
+			texts = append(texts, string(text))
+			return err
+		})
+		for start := 0; start < len(parserInput); start += size {
+			end := start + size
+			if end > len(parserInput) {
+				end = len(parserInput)
+			}
+			if n, err := parser.Write([]byte(parserInput[start:end])); n != end-start || err != nil {
+				t.Fatalf("%d: Write(%q)=%d, err=%v", size, parserInput[start:end], n, err)
+			}
+			// Each value must be passed on as soon as it is complete.
+			if len(texts) != countComplete(end) {
+				t.Errorf("%d: Write(%q) after %d bytes passed %d values", size, parserInput[start:end], end, len(texts))
+			}
+		}
+		if err := parser.Close(); err != nil {
+			t.Errorf("%d: Close() err=%s", size, err)
+		}
+		if text, expected := strings.Join(texts, " "), strings.Join(parserOutput, " "); text != expected {
+			t.Errorf("%d: values=%s, expected %s", size, text, expected)
+		}
+	}
+}
+
+// countComplete returns the number of values of parserInput that are known
+// to be complete after its first n bytes are written.
+func countComplete(n int) int {
+	// Text each value is known to be complete after, in order.  Top-level
+	// numbers are complete at the next whitespace, and the final null only
+	// at Close().
+	ends := []string{"}]}", "[]", `"str"`, "890 ", "true\n", "+2 ", "{}"}
+	count, end := 0, 0
+	for _, text := range ends {
+		end += strings.Index(parserInput[end:], text) + len(text)
+		if end <= n {
+			count++
+		}
+	}
+	return count
+}
+
+func TestNatParser(t *testing.T) {
+	var texts []string
+	parser := NatDecodeOptions{Overflow: OverflowPromote}.NewParser(func(value *NatJSONValue) error {
+		texts = append(texts, value.Kind().String()+"="+value.String())
+		return nil
+	})
+	chunks := []string{`[18446744073709`, `551617, 2.5] 1844674407`, `3709551616 -1`, `8446744073709551617`}
+	for _, chunk := range chunks {
+		if _, err := parser.Write([]byte(chunk)); err != nil {
+			t.Fatalf("Write(%s) err=%s", chunk, err)
+		}
+	}
+	if text := strings.Join(texts, " "); text != "Array=[18446744073709551617,2.5] BigInt=18446744073709551616" {
+		t.Errorf("values before Close()=%s", text)
+	}
+	if err := parser.Close(); err != nil {
+		t.Errorf("Close() err=%s", err)
+	}
+	if text := texts[len(texts)-1]; text != "BigInt=-18446744073709551617" {
+		t.Errorf("value at Close()=%s", text)
+	}
+
+	texts = nil
+	parser = NewNatParser(func(value *NatJSONValue) error {
+		texts = append(texts, value.Kind().String()+"="+value.String())
+		return nil
+	})
+	if _, err := io.Copy(parser, iotest.OneByteReader(strings.NewReader("1 -2 3.5\n"))); err != nil {
+		t.Errorf("io.Copy() err=%s", err)
+	}
+	if text := strings.Join(texts, " "); text != "Uint64=1 Int64=-2 Float64=3.5" {
+		t.Errorf("io.Copy() values=%s", text)
+	}
+}
+
+func TestParserErrors(t *testing.T) {
+	testList := []struct {
+		chunks []string
+		offset int64
+		path   string
+		err    error
+	}{
+		{[]string{`{"a": 1} {"b"`, `: [1, tru]}`}, 19, "/b/1", ErrInvalidJSON},
+		{[]string{`1 2 ,`}, 4, "", ErrInvalidJSON},
+		{[]string{`1 } 2`}, 2, "", ErrInvalidJSON},
+		{[]string{`[1] ["a\"`}, 9, "", ErrInvalidJSON},
+		{[]string{` {"a": [`, `1, 2]`}, 13, "", ErrInvalidJSON},
+		{[]string{`[1, 184467440737`, `09551616]`}, 4, "/1", strconv.ErrRange},
+		{[]string{`1true`}, 1, "", ErrInvalidJSON},
+		{[]string{`"a"`, `"b"`}, 3, "", ErrInvalidJSON},
+		{[]string{`[1]`, `[2]`}, 3, "", ErrInvalidJSON},
+		{[]string{strings.Repeat("[", MaxDepth), "[]"}, MaxDepth, strings.Repeat("/0", MaxDepth), ErrTooDeep},
+	}
+	for idx, rec := range testList {
+		count := 0
+		parser := NewNatParser(func(value *NatJSONValue) error {
+			count++
+			return nil
+		})
+		var err error
+		for _, chunk := range rec.chunks {
+			if _, err = parser.Write([]byte(chunk)); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			err = parser.Close()
+		}
+		var decErr *DecodeError
+		if !errors.As(err, &decErr) || !errors.Is(err, rec.err) {
+			t.Errorf("%d: Write(%q) err=%v", idx, rec.chunks, err)
+			continue
+		} else if decErr.Offset != rec.offset || decErr.Path != rec.path {
+			t.Errorf("%d: Write(%q) err at %d %q, expected %d %q", idx, rec.chunks,
+				decErr.Offset, decErr.Path, rec.offset, rec.path)
+		}
+		if _, again := parser.Write([]byte(" 1 ")); again != err {
+			t.Errorf("%d: Write() after err=%v", idx, again)
+		} else if again = parser.Close(); again != err {
+			t.Errorf("%d: Close() after err=%v", idx, again)
+		}
+	}
+
+	stop := errors.New("stop")
+	var texts []string
+	parser := NewBigParser(func(value *BigJSONValue) error {
+		texts = append(texts, value.String())
+		if len(texts) == 2 {
+			return stop
+		}
+		return nil
+	})
+	if n, err := parser.Write([]byte(`[1] "a" 3`)); n != 7 || err != stop {
+		t.Errorf("Write()=%d, err=%v, expected 7, %v", n, err, stop)
+	}
+	if n, err := parser.Write([]byte(`1 2 3 `)); n != 0 || err != stop {
+		t.Errorf("Write()=%d, err=%v, expected 0, %v", n, err, stop)
+	}
+}
+
+func TestParserDepth(t *testing.T) {
+	text := strings.Repeat("[", MaxDepth) + strings.Repeat("]", MaxDepth)
+	depth := 0
+	parser := NewNatParser(func(value *NatJSONValue) error {
+		for arr := value.proxy.([]NatJSONValue); len(arr) > 0; depth++ {
+			arr = arr[0].proxy.([]NatJSONValue)
+		}
+		return nil
+	})
+	if _, err := parser.Write([]byte(text)); err != nil || depth != MaxDepth-1 {
+		t.Errorf("Write() of arrays nested %d deep=%d, err=%v", MaxDepth, depth+1, err)
+	}
+	bjv := BigJSONValue{}
+	if err := bjv.UnmarshalJSON([]byte("[" + text + "]")); !errors.Is(err, ErrTooDeep) {
+		t.Errorf("UnmarshalJSON() of arrays nested %d deep err=%v", MaxDepth+1, err)
+	}
+}
+
+// BenchmarkBigParserDepth parses arrays nested to each depth, which takes
+// about the same time per byte however deep they are.
+func BenchmarkBigParserDepth(b *testing.B) {
+	for _, depth := range []int{10, 100, 1000, MaxDepth} {
+		text := []byte(strings.Repeat("[1, ", depth) + strings.Repeat("]", depth))
+		b.Run(strconv.Itoa(depth), func(b *testing.B) {
+			b.SetBytes(int64(len(text)))
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				parser := NewBigParser(func(value *BigJSONValue) error {
+					return nil
+				})
+				parser.Write(text)
+				parser.Close()
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package bigjsonvalue
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PatchError reports why a JSON Patch operation could not be applied.
+type PatchError struct {
+	Index int    // index of the operation in the patch, or -1 for the whole patch
+	Op    string // "op" member of the operation, e.g. "add", or "" if missing
+	Err   error  // ErrInvalidPatch, ErrTestFailed, or a *PointerError
+}
+
+// Error implements the error interface for PatchError.
+func (e *PatchError) Error() string {
+	if e.Index < 0 {
+		return fmt.Sprintf("cannot apply patch: %s", e.Err)
+	}
+	return fmt.Sprintf("cannot apply patch operation %d (%s): %s", e.Index, e.Op, e.Err)
+}
+
+// Unwrap returns the underlying error, so errors.Is(err, ErrTestFailed)
+// and errors.Is(err, ErrPointerNotFound) work on a PatchError.
+func (e *PatchError) Unwrap() error {
+	return e.Err
+}
+
+// ApplyPatch returns a copy of doc with the JSON Patch patch applied, per
+// RFC 6902.  patch is an array of operation objects, e.g.
+// [{"op": "replace", "path": "/id", "value": 9007199254740993}], with
+// "add", "remove", "replace", "move", "copy" and "test" operations.
+//
+// The "test" operation compares values with Equal(), so numbers must be
+// exactly equal, e.g. 9007199254740993 is not equal to 9007199254740992,
+// although both round to the same float64.
+//
+// The patch is applied atomically: doc is never changed, and if any
+// operation fails, a *PatchError is returned for it and no copy.
+func ApplyPatch(doc *BigJSONValue, patch *BigJSONValue) (*BigJSONValue, error) {
+	ops, ok := patch.proxy.([]BigJSONValue)
+	if !ok {
+		return nil, &PatchError{Index: -1, Err: ErrInvalidPatch}
+	}
+	result := doc.copyValue()
+	for idx := range ops {
+		op, err := applyPatchOp(&result, &ops[idx])
+		if err != nil {
+			return nil, &PatchError{Index: idx, Op: op, Err: err}
+		}
+	}
+	return &result, nil
+}
+
+// applyPatchOp applies the patch operation object opObj to doc, and
+// returns its "op" member.
+func applyPatchOp(doc *BigJSONValue, opObj *BigJSONValue) (string, error) {
+	var op, path, from string
+	var value *BigJSONValue
+	var ok bool
+	if member, _ := opObj.Member("op"); member != nil {
+		op, _ = member.proxy.(string)
+	}
+	if member, _ := opObj.Member("path"); member != nil {
+		path, ok = member.proxy.(string)
+	}
+	if !ok {
+		return op, ErrInvalidPatch
+	}
+	switch op {
+	case "add", "replace", "test":
+		if value, ok = opObj.Member("value"); !ok {
+			return op, ErrInvalidPatch
+		}
+	case "move", "copy":
+		member, _ := opObj.Member("from")
+		if from, ok = member.proxyOrNil().(string); !ok {
+			return op, ErrInvalidPatch
+		}
+	case "remove":
+	default:
+		return op, ErrInvalidPatch
+	}
+
+	switch op {
+	case "add":
+		return op, doc.Add(path, value)
+	case "remove":
+		return op, doc.Delete(path)
+	case "replace":
+		if _, err := doc.Get(path); err != nil {
+			return op, err
+		}
+		return op, doc.Set(path, value)
+	case "move":
+		if from == path {
+			_, err := doc.Get(from)
+			return op, err
+		} else if strings.HasPrefix(path, from+"/") {
+			return op, ErrInvalidPatch
+		}
+		moved, err := doc.Get(from)
+		if err != nil {
+			return op, err
+		}
+		movedCopy := moved.copyValue()
+		if err = doc.Delete(from); err != nil {
+			return op, err
+		}
+		return op, doc.Add(path, &movedCopy)
+	case "copy":
+		copied, err := doc.Get(from)
+		if err != nil {
+			return op, err
+		}
+		return op, doc.Add(path, copied)
+	default:
+		target, err := doc.Get(path)
+		if err != nil {
+			return op, err
+		} else if !target.Equal(value) {
+			return op, ErrTestFailed
+		}
+		return op, nil
+	}
+}
+
+// Diff returns a JSON Patch, per RFC 6902, that ApplyPatch() applies to a
+// to give a value equal to b.  Objects are patched member by member, and
+// arrays element by element after skipping any common leading and
+// trailing elements, with any other change replacing the whole value.
+// Values that are Equal() are never patched, so numbers of different
+// kinds with the same value, e.g. 1 and 1.0, are left as they are in a.
+// The patch has copies of the values in b, so b can be changed afterwards.
+func Diff(a *BigJSONValue, b *BigJSONValue) *BigJSONValue {
+	var ops []BigJSONValue
+	ops = appendDiff(ops, "", a, b)
+	if ops == nil {
+		ops = []BigJSONValue{}
+	}
+	return &BigJSONValue{proxy: ops}
+}
+
+// appendDiff appends the operations patching a to b at path to ops.
+func appendDiff(ops []BigJSONValue, path string, a *BigJSONValue, b *BigJSONValue) []BigJSONValue {
+	if a.Equal(b) {
+		return ops
+	}
+	switch a.proxy.(type) {
+	case *bigObject:
+		objB, ok := b.proxy.(*bigObject)
+		if !ok {
+			break
+		}
+		objA := a.proxy.(*bigObject)
+		for _, key := range objA.keys {
+			if _, ok := objB.members[key]; !ok {
+				ops = append(ops, newPatchOp("remove", path+"/"+escapePointerToken(key), nil))
+			}
+		}
+		for _, key := range objB.keys {
+			memberPath := path + "/" + escapePointerToken(key)
+			if memberA, ok := objA.members[key]; ok {
+				ops = appendDiff(ops, memberPath, memberA, objB.members[key])
+			} else {
+				ops = append(ops, newPatchOp("add", memberPath, objB.members[key]))
+			}
+		}
+		return ops
+	case []BigJSONValue:
+		arrB, ok := b.proxy.([]BigJSONValue)
+		if !ok {
+			break
+		}
+		arrA := a.proxy.([]BigJSONValue)
+		start := 0
+		for start < len(arrA) && start < len(arrB) && arrA[start].Equal(&arrB[start]) {
+			start++
+		}
+		endA, endB := len(arrA), len(arrB)
+		for endA > start && endB > start && arrA[endA-1].Equal(&arrB[endB-1]) {
+			endA--
+			endB--
+		}
+		idx := start
+		for ; idx < endA && idx < endB; idx++ {
+			ops = appendDiff(ops, path+"/"+strconv.Itoa(idx), &arrA[idx], &arrB[idx])
+		}
+		for n := idx; n < endA; n++ {
+			ops = append(ops, newPatchOp("remove", path+"/"+strconv.Itoa(idx), nil))
+		}
+		for ; idx < endB; idx++ {
+			ops = append(ops, newPatchOp("add", path+"/"+strconv.Itoa(idx), &arrB[idx]))
+		}
+		return ops
+	}
+	return append(ops, newPatchOp("replace", path, b))
+}
+
+// newPatchOp returns a patch operation object, with a copy of value
+// unless value is nil.
+func newPatchOp(op string, path string, value *BigJSONValue) BigJSONValue {
+	obj := &bigObject{members: make(map[string]*BigJSONValue, 3)}
+	obj.set("op", &BigJSONValue{proxy: op})
+	obj.set("path", &BigJSONValue{proxy: path})
+	if value != nil {
+		valueCopy := value.copyValue()
+		obj.set("value", &valueCopy)
+	}
+	return BigJSONValue{proxy: obj}
+}
@@ -0,0 +1,136 @@
+package bigjsonvalue
+
+import (
+	"errors"
+	"testing"
+)
+
+type patchRec struct {
+	doc    string
+	patch  string
+	result string
+	err    error
+}
+
+// Mostly examples from RFC 6902 appendix A.
+var patchList = []patchRec{
+	{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"foo":"bar","baz":"qux"}`, nil},
+	{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`, nil},
+	{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`, nil},
+	{`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`, nil},
+	{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`, nil},
+	{`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+		`[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+		`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`, nil},
+	{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+		`{"foo":["all","cows","eat","grass"]}`, nil},
+	{`{"baz":"qux","foo":["a",2,"c"]}`,
+		`[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`,
+		`{"baz":"qux","foo":["a",2,"c"]}`, nil},
+	{`{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, "", ErrTestFailed},
+	{`{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`,
+		`{"foo":"bar","child":{"grandchild":{}}}`, nil},
+	{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux","xyz":123}]`, `{"foo":"bar","baz":"qux"}`, nil},
+	{`{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, "", ErrPointerNotFound},
+	{`{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10}]`, `{"/":9,"~1":10}`, nil},
+	{`{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":"10"}]`, "", ErrTestFailed},
+	{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`, nil},
+	{`{"foo":null}`, `[{"op":"test","path":"/foo","value":null}]`, `{"foo":null}`, nil},
+	{`{"foo":1}`, `[{"op":"copy","from":"/foo","path":"/bar"}]`, `{"foo":1,"bar":1}`, nil},
+	{`{"foo":1}`, `[{"op":"move","from":"/foo","path":"/foo"}]`, `{"foo":1}`, nil},
+	{`{"foo":{"a":1}}`, `[{"op":"move","from":"/foo","path":"/foo/a/b"}]`, "", ErrInvalidPatch},
+	{`{"foo":1}`, `[{"op":"replace","path":"/bar","value":2}]`, "", ErrPointerNotFound},
+	{`{"foo":1}`, `[{"op":"replace","path":"","value":[2]}]`, `[2]`, nil},
+	{`{"foo":1}`, `[{"op":"add","path":"/bar"}]`, "", ErrInvalidPatch},
+	{`{"foo":1}`, `[{"op":"copy","path":"/bar"}]`, "", ErrInvalidPatch},
+	{`{"foo":1}`, `[{"op":"frob","path":"/bar"}]`, "", ErrInvalidPatch},
+	{`{"foo":1}`, `[{"path":"/foo"}]`, "", ErrInvalidPatch},
+	{`{"foo":1}`, `[{"op":"remove"}]`, "", ErrInvalidPatch},
+	{`{"foo":1}`, `{"op":"remove","path":"/foo"}`, "", ErrInvalidPatch},
+	{`{"id":9007199254740993}`, `[{"op":"test","path":"/id","value":9007199254740992}]`, "", ErrTestFailed},
+	{`{"id":9007199254740993}`, `[{"op":"test","path":"/id","value":9007199254740993.0}]`, `{"id":9007199254740993}`, nil},
+	{`{"price":8.95}`, `[{"op":"test","path":"/price","value":8.95}]`, `{"price":8.95}`, nil},
+	{`{"a":[1,2]}`, `[{"op":"add","path":"/a/0","value":0},{"op":"test","path":"/a/0","value":1}]`, "", ErrTestFailed},
+}
+
+func TestApplyPatch(t *testing.T) {
+	for idx, rec := range patchList {
+		doc, err := new(BigJSONValue).DecodeJSONValue(rec.doc)
+		if err != nil {
+			t.Fatalf("%d: Unexpected doc err=%v", idx, err)
+		}
+		patch, err := new(BigJSONValue).DecodeJSONValue(rec.patch)
+		if err != nil {
+			t.Fatalf("%d: Unexpected patch err=%v", idx, err)
+		}
+		result, err := ApplyPatch(doc, patch)
+		if !errors.Is(err, rec.err) {
+			t.Errorf("%d: ApplyPatch(%s) unexpected err=%v", idx, rec.patch, err)
+		} else if err == nil && result.String() != rec.result {
+			t.Errorf("%d: ApplyPatch(%s)=%s, expected %s", idx, rec.patch, result, rec.result)
+		}
+		var patchErr *PatchError
+		if err != nil && !errors.As(err, &patchErr) {
+			t.Errorf("%d: ApplyPatch(%s) err=%v, expected a *PatchError", idx, rec.patch, err)
+		}
+		if orig, _ := new(BigJSONValue).DecodeJSONValue(rec.doc); doc.String() != orig.String() {
+			t.Errorf("%d: ApplyPatch(%s) changed doc to %s", idx, rec.patch, doc)
+		}
+	}
+}
+
+func TestPatchErrorString(t *testing.T) {
+	doc, _ := new(BigJSONValue).DecodeJSONValue(`{"a":1}`)
+	patch, _ := new(BigJSONValue).DecodeJSONValue(`[{"op":"test","path":"/a","value":1},{"op":"remove","path":"/b"}]`)
+	_, err := ApplyPatch(doc, patch)
+	if err.Error() != `cannot apply patch operation 1 (remove): cannot resolve "/b" at /b in Object value: JSON Pointer value not found` {
+		t.Errorf("Unexpected Error()=%s", err)
+	}
+	_, err = ApplyPatch(doc, doc)
+	if err.Error() != "cannot apply patch: invalid JSON Patch" {
+		t.Errorf("Unexpected Error()=%s", err)
+	}
+}
+
+type diffRec struct {
+	a     string
+	b     string
+	patch string
+}
+
+var diffList = []diffRec{
+	{`{"a":1}`, `{"a":1.0}`, `[]`},
+	{`{"a":1,"b":2}`, `{"b":3,"c":4}`, `[{"op":"remove","path":"/a"},{"op":"replace","path":"/b","value":3},` +
+		`{"op":"add","path":"/c","value":4}]`},
+	{`{"a/b":{"c~d":[1]}}`, `{"a/b":{"c~d":[1,2]}}`, `[{"op":"add","path":"/a~1b/c~0d/1","value":2}]`},
+	{`[1,2,3,4,5]`, `[1,5]`, `[{"op":"remove","path":"/1"},{"op":"remove","path":"/1"},{"op":"remove","path":"/1"}]`},
+	{`[1,5]`, `[1,2,3,5]`, `[{"op":"add","path":"/1","value":2},{"op":"add","path":"/2","value":3}]`},
+	{`[1,{"x":2},3]`, `[1,{"x":9},3,4]`, `[{"op":"replace","path":"/1/x","value":9},{"op":"add","path":"/3","value":4}]`},
+	{`[1,2]`, `{"a":1}`, `[{"op":"replace","path":"","value":{"a":1}}]`},
+	{`{"id":9007199254740992}`, `{"id":9007199254740993}`, `[{"op":"replace","path":"/id","value":9007199254740993}]`},
+	{`null`, `"x"`, `[{"op":"replace","path":"","value":"x"}]`},
+	{`[]`, `[]`, `[]`},
+}
+
+func TestDiff(t *testing.T) {
+	for idx, rec := range diffList {
+		a, _ := new(BigJSONValue).DecodeJSONValue(rec.a)
+		b, _ := new(BigJSONValue).DecodeJSONValue(rec.b)
+		patch := Diff(a, b)
+		if patch.String() != rec.patch {
+			t.Errorf("%d: Diff(%s, %s)=%s, expected %s", idx, rec.a, rec.b, patch, rec.patch)
+		}
+		result, err := ApplyPatch(a, patch)
+		if err != nil || !result.Equal(b) {
+			t.Errorf("%d: ApplyPatch(Diff(%s, %s))=%s, err=%v", idx, rec.a, rec.b, result, err)
+		}
+	}
+
+	a, _ := new(BigJSONValue).DecodeJSONValue(`{"a":[1]}`)
+	b, _ := new(BigJSONValue).DecodeJSONValue(`{"a":[1,{"b":2}]}`)
+	patch := Diff(a, b)
+	b.Set("/a/1/b", NewBigString("changed"))
+	if str := patch.String(); str != `[{"op":"add","path":"/a/1","value":{"b":2}}]` {
+		t.Errorf("Changing b changed patch to %s", str)
+	}
+}
@@ -0,0 +1,94 @@
+package bigjsonvalue
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PointerError reports why a JSON Pointer could not be resolved by Get(),
+// Set(), Add() or Delete().
+type PointerError struct {
+	Pointer string // JSON Pointer being resolved
+	Path    string // prefix of Pointer up to the reference token that failed
+	Kind    Kind   // kind of the value the reference token was applied to
+	Err     error  // ErrInvalidPointer, ErrPointerNotFound, ErrInvalidIndex or ErrKindMismatch
+}
+
+// Error implements the error interface for PointerError.
+func (e *PointerError) Error() string {
+	if e.Err == ErrInvalidPointer {
+		return fmt.Sprintf("cannot resolve %q: %s", e.Pointer, e.Err)
+	}
+	return fmt.Sprintf("cannot resolve %q at %s in %s value: %s", e.Pointer, e.Path, e.Kind, e.Err)
+}
+
+// Unwrap returns the underlying error, so errors.Is(err, ErrPointerNotFound)
+// and similar work on a PointerError.
+func (e *PointerError) Unwrap() error {
+	return e.Err
+}
+
+// newPointerError returns a PointerError for pointer, whose reference
+// tokens up to the one that failed are tokens, applied to a value of kind.
+func newPointerError(pointer string, tokens []string, kind Kind, err error) error {
+	var path strings.Builder
+	for _, token := range tokens {
+		path.WriteByte('/')
+		path.WriteString(escapePointerToken(token))
+	}
+	return &PointerError{Pointer: pointer, Path: path.String(), Kind: kind, Err: err}
+}
+
+// parsePointer splits a JSON Pointer into its unescaped reference tokens,
+// per RFC 6901.  Returns no tokens for "", which references the whole
+// value, or a PointerError wrapping ErrInvalidPointer if pointer does not
+// start with "/" or has a "~" not followed by "0" or "1".
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	} else if pointer[0] != '/' {
+		return nil, &PointerError{Pointer: pointer, Err: ErrInvalidPointer}
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for idx, token := range tokens {
+		if !strings.Contains(token, "~") {
+			continue
+		}
+		for pos := 0; pos < len(token); pos++ {
+			if token[pos] == '~' && (pos+1 == len(token) || (token[pos+1] != '0' && token[pos+1] != '1')) {
+				return nil, &PointerError{Pointer: pointer, Err: ErrInvalidPointer}
+			}
+		}
+		tokens[idx] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+// arrayIndex returns the array index referenced by token in an array of
+// length n, which is n for "-", the position past the last element.
+// Returns ErrInvalidIndex if token is not "-" or a number without leading
+// zeros, or ErrPointerNotFound if it is greater than n.
+func arrayIndex(token string, n int) (int, error) {
+	if token == "-" {
+		return n, nil
+	} else if token == "" || (token[0] == '0' && len(token) > 1) ||
+		strings.TrimLeft(token, "0123456789") != "" {
+		return 0, ErrInvalidIndex
+	}
+	idx, err := strconv.Atoi(token)
+	if err != nil || idx > n {
+		return 0, ErrPointerNotFound
+	}
+	return idx, nil
+}
+
+// removeKey returns keys with key removed, keeping the order of the rest.
+func removeKey(keys []string, key string) []string {
+	for idx := range keys {
+		if keys[idx] == key {
+			return append(keys[:idx:idx], keys[idx+1:]...)
+		}
+	}
+	return keys
+}
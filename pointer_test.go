@@ -0,0 +1,197 @@
+package bigjsonvalue
+
+import (
+	"errors"
+	"testing"
+)
+
+const pointerDoc = `{"foo":["bar","baz"],"":0,"a/b":1,"c%d":2,"e^f":3,"g|h":4,"i\\j":5,"k\"l":6," ":7,"m~n":8,` +
+	`"big":{"n":123456789012345678901234567890}}`
+
+type pointerRec struct {
+	pointer string
+	result  string
+	err     error
+	path    string
+}
+
+// Examples from RFC 6901 section 5, plus errors.
+var pointerList = []pointerRec{
+	{"", pointerDoc, nil, ""},
+	{"/foo", `["bar","baz"]`, nil, ""},
+	{"/foo/0", "bar", nil, ""},
+	{"/", "0", nil, ""},
+	{"/a~1b", "1", nil, ""},
+	{"/c%d", "2", nil, ""},
+	{"/e^f", "3", nil, ""},
+	{"/g|h", "4", nil, ""},
+	{"/i\\j", "5", nil, ""},
+	{"/k\"l", "6", nil, ""},
+	{"/ ", "7", nil, ""},
+	{"/m~0n", "8", nil, ""},
+	{"/big/n", "123456789012345678901234567890", nil, ""},
+	{"foo", "", ErrInvalidPointer, ""},
+	{"/m~2n", "", ErrInvalidPointer, ""},
+	{"/m~", "", ErrInvalidPointer, ""},
+	{"/missing", "", ErrPointerNotFound, "/missing"},
+	{"/missing/0", "", ErrPointerNotFound, "/missing"},
+	{"/foo/2", "", ErrPointerNotFound, "/foo/2"},
+	{"/foo/-", "", ErrPointerNotFound, "/foo/-"},
+	{"/foo/01", "", ErrInvalidIndex, "/foo/01"},
+	{"/foo/+1", "", ErrInvalidIndex, "/foo/+1"},
+	{"/foo/bar", "", ErrInvalidIndex, "/foo/bar"},
+	{"/foo/99999999999999999999", "", ErrPointerNotFound, "/foo/99999999999999999999"},
+	{"/foo/0/x", "", ErrKindMismatch, "/foo/0/x"},
+	{"/a~1b/c~0", "", ErrKindMismatch, "/a~1b/c~0"},
+}
+
+func checkPointerErr(t *testing.T, idx int, rec pointerRec, err error) bool {
+	if !errors.Is(err, rec.err) {
+		t.Errorf("%d: Get(%s) unexpected err=%v", idx, rec.pointer, err)
+		return false
+	} else if err == nil {
+		return true
+	}
+	var ptrErr *PointerError
+	if !errors.As(err, &ptrErr) || ptrErr.Pointer != rec.pointer || ptrErr.Path != rec.path {
+		t.Errorf("%d: Get(%s) unexpected err=%+v", idx, rec.pointer, ptrErr)
+	}
+	return false
+}
+
+func TestBigGet(t *testing.T) {
+	bjv, _ := new(BigJSONValue).DecodeJSONValue(pointerDoc)
+	for idx, rec := range pointerList {
+		got, err := bjv.Get(rec.pointer)
+		if checkPointerErr(t, idx, rec, err) && got.String() != rec.result {
+			t.Errorf("%d: Get(%s)=%s, expected %s", idx, rec.pointer, got, rec.result)
+		}
+	}
+	if got, _ := bjv.Get("/big/n"); got.Kind() != BigInt {
+		t.Errorf("Unexpected Get(/big/n) Kind()=%s", got.Kind())
+	}
+}
+
+func TestNatGet(t *testing.T) {
+	opts := NatDecodeOptions{Overflow: OverflowKeepLiteral}
+	njv, _ := opts.DecodeJSONValue(new(NatJSONValue), pointerDoc)
+	for idx, rec := range pointerList {
+		got, err := njv.Get(rec.pointer)
+		if checkPointerErr(t, idx, rec, err) && got.String() != rec.result {
+			t.Errorf("%d: Get(%s)=%s, expected %s", idx, rec.pointer, got, rec.result)
+		}
+	}
+}
+
+type pointerOpRec struct {
+	op      string
+	pointer string
+	value   string
+	result  string
+	err     error
+}
+
+var pointerOpList = []pointerOpRec{
+	{"set", "", `[1]`, `[1]`, nil},
+	{"set", "/a", `{"b":1}`, `{"a":{"b":1},"arr":[1,2,3]}`, nil},
+	{"set", "/new", `true`, `{"a":0,"arr":[1,2,3],"new":true}`, nil},
+	{"set", "/arr/1", `null`, `{"a":0,"arr":[1,null,3]}`, nil},
+	{"set", "/arr/3", `4`, "", ErrPointerNotFound},
+	{"set", "/arr/-", `4`, "", ErrPointerNotFound},
+	{"set", "/a/b", `4`, "", ErrKindMismatch},
+	{"set", "/x/y", `4`, "", ErrPointerNotFound},
+	{"add", "", `"s"`, "s", nil},
+	{"add", "/a", `1`, `{"a":1,"arr":[1,2,3]}`, nil},
+	{"add", "/a~1b", `1`, `{"a":0,"arr":[1,2,3],"a/b":1}`, nil},
+	{"add", "/arr/0", `0`, `{"a":0,"arr":[0,1,2,3]}`, nil},
+	{"add", "/arr/1", `[]`, `{"a":0,"arr":[1,[],2,3]}`, nil},
+	{"add", "/arr/3", `4`, `{"a":0,"arr":[1,2,3,4]}`, nil},
+	{"add", "/arr/-", `4`, `{"a":0,"arr":[1,2,3,4]}`, nil},
+	{"add", "/arr/4", `4`, "", ErrPointerNotFound},
+	{"add", "/arr/x", `4`, "", ErrInvalidIndex},
+	{"add", "/a/0", `4`, "", ErrKindMismatch},
+	{"delete", "", "", "nil", nil},
+	{"delete", "/a", "", `{"arr":[1,2,3]}`, nil},
+	{"delete", "/arr/0", "", `{"a":0,"arr":[2,3]}`, nil},
+	{"delete", "/arr/2", "", `{"a":0,"arr":[1,2]}`, nil},
+	{"delete", "/arr/3", "", "", ErrPointerNotFound},
+	{"delete", "/arr/-", "", "", ErrPointerNotFound},
+	{"delete", "/b", "", "", ErrPointerNotFound},
+	{"delete", "/a/b", "", "", ErrKindMismatch},
+	{"delete", "a", "", "", ErrInvalidPointer},
+}
+
+func TestBigPointerOps(t *testing.T) {
+	for idx, rec := range pointerOpList {
+		bjv, _ := new(BigJSONValue).DecodeJSONValue(`{"a":0,"arr":[1,2,3]}`)
+		value, _ := new(BigJSONValue).DecodeJSONValue(rec.value)
+		var err error
+		switch rec.op {
+		case "set":
+			err = bjv.Set(rec.pointer, value)
+		case "add":
+			err = bjv.Add(rec.pointer, value)
+		case "delete":
+			err = bjv.Delete(rec.pointer)
+		}
+		if !errors.Is(err, rec.err) {
+			t.Errorf("%d: %s(%s) unexpected err=%v", idx, rec.op, rec.pointer, err)
+		} else if err == nil && bjv.String() != rec.result {
+			t.Errorf("%d: %s(%s) result=%s, expected %s", idx, rec.op, rec.pointer, bjv, rec.result)
+		} else if err != nil && bjv.String() != `{"a":0,"arr":[1,2,3]}` {
+			t.Errorf("%d: %s(%s) failed but changed value to %s", idx, rec.op, rec.pointer, bjv)
+		}
+	}
+}
+
+func TestNatPointerOps(t *testing.T) {
+	for idx, rec := range pointerOpList {
+		njv, _ := new(NatJSONValue).DecodeJSONValue(`{"a":0,"arr":[1,2,3]}`)
+		value, _ := new(NatJSONValue).DecodeJSONValue(rec.value)
+		var err error
+		switch rec.op {
+		case "set":
+			err = njv.Set(rec.pointer, value)
+		case "add":
+			err = njv.Add(rec.pointer, value)
+		case "delete":
+			err = njv.Delete(rec.pointer)
+		}
+		if !errors.Is(err, rec.err) {
+			t.Errorf("%d: %s(%s) unexpected err=%v", idx, rec.op, rec.pointer, err)
+		} else if err == nil && njv.String() != rec.result {
+			t.Errorf("%d: %s(%s) result=%s, expected %s", idx, rec.op, rec.pointer, njv, rec.result)
+		} else if err != nil && njv.String() != `{"a":0,"arr":[1,2,3]}` {
+			t.Errorf("%d: %s(%s) failed but changed value to %s", idx, rec.op, rec.pointer, njv)
+		}
+	}
+}
+
+func TestPointerOpsCopy(t *testing.T) {
+	bjv, _ := new(BigJSONValue).DecodeJSONValue(`{"a":[1]}`)
+	value, _ := new(BigJSONValue).DecodeJSONValue(`{"b":2}`)
+	bjv.Set("/c", value)
+	bjv.Add("/a/0", value)
+	value.Set("/b", NewBigString("changed"))
+	if str := bjv.String(); str != `{"a":[{"b":2},1],"c":{"b":2}}` {
+		t.Errorf("Changing value changed result to %s", str)
+	}
+
+	a, _ := bjv.Get("/a")
+	bjv.Set("", a)
+	if str := bjv.String(); str != `[{"b":2},1]` {
+		t.Errorf("Setting whole value to its own member gave %s", str)
+	}
+}
+
+func TestPointerErrorString(t *testing.T) {
+	bjv, _ := new(BigJSONValue).DecodeJSONValue(`{"a":[1]}`)
+	_, err := bjv.Get("/a/b/c")
+	if err.Error() != `cannot resolve "/a/b/c" at /a/b in Array value: invalid array index` {
+		t.Errorf("Unexpected Error()=%s", err)
+	}
+	_, err = bjv.Get("a")
+	if err.Error() != `cannot resolve "a": invalid JSON Pointer` {
+		t.Errorf("Unexpected Error()=%s", err)
+	}
+}
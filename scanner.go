@@ -0,0 +1,406 @@
+package bigjsonvalue
+
+import (
+	"bytes"
+	"encoding/json"
+	"unicode/utf8"
+)
+
+// valueBuilder builds values of one type from the parts of JSON text a
+// valueScanner finds, in document order.  Each value is added to the
+// innermost object or array begun and not yet ended, or is the top-level
+// value if there is none.
+type valueBuilder interface {
+	beginObject()
+	beginArray()
+	key(key string) // sets the key of the next member of the object
+	end()           // ends the innermost object or array
+	scalar(proxy interface{})
+	number(text []byte, ns *numScan) (Kind, error)
+}
+
+// Tokens a valueScanner may be within.
+const (
+	scanTokNone = iota
+	scanTokString
+	scanTokNumber
+	scanTokLiteral
+)
+
+// scanStateEnd is the valueScanner state after the value, when nothing
+// more may come.  Other states are those of Tokenizer.
+const scanStateEnd = tokStateNext + 1
+
+// valueScanner decodes JSON text written to it in chunks in a single
+// pass, passing the parts of each value to a valueBuilder as it finds
+// them.  Only the text of a string, number or literal split across
+// chunks is kept, so each byte is scanned once however deeply values
+// are nested, and values nested more than MaxDepth deep are rejected.
+//
+// If emit is nil, the text must be exactly one value, without whitespace
+// around it.  Otherwise it may hold any number of top-level values
+// separated by whitespace, and emit is called as each is complete.
+type valueScanner struct {
+	builder valueBuilder
+	state   int        // what may come next, one of tokStateTop, etc.
+	frames  []tokFrame // objects and arrays enclosing the next byte
+	offset  int64      // byte offset of the chunk being scanned
+	tok     int        // token being scanned, one of scanTokNone, etc.
+	tokAt   int64      // byte offset of the token being scanned
+	key     bool       // true if the string being scanned is a key
+	plain   bool       // true if the string being scanned has no escapes
+	escape  int        // -1 after a backslash, or hex digits left in "\u"
+	buf     []byte     // text of the token from earlier chunks
+	kind    Kind       // kind of the value, once complete
+	adjoin  bool       // true after a top-level value, until whitespace
+	err     error      // sticky error, returned by every later write()
+
+	// emit is called when each top-level value is complete, or is nil.
+	emit func() error
+}
+
+// decodeJSONText decodes text, which must be exactly one JSON value,
+// passing its parts to builder.  Returns a *DecodeError if text is
+// malformed or builder cannot decode a number.
+func decodeJSONText(text []byte, builder valueBuilder) error {
+	vs := valueScanner{builder: builder}
+	if _, err := vs.write(text); err != nil {
+		return err
+	}
+	return vs.close()
+}
+
+// write scans chunk, continuing from the previous chunk.  Returns the
+// number of bytes of chunk scanned, which is less than len(chunk) only
+// if an error is returned.
+func (vs *valueScanner) write(chunk []byte) (int, error) {
+	if vs.err != nil {
+		return 0, vs.err
+	}
+	start := 0 // index of the token being scanned within chunk
+	for idx := 0; idx < len(chunk); {
+		var err error
+		if vs.tok == scanTokNone {
+			start = idx
+			idx, err = vs.scanByte(chunk, idx)
+		} else {
+			idx, err = vs.scanToken(chunk, start, idx)
+		}
+		if err != nil {
+			vs.err = err
+			return idx, err
+		}
+	}
+	if vs.tok != scanTokNone {
+		vs.buf = append(vs.buf, chunk[start:]...)
+	}
+	vs.offset += int64(len(chunk))
+	return len(chunk), nil
+}
+
+// close ends the text, completing a number or literal at the end of it.
+// Returns a *DecodeError wrapping ErrInvalidJSON if the text ends within
+// a value, or holds no value and emit is nil.
+func (vs *valueScanner) close() error {
+	if vs.err != nil {
+		return vs.err
+	}
+	var err error
+	if vs.tok == scanTokNumber || vs.tok == scanTokLiteral {
+		err = vs.endToken(vs.buf)
+	}
+	if err == nil && len(vs.frames) > 0 {
+		err = vs.containerError(nil, 0)
+	} else if err == nil && (vs.tok == scanTokString || vs.state == tokStateTop && vs.emit == nil) {
+		err = &DecodeError{Offset: vs.offset, Err: ErrInvalidJSON}
+	}
+	vs.err = err
+	return err
+}
+
+// scanByte scans the byte at idx in chunk outside any token, and returns
+// the index of the next byte to scan.
+func (vs *valueScanner) scanByte(chunk []byte, idx int) (int, error) {
+	c := chunk[idx]
+	if isSpace(c) && vs.state != scanStateEnd && (vs.state != tokStateTop || vs.emit != nil) {
+		vs.adjoin = false
+		return idx + 1, nil
+	}
+	switch vs.state {
+	case tokStateTop:
+		if vs.adjoin {
+			// Top-level values must be separated by whitespace.
+			return idx, vs.syntaxError(chunk, idx, "", Nil)
+		}
+	case scanStateEnd:
+		return idx, vs.syntaxError(chunk, idx, "", vs.kind)
+	case tokStateColon:
+		if c != ':' {
+			return idx, vs.containerError(chunk, idx)
+		}
+		vs.state = tokStateValue
+		return idx + 1, nil
+	case tokStateNext:
+		frame := &vs.frames[len(vs.frames)-1]
+		if c == ',' {
+			if vs.state = tokStateKey; frame.delim == '[' {
+				vs.state = tokStateValue
+				frame.index++
+			}
+			return idx + 1, nil
+		} else if c == '}' && frame.delim == '{' || c == ']' && frame.delim == '[' {
+			return idx + 1, vs.endContainer()
+		}
+		return idx, vs.containerError(chunk, idx)
+	case tokStateFirstElem:
+		if c == ']' {
+			return idx + 1, vs.endContainer()
+		}
+		vs.frames[len(vs.frames)-1].index = 0
+	case tokStateFirstKey, tokStateKey:
+		if c == '}' && vs.state == tokStateFirstKey {
+			return idx + 1, vs.endContainer()
+		} else if c != '"' {
+			return idx, vs.containerError(chunk, idx)
+		}
+		vs.beginToken(scanTokString, idx)
+		vs.key = true
+		return idx + 1, nil
+	}
+
+	switch {
+	case (c == '{' || c == '[') && len(vs.frames) == MaxDepth:
+		kind := Object
+		if c == '[' {
+			kind = Array
+		}
+		return idx, &DecodeError{
+			Offset:  vs.offset + int64(idx),
+			Path:    framesPath(vs.frames),
+			Snippet: snippet(chunk[idx:]),
+			Kind:    kind,
+			Err:     ErrTooDeep,
+		}
+	case c == '{' || c == '[':
+		vs.frames = append(vs.frames, tokFrame{delim: c, index: -1})
+		if vs.state = tokStateFirstKey; c == '[' {
+			vs.state = tokStateFirstElem
+			vs.builder.beginArray()
+		} else {
+			vs.builder.beginObject()
+		}
+		return idx + 1, nil
+	case c == '"':
+		vs.beginToken(scanTokString, idx)
+		return idx + 1, nil
+	case c == '-' || isDigit(c):
+		vs.beginToken(scanTokNumber, idx)
+		return idx, nil
+	case c >= 'a' && c <= 'z':
+		vs.beginToken(scanTokLiteral, idx)
+		return idx, nil
+	}
+	return idx, vs.syntaxError(chunk, idx, framesPath(vs.frames), Nil)
+}
+
+// beginToken starts scanning a token at idx in the chunk.
+func (vs *valueScanner) beginToken(tok int, idx int) {
+	vs.tok, vs.tokAt = tok, vs.offset+int64(idx)
+	vs.key, vs.plain, vs.escape = false, true, 0
+}
+
+// scanToken scans the token starting at start in chunk, or in an earlier
+// chunk if it is in buf, from idx on.  Returns the index of the next
+// byte to scan, which is len(chunk) if the token continues past it.
+func (vs *valueScanner) scanToken(chunk []byte, start int, idx int) (int, error) {
+	if vs.tok == scanTokString {
+		return vs.scanString(chunk, start, idx)
+	}
+	for idx < len(chunk) && isTokenByte(vs.tok, chunk[idx]) {
+		idx++
+	}
+	if idx == len(chunk) {
+		return idx, nil
+	}
+	return idx, vs.endToken(vs.tokenText(chunk[start:idx]))
+}
+
+// isTokenByte returns true if c may be part of a number or literal token.
+func isTokenByte(tok int, c byte) bool {
+	if tok == scanTokNumber {
+		return isDigit(c) || c == '-' || c == '+' || c == '.' || c == 'e' || c == 'E'
+	}
+	return c >= 'a' && c <= 'z'
+}
+
+// scanString scans a string, as scanToken does.  The escapes and bytes
+// of the string are checked here, so it is only decoded again if it has
+// escapes or invalid UTF-8.
+func (vs *valueScanner) scanString(chunk []byte, start int, idx int) (int, error) {
+	for ; idx < len(chunk); idx++ {
+		c := chunk[idx]
+		switch {
+		case vs.escape < 0:
+			if bytes.IndexByte([]byte(`"\/bfnrtu`), c) < 0 {
+				return idx, vs.stringError(chunk, idx)
+			} else if vs.escape = 0; c == 'u' {
+				vs.escape = 4
+			}
+		case vs.escape > 0:
+			if !isDigit(c) && (c|0x20 < 'a' || c|0x20 > 'f') {
+				return idx, vs.stringError(chunk, idx)
+			}
+			vs.escape--
+		case c == '"':
+			return idx + 1, vs.endString(vs.tokenText(chunk[start : idx+1]))
+		case c == '\\':
+			vs.escape, vs.plain = -1, false
+		case c < 0x20:
+			return idx, vs.stringError(chunk, idx)
+		}
+	}
+	return idx, nil
+}
+
+// tokenText returns the text of the token ending with text, appending it
+// to the text from earlier chunks if any.
+func (vs *valueScanner) tokenText(text []byte) []byte {
+	if len(vs.buf) == 0 {
+		return text
+	}
+	vs.buf = append(vs.buf, text...)
+	return vs.buf
+}
+
+// endString decodes the string token in text, as a key or a value.
+func (vs *valueScanner) endString(text []byte) error {
+	var str string
+	if inner := text[1 : len(text)-1]; vs.plain && utf8.Valid(inner) {
+		str = string(inner)
+	} else if err := json.Unmarshal(text, &str); err != nil {
+		return vs.tokenError(text, String, ErrInvalidJSON)
+	}
+	vs.tok, vs.buf = scanTokNone, vs.buf[:0]
+	if vs.key {
+		frame := &vs.frames[len(vs.frames)-1]
+		frame.key, frame.index = str, 0
+		vs.state = tokStateColon
+		vs.builder.key(str)
+		return nil
+	}
+	vs.builder.scalar(str)
+	return vs.endValue(String)
+}
+
+// endToken decodes the number or literal token in text.
+func (vs *valueScanner) endToken(text []byte) error {
+	var kind Kind
+	var err error
+	if vs.tok == scanTokNumber {
+		if ns, ok := scanNumber(text); ok {
+			kind, err = vs.builder.number(text, &ns)
+		} else {
+			err = ErrInvalidJSON
+		}
+	} else if bytes.Equal(text, []byte("null")) {
+		vs.builder.scalar(nil)
+	} else if bytes.Equal(text, []byte("true")) {
+		kind = Bool
+		vs.builder.scalar(true)
+	} else if bytes.Equal(text, []byte("false")) {
+		kind = Bool
+		vs.builder.scalar(false)
+	} else {
+		err = ErrInvalidJSON
+	}
+	if err != nil {
+		return vs.tokenError(text, kind, err)
+	}
+	vs.tok, vs.buf = scanTokNone, vs.buf[:0]
+	return vs.endValue(kind)
+}
+
+// endContainer ends the innermost object or array.
+func (vs *valueScanner) endContainer() error {
+	kind := Object
+	if vs.frames[len(vs.frames)-1].delim == '[' {
+		kind = Array
+	}
+	vs.frames = vs.frames[:len(vs.frames)-1]
+	vs.builder.end()
+	return vs.endValue(kind)
+}
+
+// endValue sets the state for after a complete value of kind, and
+// emits it if a top-level value in a stream.
+func (vs *valueScanner) endValue(kind Kind) error {
+	if len(vs.frames) > 0 {
+		vs.state = tokStateNext
+		return nil
+	} else if vs.emit == nil {
+		vs.state, vs.kind = scanStateEnd, kind
+		return nil
+	}
+	vs.state, vs.adjoin = tokStateTop, true
+	return vs.emit()
+}
+
+// tokenPath returns the JSON Pointer to the value of the token being
+// scanned, which for a key is the enclosing object.
+func (vs *valueScanner) tokenPath() string {
+	if vs.key {
+		return framesPath(vs.frames[:len(vs.frames)-1])
+	}
+	return framesPath(vs.frames)
+}
+
+// syntaxError returns a DecodeError wrapping ErrInvalidJSON for the
+// unexpected text at idx in chunk, in the value at path being decoded as
+// kind.
+func (vs *valueScanner) syntaxError(chunk []byte, idx int, path string, kind Kind) error {
+	return &DecodeError{
+		Offset:  vs.offset + int64(idx),
+		Path:    path,
+		Snippet: snippet(chunk[idx:]),
+		Kind:    kind,
+		Err:     ErrInvalidJSON,
+	}
+}
+
+// containerError returns a DecodeError wrapping ErrInvalidJSON for the
+// unexpected text at idx in chunk, or the end of the text if chunk is
+// nil, in the innermost object or array.
+func (vs *valueScanner) containerError(chunk []byte, idx int) error {
+	kind := Object
+	if vs.frames[len(vs.frames)-1].delim == '[' {
+		kind = Array
+	}
+	return vs.syntaxError(chunk, idx, framesPath(vs.frames[:len(vs.frames)-1]), kind)
+}
+
+// stringError returns a DecodeError wrapping ErrInvalidJSON for the
+// unexpected byte at idx in chunk within the string being scanned.
+func (vs *valueScanner) stringError(chunk []byte, idx int) error {
+	kind := String
+	if vs.key {
+		kind = Object
+	}
+	return vs.syntaxError(chunk, idx, vs.tokenPath(), kind)
+}
+
+// tokenError returns a DecodeError wrapping err for the token in text,
+// being decoded as kind.
+func (vs *valueScanner) tokenError(text []byte, kind Kind, err error) error {
+	return &DecodeError{
+		Offset:  vs.tokAt,
+		Path:    vs.tokenPath(),
+		Snippet: snippet(text),
+		Kind:    kind,
+		Err:     err,
+	}
+}
+
+// isSpace returns true if c is JSON whitespace.
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,198 @@
+package bigjsonvalue
+
+// proxySet counts occurrences of proxy values, treating values that
+// compare equal as the same value.
+type proxySet struct {
+	buckets  map[uint64][]proxyCount
+	distinct int
+	total    int
+}
+
+// proxyCount is a proxy value in a proxySet and its number of occurrences.
+type proxyCount struct {
+	proxy interface{}
+	count int
+}
+
+// find returns the bucket of proxy and the index of proxy within it,
+// or -1 if not present.
+func (set *proxySet) find(proxy interface{}) (uint64, int) {
+	hash := hash64Proxy(proxy)
+	for idx := range set.buckets[hash] {
+		if equalProxies(set.buckets[hash][idx].proxy, proxy) {
+			return hash, idx
+		}
+	}
+	return hash, -1
+}
+
+// add adds one occurrence of proxy, calling copyProxy to get the proxy
+// value to keep if not yet present, and returns its new count.
+func (set *proxySet) add(proxy interface{}, copyProxy func() interface{}) int {
+	hash, idx := set.find(proxy)
+	set.total++
+	if idx >= 0 {
+		set.buckets[hash][idx].count++
+		return set.buckets[hash][idx].count
+	}
+	if set.buckets == nil {
+		set.buckets = make(map[uint64][]proxyCount)
+	}
+	set.buckets[hash] = append(set.buckets[hash], proxyCount{proxy: copyProxy(), count: 1})
+	set.distinct++
+	return 1
+}
+
+// remove removes one occurrence of proxy, and returns its new count.
+func (set *proxySet) remove(proxy interface{}) int {
+	hash, idx := set.find(proxy)
+	if idx < 0 {
+		return 0
+	}
+	set.total--
+	bucket := set.buckets[hash]
+	if bucket[idx].count--; bucket[idx].count > 0 {
+		return bucket[idx].count
+	}
+	set.distinct--
+	if len(bucket) == 1 {
+		delete(set.buckets, hash)
+	} else {
+		bucket[idx] = bucket[len(bucket)-1]
+		set.buckets[hash] = bucket[:len(bucket)-1]
+	}
+	return 0
+}
+
+// count returns the number of occurrences of proxy.
+func (set *proxySet) count(proxy interface{}) int {
+	hash, idx := set.find(proxy)
+	if idx < 0 {
+		return 0
+	}
+	return set.buckets[hash][idx].count
+}
+
+// each calls fn for each distinct proxy value and its count,
+// stopping early if fn returns false.
+func (set *proxySet) each(fn func(proxy interface{}, count int) bool) {
+	for _, bucket := range set.buckets {
+		for idx := range bucket {
+			if !fn(bucket[idx].proxy, bucket[idx].count) {
+				return
+			}
+		}
+	}
+}
+
+// BigSet is a set, or multiset, of BigJSONValue values, where values
+// that are Equal() are the same member, e.g. 1, 1.0 and 1.00.
+// Hash64() is used to find members, so adding and finding members takes
+// constant time on average.
+//
+// Used as a set, Add() adds new members and Len() is the number of
+// members.  Used as a multiset, Add() and Remove() count occurrences of
+// members, Count() returns them and Total() is the sum of all counts.
+//
+// Members are copied when first added, so changing an added value does
+// not change the member.  The zero value is an empty set ready to use.
+type BigSet struct {
+	set proxySet
+}
+
+// Add adds one occurrence of bjv, and returns its count, which is 1 if
+// bjv was not already a member.
+func (set *BigSet) Add(bjv *BigJSONValue) int {
+	return set.set.add(bjv.proxy, bjv.copyProxy)
+}
+
+// Remove removes one occurrence of bjv, and returns its remaining count,
+// which is 0 if bjv is no longer a member.
+func (set *BigSet) Remove(bjv *BigJSONValue) int {
+	return set.set.remove(bjv.proxy)
+}
+
+// Contains returns true if bjv is a member.
+func (set *BigSet) Contains(bjv *BigJSONValue) bool {
+	return set.set.count(bjv.proxy) > 0
+}
+
+// Count returns the number of occurrences of bjv, or 0 if not a member.
+func (set *BigSet) Count(bjv *BigJSONValue) int {
+	return set.set.count(bjv.proxy)
+}
+
+// Len returns the number of distinct members.
+func (set *BigSet) Len() int {
+	return set.set.distinct
+}
+
+// Total returns the number of occurrences of all members.
+func (set *BigSet) Total() int {
+	return set.set.total
+}
+
+// Range calls fn for each member and its count in no particular order,
+// stopping early if fn returns false.  Members must not be changed,
+// and the set must not be changed until Range returns.
+func (set *BigSet) Range(fn func(member *BigJSONValue, count int) bool) {
+	set.set.each(func(proxy interface{}, count int) bool {
+		return fn(&BigJSONValue{proxy: proxy}, count)
+	})
+}
+
+// NatSet is a set, or multiset, of NatJSONValue values, where values
+// that are Equal() are the same member, e.g. 1 and 1.0.
+// Hash64() is used to find members, so adding and finding members takes
+// constant time on average.
+//
+// Used as a set, Add() adds new members and Len() is the number of
+// members.  Used as a multiset, Add() and Remove() count occurrences of
+// members, Count() returns them and Total() is the sum of all counts.
+//
+// Members are copied when first added, so changing an added value does
+// not change the member.  The zero value is an empty set ready to use.
+type NatSet struct {
+	set proxySet
+}
+
+// Add adds one occurrence of njv, and returns its count, which is 1 if
+// njv was not already a member.
+func (set *NatSet) Add(njv *NatJSONValue) int {
+	return set.set.add(njv.proxy, njv.copyProxy)
+}
+
+// Remove removes one occurrence of njv, and returns its remaining count,
+// which is 0 if njv is no longer a member.
+func (set *NatSet) Remove(njv *NatJSONValue) int {
+	return set.set.remove(njv.proxy)
+}
+
+// Contains returns true if njv is a member.
+func (set *NatSet) Contains(njv *NatJSONValue) bool {
+	return set.set.count(njv.proxy) > 0
+}
+
+// Count returns the number of occurrences of njv, or 0 if not a member.
+func (set *NatSet) Count(njv *NatJSONValue) int {
+	return set.set.count(njv.proxy)
+}
+
+// Len returns the number of distinct members.
+func (set *NatSet) Len() int {
+	return set.set.distinct
+}
+
+// Total returns the number of occurrences of all members.
+func (set *NatSet) Total() int {
+	return set.set.total
+}
+
+// Range calls fn for each member and its count in no particular order,
+// stopping early if fn returns false.  Members must not be changed,
+// and the set must not be changed until Range returns.
+func (set *NatSet) Range(fn func(member *NatJSONValue, count int) bool) {
+	set.set.each(func(proxy interface{}, count int) bool {
+		return fn(&NatJSONValue{proxy: proxy}, count)
+	})
+}
@@ -0,0 +1,69 @@
+package bigjsonvalue
+
+import (
+	"testing"
+)
+
+func TestBigSet(t *testing.T) {
+	var set BigSet
+	for _, text := range []string{`1`, `1.0`, `"1"`, `{"a": 1, "b": 2}`, `{"b": 2.0, "a": 1}`, `1e400`, `1`} {
+		bjv, _ := new(BigJSONValue).DecodeJSONValue(text)
+		set.Add(bjv)
+	}
+	if set.Len() != 4 || set.Total() != 7 {
+		t.Errorf("Unexpected Len()=%d, Total()=%d", set.Len(), set.Total())
+	}
+	one, _ := BigDecodeOptions{Decimal: true}.DecodeJSONValue(new(BigJSONValue), `1.00`)
+	if !set.Contains(one) || set.Count(one) != 3 {
+		t.Errorf("Unexpected Contains(%s)=%t, Count(%s)=%d", one, set.Contains(one), one, set.Count(one))
+	}
+	if count := set.Remove(one); count != 2 || set.Total() != 6 {
+		t.Errorf("Unexpected Remove(%s)=%d, Total()=%d", one, count, set.Total())
+	}
+	set.Remove(one)
+	if count := set.Remove(one); count != 0 || set.Contains(one) || set.Len() != 3 {
+		t.Errorf("Unexpected Remove(%s)=%d, Contains()=%t, Len()=%d", one, count, set.Contains(one), set.Len())
+	}
+	if count := set.Remove(one); count != 0 || set.Total() != 4 {
+		t.Errorf("Unexpected Remove(%s)=%d, Total()=%d", one, count, set.Total())
+	}
+
+	total := 0
+	set.Range(func(member *BigJSONValue, count int) bool {
+		total += count
+		return true
+	})
+	if total != set.Total() {
+		t.Errorf("Range() counted %d, expected %d", total, set.Total())
+	}
+}
+
+func TestNatSetCopies(t *testing.T) {
+	var set NatSet
+	keyValues, _ := new(NatJSONValue).DecodeJSONValue(`[42, "foo"]`)
+	if count := set.Add(keyValues); count != 1 {
+		t.Errorf("Unexpected Add()=%d", count)
+	}
+	keyValues.Index(0).proxy = uint64(43)
+	if set.Contains(keyValues) {
+		t.Errorf("Changing added value changed member")
+	}
+	dup, _ := new(NatJSONValue).FromInterface([]interface{}{42.0, "foo"})
+	if count := set.Add(dup); count != 2 || set.Len() != 1 {
+		t.Errorf("Unexpected Add()=%d, Len()=%d", count, set.Len())
+	}
+}
+
+func BenchmarkNatSetDedupe(b *testing.B) {
+	tuples := make([]*NatJSONValue, 1000)
+	for idx := range tuples {
+		tuples[idx], _ = new(NatJSONValue).FromInterface([]interface{}{idx % 100, "schema.table"})
+	}
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		var set NatSet
+		for _, tuple := range tuples {
+			set.Add(tuple)
+		}
+	}
+}
@@ -0,0 +1,440 @@
+package bigjsonvalue
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// TokenKind enumerates the kinds of Token yielded by a Tokenizer
+type TokenKind uint
+
+// TokenKind enumeration constants
+const (
+	TokenObjectStart TokenKind = iota
+	TokenObjectEnd
+	TokenArrayStart
+	TokenArrayEnd
+	TokenKey
+	TokenString
+	TokenBool
+	TokenNull
+	TokenNumber
+	lastTokenKind
+	// insert new enums before lastTokenKind, lastTokenKind MUST ALWAYS BE LAST
+)
+
+var tokenKindNames = [...]string{
+	"ObjectStart",
+	"ObjectEnd",
+	"ArrayStart",
+	"ArrayEnd",
+	"Key",
+	"String",
+	"Bool",
+	"Null",
+	"Number",
+}
+
+// String implements fmt.Stringer interface for TokenKind
+func (k TokenKind) String() string {
+	return tokenKindNames[k]
+}
+
+// Token is a single token of JSON text yielded by a Tokenizer: the start
+// or end of an object or array, an object member key, or a scalar value.
+// Number tokens keep their text, and are only decoded when Big() or Nat()
+// is called, so numbers of any size pass through without loss.
+type Token struct {
+	Kind   TokenKind // kind of token
+	Offset int64     // byte offset of the start of the token in the stream
+	Depth  int       // number of objects and arrays enclosing the token
+	text   string    // JSON text of the token
+	str    string    // decoded string of key and string tokens
+}
+
+// Text returns the JSON text of the token, e.g. "{", `"a\n"` or "1.50".
+func (tok *Token) Text() string {
+	return tok.text
+}
+
+// String returns the decoded string of key and string tokens, e.g. "a\n"
+// for `"a\n"`, otherwise the JSON text of the token.
+func (tok *Token) String() string {
+	if tok.Kind == TokenKey || tok.Kind == TokenString {
+		return tok.str
+	}
+	return tok.text
+}
+
+// Bool returns true if the token is the bool value true.
+func (tok *Token) Bool() bool {
+	return tok.text == "true"
+}
+
+// Big decodes a scalar or key token as a new BigJSONValue, using
+// DefaultBigDecodeOptions.  See BigDecodeOptions.DecodeToken().
+func (tok *Token) Big() (*BigJSONValue, error) {
+	return DefaultBigDecodeOptions.DecodeToken(tok)
+}
+
+// Nat decodes a scalar or key token as a new NatJSONValue, using
+// DefaultNatDecodeOptions.  See NatDecodeOptions.DecodeToken().
+func (tok *Token) Nat() (*NatJSONValue, error) {
+	return DefaultNatDecodeOptions.DecodeToken(tok)
+}
+
+// isContainer returns true for tokens starting or ending an object or
+// array, which have no value of their own.
+func (tok *Token) isContainer() bool {
+	return tok.Kind <= TokenArrayEnd
+}
+
+// tokFrame is an object or array enclosing the current token.
+type tokFrame struct {
+	delim byte   // '{' or '['
+	key   string // key of the current member of an object, if index is 0
+	index int    // index of the current element of an array, or -1 if none yet
+}
+
+// Tokenizer states, for what may come next.
+const (
+	tokStateTop       = iota // a top-level value, or the end of the stream
+	tokStateValue            // a value, after ':' or ',' in an array
+	tokStateFirstElem        // a value or ']', after '['
+	tokStateFirstKey         // a key or '}', after '{'
+	tokStateKey              // a key, after ',' in an object
+	tokStateColon            // ':', after a key
+	tokStateNext             // ',' or the end of the enclosing container
+)
+
+// Tokenizer reads JSON text from an io.Reader a token at a time, so
+// documents far larger than memory can be processed a value at a time.
+// The text may hold any number of top-level values separated by
+// whitespace, such as newline-delimited JSON.  Values with no whitespace
+// between them, such as "1true" or "{}[]", are malformed.
+//
+// A Tokenizer buffers its reads, so may read past the last token it
+// returns.
+type Tokenizer struct {
+	r      *bufio.Reader
+	offset int64      // byte offset of the next unread byte
+	state  int        // what may come next, one of tokStateTop, etc.
+	frames []tokFrame // objects and arrays enclosing the next token
+	buf    []byte     // text of the token being read
+	err    error      // sticky error, returned by every later Next()
+}
+
+// NewTokenizer returns a Tokenizer reading JSON text from r.
+func NewTokenizer(r io.Reader) *Tokenizer {
+	return &Tokenizer{r: bufio.NewReader(r)}
+}
+
+// Depth returns the number of objects and arrays enclosing the next token.
+func (tz *Tokenizer) Depth() int {
+	return len(tz.frames)
+}
+
+// Offset returns the byte offset in the stream just past the last token.
+func (tz *Tokenizer) Offset() int64 {
+	return tz.offset
+}
+
+// Path returns the JSON Pointer, per RFC 6901, to the value of the last
+// token within its top-level value, e.g. "/change/3/columnvalues".  For a
+// key token, that is the member value the key is for, and for the start
+// or end of an object or array, that is the object or array.
+func (tz *Tokenizer) Path() string {
+	return framesPath(tz.frames)
+}
+
+// framesPath returns the JSON Pointer to the current value within frames.
+func framesPath(frames []tokFrame) string {
+	var sb strings.Builder
+	for idx := range frames {
+		frame := &frames[idx]
+		if frame.index < 0 {
+			continue
+		}
+		sb.WriteByte('/')
+		if frame.delim == '{' {
+			sb.WriteString(escapePointerToken(frame.key))
+		} else {
+			sb.WriteString(strconv.Itoa(frame.index))
+		}
+	}
+	return sb.String()
+}
+
+// Next reads and returns the next token.  Returns io.EOF at the end of
+// the stream after a complete top-level value or none at all.  Returns a
+// *DecodeError wrapping ErrInvalidJSON for malformed text, with Offset
+// relative to the stream and Path to the offending value within its
+// top-level value, or the error from the io.Reader.  Once an error is
+// returned, every later call returns it again.
+func (tz *Tokenizer) Next() (Token, error) {
+	if tz.err != nil {
+		return Token{}, tz.err
+	}
+	tok, err := tz.next()
+	if err != nil {
+		tz.err = err
+		return Token{}, err
+	}
+	return tok, nil
+}
+
+// next reads the next token.
+func (tz *Tokenizer) next() (Token, error) {
+	for {
+		start := tz.offset
+		c, err := tz.skipSpace()
+		if err == io.EOF && tz.state == tokStateTop {
+			return Token{}, io.EOF
+		} else if err == io.EOF {
+			return Token{}, tz.syntaxError(nil)
+		} else if err != nil {
+			return Token{}, err
+		} else if tz.state == tokStateTop && start > 0 && tz.offset == start {
+			// Top-level values must be separated by whitespace.
+			return Token{}, tz.syntaxError([]byte{c})
+		}
+		tok := Token{Offset: tz.offset, Depth: len(tz.frames)}
+		switch tz.state {
+		case tokStateColon:
+			if c != ':' {
+				return Token{}, tz.syntaxError([]byte{c})
+			}
+			tz.discard(1)
+			tz.state = tokStateValue
+			continue
+		case tokStateNext:
+			frame := &tz.frames[len(tz.frames)-1]
+			if c == ',' {
+				tz.discard(1)
+				if tz.state = tokStateKey; frame.delim == '[' {
+					tz.state = tokStateValue
+					frame.index++
+				}
+				continue
+			} else if c == '}' && frame.delim == '{' || c == ']' && frame.delim == '[' {
+				return tz.readEnd(tok, c), nil
+			}
+			return Token{}, tz.syntaxError([]byte{c})
+		case tokStateFirstElem:
+			if c == ']' {
+				return tz.readEnd(tok, c), nil
+			}
+			tz.frames[len(tz.frames)-1].index = 0
+		case tokStateFirstKey, tokStateKey:
+			if c == '}' && tz.state == tokStateFirstKey {
+				return tz.readEnd(tok, c), nil
+			} else if c != '"' {
+				return Token{}, tz.syntaxError([]byte{c})
+			}
+			tok.Kind = TokenKey
+			if err = tz.readString(&tok); err != nil {
+				return Token{}, err
+			}
+			frame := &tz.frames[len(tz.frames)-1]
+			frame.key, frame.index = tok.str, 0
+			tz.state = tokStateColon
+			return tok, nil
+		}
+		return tz.readValue(tok, c)
+	}
+}
+
+// readValue reads the token of a value starting with c.
+func (tz *Tokenizer) readValue(tok Token, c byte) (Token, error) {
+	var err error
+	switch {
+	case c == '{' || c == '[':
+		tz.discard(1)
+		tok.text = string(c)
+		if tok.Kind, tz.state = TokenObjectStart, tokStateFirstKey; c == '[' {
+			tok.Kind, tz.state = TokenArrayStart, tokStateFirstElem
+		}
+		tz.frames = append(tz.frames, tokFrame{delim: c, index: -1})
+		return tok, nil
+	case c == '"':
+		tok.Kind = TokenString
+		err = tz.readString(&tok)
+	case c == '-' || isDigit(c):
+		tok.Kind = TokenNumber
+		err = tz.readNumber(&tok)
+	case c >= 'a' && c <= 'z':
+		err = tz.readLiteral(&tok)
+	default:
+		err = tz.syntaxError([]byte{c})
+	}
+	if err != nil {
+		return Token{}, err
+	}
+	tz.endValue()
+	return tok, nil
+}
+
+// readEnd reads the token ending the innermost object or array.
+func (tz *Tokenizer) readEnd(tok Token, c byte) Token {
+	tz.discard(1)
+	tz.frames = tz.frames[:len(tz.frames)-1]
+	tok.Depth = len(tz.frames)
+	tok.text = string(c)
+	if tok.Kind = TokenObjectEnd; c == ']' {
+		tok.Kind = TokenArrayEnd
+	}
+	tz.endValue()
+	return tok
+}
+
+// endValue sets the state for after a complete value.
+func (tz *Tokenizer) endValue() {
+	if len(tz.frames) == 0 {
+		tz.state = tokStateTop
+	} else {
+		tz.state = tokStateNext
+	}
+}
+
+// readString reads the JSON string starting at the next byte into tok.
+func (tz *Tokenizer) readString(tok *Token) error {
+	tz.buf = append(tz.buf[:0], '"')
+	tz.discard(1)
+	escaped := false
+	for {
+		c, err := tz.r.ReadByte()
+		if err == io.EOF {
+			return tz.syntaxError(nil)
+		} else if err != nil {
+			return err
+		}
+		tz.offset++
+		tz.buf = append(tz.buf, c)
+		if c < 0x20 {
+			return tz.tokenError()
+		} else if c == '\\' {
+			escaped = true
+			if c, err = tz.r.ReadByte(); err == io.EOF {
+				return tz.syntaxError(nil)
+			} else if err != nil {
+				return err
+			}
+			tz.offset++
+			tz.buf = append(tz.buf, c)
+		} else if c == '"' {
+			break
+		}
+	}
+	tok.text = string(tz.buf)
+	if !escaped && utf8.ValidString(tok.text) {
+		tok.str = tok.text[1 : len(tok.text)-1]
+		return nil
+	}
+	var str string
+	if err := json.Unmarshal(tz.buf, &str); err != nil {
+		return tz.tokenError()
+	}
+	tok.str = str
+	return nil
+}
+
+// readNumber reads the JSON number starting at the next byte into tok,
+// leaving it undecoded.
+func (tz *Tokenizer) readNumber(tok *Token) error {
+	err := tz.readWhile(func(c byte) bool {
+		return isDigit(c) || c == '-' || c == '+' || c == '.' || c == 'e' || c == 'E'
+	})
+	if err != nil {
+		return err
+	} else if _, ok := scanNumber(tz.buf); !ok {
+		return tz.tokenError()
+	}
+	tok.text = string(tz.buf)
+	return nil
+}
+
+// readLiteral reads the true, false or null literal starting at the next
+// byte into tok.
+func (tz *Tokenizer) readLiteral(tok *Token) error {
+	err := tz.readWhile(func(c byte) bool {
+		return c >= 'a' && c <= 'z'
+	})
+	if err != nil {
+		return err
+	}
+	switch string(tz.buf) {
+	case "true":
+		tok.Kind, tok.text = TokenBool, "true"
+	case "false":
+		tok.Kind, tok.text = TokenBool, "false"
+	case "null":
+		tok.Kind, tok.text = TokenNull, "null"
+	default:
+		return tz.tokenError()
+	}
+	return nil
+}
+
+// readWhile reads bytes for which fn returns true into buf.
+func (tz *Tokenizer) readWhile(fn func(c byte) bool) error {
+	tz.buf = tz.buf[:0]
+	for {
+		c, err := tz.r.ReadByte()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		} else if !fn(c) {
+			return tz.r.UnreadByte()
+		}
+		tz.offset++
+		tz.buf = append(tz.buf, c)
+	}
+}
+
+// skipSpace skips whitespace, and returns the next byte without
+// reading it.
+func (tz *Tokenizer) skipSpace() (byte, error) {
+	for {
+		c, err := tz.r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		switch c {
+		case ' ', '\t', '\n', '\r':
+			tz.offset++
+		default:
+			return c, tz.r.UnreadByte()
+		}
+	}
+}
+
+// discard skips n bytes that have already been peeked at.
+func (tz *Tokenizer) discard(n int) {
+	tz.r.Discard(n)
+	tz.offset += int64(n)
+}
+
+// syntaxError returns a DecodeError wrapping ErrInvalidJSON for the
+// unexpected text at the next byte, or the unexpected end of the stream
+// if text is nil.
+func (tz *Tokenizer) syntaxError(text []byte) error {
+	return &DecodeError{
+		Offset:  tz.offset,
+		Path:    tz.Path(),
+		Snippet: snippet(text),
+		Err:     ErrInvalidJSON,
+	}
+}
+
+// tokenError returns a DecodeError wrapping ErrInvalidJSON for the
+// malformed token read into buf.
+func (tz *Tokenizer) tokenError() error {
+	decErr := tz.syntaxError(tz.buf).(*DecodeError)
+	decErr.Offset -= int64(len(tz.buf))
+	return decErr
+}
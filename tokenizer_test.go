@@ -0,0 +1,199 @@
+package bigjsonvalue
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+const tokenizerInput = `{"a": [1, 18446744073709551617, {"b~/": null}], "c": true}`
+
+func TestTokenizer(t *testing.T) {
+	expectedList := []struct {
+		kind   TokenKind
+		offset int64
+		depth  int
+		text   string
+		path   string
+	}{
+		{TokenObjectStart, 0, 0, "{", ""},
+		{TokenKey, 1, 1, `"a"`, "/a"},
+		{TokenArrayStart, 6, 1, "[", "/a"},
+		{TokenNumber, 7, 2, "1", "/a/0"},
+		{TokenNumber, 10, 2, "18446744073709551617", "/a/1"},
+		{TokenObjectStart, 32, 2, "{", "/a/2"},
+		{TokenKey, 33, 3, `"b~/"`, "/a/2/b~0~1"},
+		{TokenNull, 40, 3, "null", "/a/2/b~0~1"},
+		{TokenObjectEnd, 44, 2, "}", "/a/2"},
+		{TokenArrayEnd, 45, 1, "]", "/a"},
+		{TokenKey, 48, 1, `"c"`, "/c"},
+		{TokenBool, 53, 1, "true", "/c"},
+		{TokenObjectEnd, 57, 0, "}", ""},
+	}
+	for _, oneByte := range []bool{false, true} {
+		var r io.Reader = strings.NewReader(tokenizerInput)
+		if oneByte {
+			r = iotest.OneByteReader(r)
+		}
+		tz := NewTokenizer(r)
+		for idx, rec := range expectedList {
+			tok, err := tz.Next()
+			if err != nil {
+				t.Fatalf("%d: Next() err=%s", idx, err)
+			}
+			if tok.Kind != rec.kind || tok.Offset != rec.offset || tok.Depth != rec.depth || tok.Text() != rec.text {
+				t.Errorf("%d: Next()=%s at %d depth %d %s, expected %s at %d depth %d %s",
+					idx, tok.Kind, tok.Offset, tok.Depth, tok.Text(), rec.kind, rec.offset, rec.depth, rec.text)
+			}
+			if path := tz.Path(); path != rec.path {
+				t.Errorf("%d: Path()=%s, expected %s", idx, path, rec.path)
+			}
+		}
+		for idx := 0; idx < 2; idx++ {
+			if _, err := tz.Next(); err != io.EOF {
+				t.Errorf("Next() at end err=%v, expected EOF", err)
+			}
+		}
+		if offset := tz.Offset(); offset != int64(len(tokenizerInput)) {
+			t.Errorf("Offset() at end=%d", offset)
+		}
+	}
+}
+
+func TestTokenizerValues(t *testing.T) {
+	tz := NewTokenizer(strings.NewReader(" 1 \"a\\n\\u00e9\" false\n{} [] -1.50e+2 null\n"))
+	var texts []string
+	for {
+		tok, err := tz.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Next() err=%s", err)
+		} else if tok.Depth != 0 && tok.Kind != TokenObjectEnd && tok.Kind != TokenArrayEnd {
+			t.Errorf("Next()=%s at depth %d", tok.Kind, tok.Depth)
+		}
+		texts = append(texts, tok.Kind.String()+" "+tok.String())
+		if tok.Kind == TokenBool && tok.Bool() {
+			t.Errorf("Bool() of %s=true", tok.Text())
+		}
+	}
+	expected := "Number 1,String a\né,Bool false,ObjectStart {,ObjectEnd },ArrayStart [,ArrayEnd ],Number -1.50e+2,Null null"
+	if text := strings.Join(texts, ","); text != expected {
+		t.Errorf("Next() tokens=%s, expected %s", text, expected)
+	}
+	if _, err := NewTokenizer(strings.NewReader("  \n")).Next(); err != io.EOF {
+		t.Errorf("Next() of whitespace err=%v, expected EOF", err)
+	}
+}
+
+func TestTokenDecode(t *testing.T) {
+	tz := NewTokenizer(strings.NewReader(`[18446744073709551617, 1.50, "s", 18446744073709551616]`))
+	tok, _ := tz.Next()
+	if _, err := tok.Big(); err != ErrKindMismatch {
+		t.Errorf("Big() of %s err=%v", tok.Kind, err)
+	}
+	tok, _ = tz.Next()
+	if bjv, err := tok.Big(); err != nil || bjv.Kind() != BigInt || bjv.String() != "18446744073709551617" {
+		t.Errorf("Big() of %s=%s %s, err=%v", tok.Text(), bjv.Kind(), bjv, err)
+	}
+	njv, err := NatDecodeOptions{Overflow: OverflowPromote}.DecodeToken(&tok)
+	if err != nil || njv.Kind() != BigInt || njv.String() != "18446744073709551617" {
+		t.Errorf("DecodeToken() of %s=%s %s, err=%v", tok.Text(), njv.Kind(), njv, err)
+	}
+	tok, _ = tz.Next()
+	bjv, err := BigDecodeOptions{Decimal: true, RetainLiterals: true}.DecodeToken(&tok)
+	if err != nil || bjv.Kind() != Decimal || bjv.Literal() != "1.50" {
+		t.Errorf("DecodeToken() of %s=%s %s, err=%v", tok.Text(), bjv.Kind(), bjv, err)
+	}
+	tok, _ = tz.Next()
+	if njv, err = tok.Nat(); err != nil || njv.Kind() != String || njv.String() != "s" {
+		t.Errorf("Nat() of %s=%s %s, err=%v", tok.Text(), njv.Kind(), njv, err)
+	}
+	tok, _ = tz.Next()
+	_, err = tok.Nat()
+	var decErr *DecodeError
+	if !errors.As(err, &decErr) || decErr.Offset != 34 || !errors.Is(err, strconv.ErrRange) {
+		t.Errorf("Nat() of %s err=%v", tok.Text(), err)
+	}
+}
+
+func TestTokenizerErrors(t *testing.T) {
+	testList := []struct {
+		text    string
+		offset  int64
+		path    string
+		snippet string
+	}{
+		{`[1,]`, 3, "/1", "]"},
+		{`{"a" 1}`, 5, "/a", "1"},
+		{`{"a":1,}`, 7, "/a", "}"},
+		{`{1:2}`, 1, "", "1"},
+		{`[1}`, 2, "/0", "}"},
+		{`{"a":[true false]}`, 11, "/a/0", "f"},
+		{`[01]`, 1, "/0", "01"},
+		{`tru`, 0, "", "tru"},
+		{`["\x"]`, 1, "/0", `"\x"`},
+		{"\"a\tb\"", 0, "", "\"a\t"},
+		{`"abc`, 4, "", ""},
+		{`{"a":[`, 6, "/a", ""},
+		{`1 2 ]`, 4, "", "]"},
+		{`1true`, 1, "", "t"},
+		{`"a""b"`, 3, "", `"`},
+		{`{}[]`, 2, "", "["},
+	}
+	for idx, rec := range testList {
+		tz := NewTokenizer(strings.NewReader(rec.text))
+		var err error
+		for err == nil {
+			_, err = tz.Next()
+		}
+		var decErr *DecodeError
+		if !errors.As(err, &decErr) || !errors.Is(err, ErrInvalidJSON) {
+			t.Errorf("%d: Next() of %s err=%v", idx, rec.text, err)
+			continue
+		}
+		if decErr.Offset != rec.offset || decErr.Path != rec.path || decErr.Snippet != rec.snippet {
+			t.Errorf("%d: Next() of %s err at %d %q %q, expected %d %q %q", idx, rec.text,
+				decErr.Offset, decErr.Path, decErr.Snippet, rec.offset, rec.path, rec.snippet)
+		}
+		if _, again := tz.Next(); again != err {
+			t.Errorf("%d: Next() after err=%v, expected %v", idx, again, err)
+		}
+	}
+
+	readErr := errors.New("read failed")
+	tz := NewTokenizer(io.MultiReader(strings.NewReader(`[1, `), errReader{readErr}))
+	var err error
+	for err == nil {
+		_, err = tz.Next()
+	}
+	if err != readErr {
+		t.Errorf("Next() err=%v, expected %v", err, readErr)
+	}
+}
+
+// errReader is an io.Reader that always fails with err.
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read(p []byte) (int, error) {
+	return 0, r.err
+}
+
+func BenchmarkTokenizer(b *testing.B) {
+	text := `{"xid":1,"change":[` + strings.Repeat(
+		`{"kind":"insert","table":"t","columnvalues":[18446744073709551617,"name",1.5,true,null]},`, 100) + `{}]}`
+	b.SetBytes(int64(len(text)))
+	for n := 0; n < b.N; n++ {
+		tz := NewTokenizer(strings.NewReader(text))
+		for {
+			if _, err := tz.Next(); err != nil {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package bigjsonvalue
+
+import (
+	"errors"
+	"io"
+)
+
+// Handler receives callbacks from Walk() for each part of JSON text in
+// document order, with scalar values decoded as BigJSONValue values.
+//
+// Any error a method returns stops the walk, and is returned by Walk(),
+// except for two that control the walk instead, which may also be
+// wrapped:
+//
+// ErrStopWalk stops the walk, and Walk() returns nil.
+//
+// ErrSkipSubtree skips part of the text.  From OnObjectStart() or
+// OnArrayStart(), it skips the members or elements of that object or
+// array, including the call to OnObjectEnd() or OnArrayEnd().  From
+// OnKey(), it skips the member value.  From any other method, it skips
+// the rest of the enclosing object or array, including its end.
+type Handler interface {
+	OnObjectStart() error
+	OnObjectEnd() error
+	OnArrayStart() error
+	OnArrayEnd() error
+	OnKey(key string) error
+	OnValue(value *BigJSONValue) error
+}
+
+// BaseHandler implements Handler with methods that do nothing, for
+// embedding in handlers that only need some of them.
+type BaseHandler struct{}
+
+// OnObjectStart implements Handler, and returns nil.
+func (BaseHandler) OnObjectStart() error { return nil }
+
+// OnObjectEnd implements Handler, and returns nil.
+func (BaseHandler) OnObjectEnd() error { return nil }
+
+// OnArrayStart implements Handler, and returns nil.
+func (BaseHandler) OnArrayStart() error { return nil }
+
+// OnArrayEnd implements Handler, and returns nil.
+func (BaseHandler) OnArrayEnd() error { return nil }
+
+// OnKey implements Handler, and returns nil.
+func (BaseHandler) OnKey(key string) error { return nil }
+
+// OnValue implements Handler, and returns nil.
+func (BaseHandler) OnValue(value *BigJSONValue) error { return nil }
+
+// Walk reads JSON text from r, and calls the methods of h for each part
+// of it, decoding scalar values with DefaultBigDecodeOptions.
+// See BigDecodeOptions.Walk().
+func Walk(r io.Reader, h Handler) error {
+	return DefaultBigDecodeOptions.Walk(r, h)
+}
+
+// Walk reads JSON text from r a token at a time using a Tokenizer, and
+// calls the methods of h for each part of it, decoding scalar values
+// using these options.  No object or array values are built, so memory
+// use depends on how deeply values are nested, not on the size of the
+// text.  The text may hold any number of top-level values.
+//
+// Returns nil at the end of the text, or when h returns ErrStopWalk.
+// Otherwise returns the first error h returns, the error from the
+// Tokenizer for malformed text, or a *DecodeError for a scalar value that
+// cannot be decoded, with Offset relative to r and Path to the value
+// within its top-level value.  Skipped text must still be well-formed.
+func (opts BigDecodeOptions) Walk(r io.Reader, h Handler) error {
+	tz := NewTokenizer(r)
+	for {
+		tok, err := tz.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		switch tok.Kind {
+		case TokenObjectStart:
+			err = h.OnObjectStart()
+		case TokenObjectEnd:
+			err = h.OnObjectEnd()
+		case TokenArrayStart:
+			err = h.OnArrayStart()
+		case TokenArrayEnd:
+			err = h.OnArrayEnd()
+		case TokenKey:
+			err = h.OnKey(tok.str)
+		default:
+			var value *BigJSONValue
+			if value, err = opts.DecodeToken(&tok); err != nil {
+				if decErr, ok := err.(*DecodeError); ok {
+					decErr.Path = tz.Path()
+				}
+				return err
+			}
+			err = h.OnValue(value)
+		}
+		if errors.Is(err, ErrSkipSubtree) {
+			err = walkSkip(tz, &tok)
+		}
+		if errors.Is(err, ErrStopWalk) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// walkSkip skips the text Handler method returning ErrSkipSubtree for tok
+// asks to skip.
+func walkSkip(tz *Tokenizer, tok *Token) error {
+	switch tok.Kind {
+	case TokenObjectStart, TokenArrayStart:
+		return skipTokens(tz, tok.Depth)
+	case TokenKey:
+		next, err := tz.Next()
+		if err != nil || !next.isContainer() {
+			return err
+		}
+		return skipTokens(tz, next.Depth)
+	}
+	if tok.Depth == 0 {
+		return nil
+	}
+	return skipTokens(tz, tok.Depth-1)
+}
+
+// skipTokens reads tokens up to and including the end of the object or
+// array started at depth.
+func skipTokens(tz *Tokenizer, depth int) error {
+	for {
+		tok, err := tz.Next()
+		if err != nil {
+			return err
+		} else if (tok.Kind == TokenObjectEnd || tok.Kind == TokenArrayEnd) && tok.Depth == depth {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package bigjsonvalue
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// recordHandler records each callback, and returns the error in errs for
+// its position, if any.
+type recordHandler struct {
+	events []string
+	errs   map[int]error
+}
+
+func (h *recordHandler) record(event string) error {
+	h.events = append(h.events, event)
+	return h.errs[len(h.events)-1]
+}
+
+func (h *recordHandler) OnObjectStart() error { return h.record("{") }
+func (h *recordHandler) OnObjectEnd() error   { return h.record("}") }
+func (h *recordHandler) OnArrayStart() error  { return h.record("[") }
+func (h *recordHandler) OnArrayEnd() error    { return h.record("]") }
+func (h *recordHandler) OnKey(key string) error {
+	return h.record(key + ":")
+}
+func (h *recordHandler) OnValue(value *BigJSONValue) error {
+	return h.record(value.Kind().String() + "=" + value.String())
+}
+
+const walkInput = `{"a": [1, {"b": 18446744073709551617}, 2.5], "c": {"d": [true]}, "e": null} "s"`
+
+func TestWalk(t *testing.T) {
+	testList := []struct {
+		errs     map[int]error
+		expected string
+	}{
+		{nil, `{ a: [ BigInt=1 { b: BigInt=18446744073709551617 } BigFloat=2.5 ] c: { d: [ Bool=true ] } e: Nil=nil } String=s`},
+		{map[int]error{2: ErrSkipSubtree}, `{ a: [ c: { d: [ Bool=true ] } e: Nil=nil } String=s`},
+		{map[int]error{1: ErrSkipSubtree}, `{ a: c: { d: [ Bool=true ] } e: Nil=nil } String=s`},
+		{map[int]error{3: ErrSkipSubtree}, `{ a: [ BigInt=1 c: { d: [ Bool=true ] } e: Nil=nil } String=s`},
+		{map[int]error{6: ErrSkipSubtree}, `{ a: [ BigInt=1 { b: BigInt=18446744073709551617 BigFloat=2.5 ] c: { d: [ Bool=true ] } e: Nil=nil } String=s`},
+		{map[int]error{7: ErrSkipSubtree}, `{ a: [ BigInt=1 { b: BigInt=18446744073709551617 } c: { d: [ Bool=true ] } e: Nil=nil } String=s`},
+		{map[int]error{0: ErrSkipSubtree}, `{ String=s`},
+		{map[int]error{18: ErrSkipSubtree}, `{ a: [ BigInt=1 { b: BigInt=18446744073709551617 } BigFloat=2.5 ] c: { d: [ Bool=true ] } e: Nil=nil String=s`},
+		{map[int]error{6: ErrStopWalk}, `{ a: [ BigInt=1 { b: BigInt=18446744073709551617`},
+		{map[int]error{6: fmt.Errorf("done: %w", ErrStopWalk)}, `{ a: [ BigInt=1 { b: BigInt=18446744073709551617`},
+		{map[int]error{2: fmt.Errorf("skip: %w", ErrSkipSubtree)}, `{ a: [ c: { d: [ Bool=true ] } e: Nil=nil } String=s`},
+	}
+	for idx, rec := range testList {
+		h := &recordHandler{errs: rec.errs}
+		if err := Walk(strings.NewReader(walkInput), h); err != nil {
+			t.Errorf("%d: Walk() err=%s", idx, err)
+		}
+		if events := strings.Join(h.events, " "); events != rec.expected {
+			t.Errorf("%d: Walk() events=%s, expected %s", idx, events, rec.expected)
+		}
+	}
+
+	h := &recordHandler{}
+	if err := (BigDecodeOptions{Decimal: true}).Walk(strings.NewReader(`[1.50]`), h); err != nil {
+		t.Errorf("Walk() err=%s", err)
+	} else if events := strings.Join(h.events, " "); events != "[ Decimal=1.50 ]" {
+		t.Errorf("Walk() events=%s", events)
+	}
+}
+
+func TestWalkErrors(t *testing.T) {
+	handlerErr := errors.New("handler failed")
+	h := &recordHandler{errs: map[int]error{2: handlerErr}}
+	if err := Walk(strings.NewReader(walkInput), h); err != handlerErr {
+		t.Errorf("Walk() err=%v, expected %v", err, handlerErr)
+	}
+
+	testList := []struct {
+		text   string
+		errs   map[int]error
+		opts   BigDecodeOptions
+		offset int64
+		path   string
+		err    error
+	}{
+		{`{"a": [1, 2}`, nil, DefaultBigDecodeOptions, 11, "/a/1", ErrInvalidJSON},
+		{`{"a": [1, 2}`, map[int]error{0: ErrSkipSubtree}, DefaultBigDecodeOptions, 11, "/a/1", ErrInvalidJSON},
+		{`{"a": [true, 1e2147483648]}`, nil, BigDecodeOptions{Decimal: true}, 13, "/a/1", strconv.ErrRange},
+	}
+	for idx, rec := range testList {
+		err := rec.opts.Walk(strings.NewReader(rec.text), &recordHandler{errs: rec.errs})
+		var decErr *DecodeError
+		if !errors.As(err, &decErr) || !errors.Is(err, rec.err) {
+			t.Errorf("%d: Walk(%s) err=%v", idx, rec.text, err)
+		} else if decErr.Offset != rec.offset || decErr.Path != rec.path {
+			t.Errorf("%d: Walk(%s) err at %d %q, expected %d %q", idx, rec.text,
+				decErr.Offset, decErr.Path, rec.offset, rec.path)
+		}
+	}
+}
+
+// columnHandler collects the columnvalues of changes to some tables from
+// wal2json output, skipping everything else.
+type columnHandler struct {
+	BaseHandler
+	tables  map[string]bool
+	depth   int
+	key     string
+	table   string
+	columns [][]*BigJSONValue
+}
+
+func (h *columnHandler) OnObjectStart() error {
+	h.depth++
+	return nil
+}
+
+func (h *columnHandler) OnObjectEnd() error {
+	h.depth--
+	return nil
+}
+
+func (h *columnHandler) OnArrayStart() error {
+	if h.depth == 1 && h.key == "change" {
+		return nil
+	} else if h.depth == 2 && h.key == "columnvalues" && h.tables[h.table] {
+		h.columns = append(h.columns, nil)
+		return nil
+	}
+	return ErrSkipSubtree
+}
+
+func (h *columnHandler) OnKey(key string) error {
+	h.key = key
+	return nil
+}
+
+func (h *columnHandler) OnValue(value *BigJSONValue) error {
+	if h.depth == 2 && h.key == "table" {
+		h.table = value.String()
+	} else if h.depth == 2 && h.key == "columnvalues" {
+		h.columns[len(h.columns)-1] = append(h.columns[len(h.columns)-1], value)
+	}
+	return nil
+}
+
+func TestWalkHandler(t *testing.T) {
+	text := `{"xid": 1, "change": [
+		{"kind": "insert", "table": "users", "columnnames": ["id"], "columnvalues": [18446744073709551617, "bob"]},
+		{"kind": "insert", "table": "logs", "columnnames": ["id"], "columnvalues": [1, "skipped"]},
+		{"kind": "update", "table": "users", "columnnames": ["id"], "columnvalues": [9007199254740993, 1.5]}
+	]}`
+	h := &columnHandler{tables: map[string]bool{"users": true}}
+	if err := Walk(strings.NewReader(text), h); err != nil {
+		t.Fatalf("Walk() err=%s", err)
+	}
+	var rows []string
+	for _, row := range h.columns {
+		var values []string
+		for _, value := range row {
+			values = append(values, value.String())
+		}
+		rows = append(rows, strings.Join(values, ","))
+	}
+	if text := strings.Join(rows, ";"); text != "18446744073709551617,bob;9007199254740993,1.5" {
+		t.Errorf("Walk() columnvalues=%s", text)
+	}
+}